@@ -5,6 +5,14 @@ gardener-extension-registry-cache
 {{- define "config" -}}
 apiVersion: config.registry.extensions.gardener.cloud/v1alpha1
 kind: Configuration
+{{- if .Values.sharedCache }}
+sharedCache:
+{{ toYaml .Values.sharedCache | indent 2 }}
+{{- end }}
+{{- if .Values.certificates }}
+certificates:
+{{ toYaml .Values.certificates | indent 2 }}
+{{- end }}
 {{- end }}
 
 {{- define "leaderelectionid" -}}
@@ -16,7 +16,10 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	monitoringv1alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
 	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	vpaautoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
 	"k8s.io/component-base/version"
 	"k8s.io/component-base/version/verflag"
@@ -29,6 +32,7 @@ import (
 	mirrorinstall "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/install"
 	registryinstall "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/install"
 	cachecontroller "github.com/gardener/gardener-extension-registry-cache/pkg/controller/cache"
+	sharedcachecontroller "github.com/gardener/gardener-extension-registry-cache/pkg/controller/sharedcache"
 )
 
 var log = logf.Log.WithName("gardener-extension-registry-cache")
@@ -79,6 +83,13 @@ func (o *Options) run(ctx context.Context) error {
 			DisableFor: []client.Object{
 				&corev1.Secret{},    // applied for ManagedResources
 				&corev1.ConfigMap{}, // applied for monitoring config
+				// applied for the shared registry caches
+				&corev1.Namespace{},
+				&corev1.Service{},
+				&corev1.PersistentVolumeClaim{},
+				&appsv1.StatefulSet{},
+				&policyv1.PodDisruptionBudget{},
+				&vpaautoscalingv1.VerticalPodAutoscaler{},
 			},
 		},
 	}
@@ -104,9 +115,13 @@ func (o *Options) run(ctx context.Context) error {
 	if err := monitoringv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
 		return fmt.Errorf("could not update manager scheme: %w", err)
 	}
+	if err := vpaautoscalingv1.AddToScheme(mgr.GetScheme()); err != nil {
+		return fmt.Errorf("could not update manager scheme: %w", err)
+	}
 
 	ctrlConfig := o.registryOptions.Completed()
 	ctrlConfig.Apply(&cachecontroller.DefaultAddOptions.Config)
+	ctrlConfig.Apply(&sharedcachecontroller.DefaultAddOptions.Config)
 	o.controllerOptions.Completed().Apply(&cachecontroller.DefaultAddOptions.ControllerOptions)
 	o.reconcileOptions.Completed().Apply(&cachecontroller.DefaultAddOptions.IgnoreOperationAnnotation, ptr.To(extensionsv1alpha1.ExtensionClassShoot))
 	o.heartbeatOptions.Completed().Apply(&heartbeatcontroller.DefaultAddOptions)
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/component-base/version"
+	"k8s.io/component-base/version/verflag"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/upstreamlimiter"
+)
+
+var log = logf.Log.WithName("registry-cache-upstream-limiter")
+
+// options contains the options of the upstream limiter.
+type options struct {
+	upstreamURL        string
+	bindAddress        string
+	metricsBindAddress string
+	config             upstreamlimiter.Config
+}
+
+func (o *options) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.upstreamURL, "upstream-url", "", "URL of the upstream to which the requests are proxied")
+	fs.StringVar(&o.bindAddress, "bind-address", net.JoinHostPort("127.0.0.1", strconv.Itoa(constants.UpstreamLimiterPort)), "address on which the requests to the upstream are served")
+	fs.StringVar(&o.metricsBindAddress, "metrics-bind-address", ":"+strconv.Itoa(constants.UpstreamLimiterMetricsPort), "address on which the metrics and health checks are served")
+	fs.Int32Var(&o.config.RequestsPerSecond, "requests-per-second", 0, "maximum number of requests per second to the upstream, 0 disables the limit")
+	fs.Int32Var(&o.config.MaxConcurrentRequests, "max-concurrent-requests", 0, "maximum number of concurrent requests to the upstream, 0 disables the limit")
+	fs.Int64Var(&o.config.MaxBandwidth, "max-bandwidth", 0, "maximum bandwidth in bytes per second of the responses from the upstream, 0 disables the limit")
+}
+
+func (o *options) validate() (*url.URL, error) {
+	upstreamURL, err := url.Parse(o.upstreamURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse upstream URL: %w", err)
+	}
+	if upstreamURL.Scheme != "http" && upstreamURL.Scheme != "https" || upstreamURL.Host == "" {
+		return nil, fmt.Errorf("upstream URL %q must be an absolute URL with scheme 'http' or 'https'", o.upstreamURL)
+	}
+	if o.config.RequestsPerSecond < 0 || o.config.MaxConcurrentRequests < 0 || o.config.MaxBandwidth < 0 {
+		return nil, errors.New("limits must not be negative")
+	}
+
+	return upstreamURL, nil
+}
+
+// NewUpstreamLimiterCommand creates a new command for running the upstream limiter of a registry cache.
+func NewUpstreamLimiterCommand() *cobra.Command {
+	opts := &options{}
+
+	cmd := &cobra.Command{
+		Use:   "registry-cache-upstream-limiter",
+		Short: "Proxies the requests of a registry cache to its upstream and limits them.",
+
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			verflag.PrintAndExitIfRequested()
+
+			upstreamURL, err := opts.validate()
+			if err != nil {
+				return err
+			}
+
+			log.Info("Starting registry-cache-upstream-limiter", "version", version.Get(), "upstreamURL", upstreamURL.String())
+
+			return run(cmd.Context(), opts, upstreamURL)
+		},
+	}
+
+	verflag.AddFlags(cmd.Flags())
+	opts.addFlags(cmd.Flags())
+
+	return cmd
+}
+
+func run(ctx context.Context, opts *options, upstreamURL *url.URL) error {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// The responses are passed through unchanged, e.g. the digests of compressed blobs must not change.
+	transport.DisableCompression = true
+
+	limiter := upstreamlimiter.New(log, upstreamURL, &http.Client{Transport: transport}, opts.config, upstreamlimiter.NewMetrics(registry))
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	metricsMux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	servers := []*http.Server{
+		{Addr: opts.bindAddress, Handler: limiter, ReadHeaderTimeout: 30 * time.Second},
+		{Addr: opts.metricsBindAddress, Handler: metricsMux, ReadHeaderTimeout: 30 * time.Second},
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, server := range servers {
+		g.Go(func() error {
+			log.Info("Starting server", "address", server.Addr)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to serve on %s: %w", server.Addr, err)
+			}
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 25*time.Second)
+		defer cancel()
+
+		var errs []error
+		for _, server := range servers {
+			errs = append(errs, server.Shutdown(shutdownCtx))
+		}
+		return errors.Join(errs...)
+	})
+
+	return g.Wait()
+}
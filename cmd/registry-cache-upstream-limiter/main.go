@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+
+	"github.com/gardener/gardener/pkg/logger"
+	runtimelog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/gardener/gardener-extension-registry-cache/cmd/registry-cache-upstream-limiter/app"
+)
+
+func main() {
+	runtimelog.SetLogger(logger.MustNewZapLogger(logger.InfoLevel, logger.FormatJSON))
+
+	ctx := signals.SetupSignalHandler()
+	if err := app.NewUpstreamLimiterCommand().ExecuteContext(ctx); err != nil {
+		runtimelog.Log.Error(err, "Error executing the upstream limiter command")
+		os.Exit(1)
+	}
+}
@@ -20,6 +20,7 @@ function run() {
 
 run "skaffold.yaml" "gardener-extension-registry-cache"           "extension"
 run "skaffold.yaml" "gardener-extension-registry-cache-admission" "admission"
+run "skaffold.yaml" "registry-cache-upstream-limiter"             "extension"
 
 if ! $success ; then
   exit 1
@@ -7,21 +7,28 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	"github.com/gardener/gardener/pkg/apis/core"
 	gardencorehelper "github.com/gardener/gardener/pkg/apis/core/helper"
+	imagevectorutils "github.com/gardener/gardener/pkg/utils/imagevector"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/gardener/gardener-extension-registry-cache/imagevector"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/admission/validator/helper"
+	mirrorapi "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror"
 	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/validation"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
 )
 
 // shoot validates shoots
@@ -66,7 +73,10 @@ func (s *shoot) Validate(ctx context.Context, newObj, oldObj client.Object) erro
 		return fmt.Errorf("failed to decode providerConfig: %w", err)
 	}
 
-	allErrs := field.ErrorList{}
+	var (
+		allErrs         = field.ErrorList{}
+		oldMirrorConfig *mirrorapi.MirrorConfig
+	)
 
 	if oldObj != nil {
 		oldShoot, ok := oldObj.(*core.Shoot)
@@ -91,11 +101,20 @@ func (s *shoot) Validate(ctx context.Context, newObj, oldObj client.Object) erro
 
 			allErrs = append(allErrs, validation.ValidateRegistryConfigUpdate(oldRegistryConfig, registryConfig, providerConfigPath)...)
 		}
+
+		if mirrorI, mirrorExt := helper.FindExtension(oldShoot.Spec.Extensions, constants.RegistryMirrorExtensionType); mirrorI != -1 && mirrorExt.ProviderConfig != nil {
+			oldMirrorConfig = &mirrorapi.MirrorConfig{}
+			if err := runtime.DecodeInto(s.decoder, mirrorExt.ProviderConfig.Raw, oldMirrorConfig); err != nil {
+				return fmt.Errorf("failed to decode registry-mirror providerConfig of old Shoot: %w", err)
+			}
+		}
 	}
 
 	allErrs = append(allErrs, validation.ValidateRegistryConfig(registryConfig, providerConfigPath)...)
+	allErrs = append(allErrs, validateStrictRegistryCaches(registryConfig, oldMirrorConfig, providerConfigPath, imageUpstreams(imagevector.ImageVector()))...)
+	allErrs = append(allErrs, validateInternalLoadBalancerExposures(registryConfig, providerConfigPath, shoot.Spec.Provider.Type)...)
 
-	errList, err := s.validateRegistryCredentials(ctx, registryConfig, providerConfigPath, shoot.Spec.Resources, shoot.Namespace)
+	errList, err := s.validateReferencedSecrets(ctx, registryConfig, providerConfigPath, shoot.Spec.Resources, shoot.Namespace)
 	if err != nil {
 		return err
 	}
@@ -104,35 +123,130 @@ func (s *shoot) Validate(ctx context.Context, newObj, oldObj client.Object) erro
 	return allErrs.ToAggregate()
 }
 
-// validateRegistryConfig validates the passed configuration instance.
-func (s *shoot) validateRegistryCredentials(ctx context.Context, config *api.RegistryConfig, fldPath *field.Path, resources []core.NamedResourceReference, namespace string) (field.ErrorList, error) {
+// validateInternalLoadBalancerExposures validates that an internal load balancer can be created for the registry caches
+// exposed with type 'InternalLoadBalancer'. The annotations for an internal load balancer are provider-specific, hence
+// they have to be provided for provider types without known annotations.
+func validateInternalLoadBalancerExposures(config *api.RegistryConfig, fldPath *field.Path, providerType string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, cache := range config.Caches {
+		if cache.Exposure == nil || cache.Exposure.Type != api.ExposureTypeInternalLoadBalancer {
+			continue
+		}
+
+		if len(cache.Exposure.Annotations) == 0 && registryutils.InternalLoadBalancerAnnotations(providerType) == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("caches").Index(i).Child("exposure", "annotations"), fmt.Sprintf("annotations for an internal load balancer must be provided for provider type %q", providerType)))
+		}
+	}
+
+	return allErrs
+}
+
+// validateStrictRegistryCaches validates the upstreams of the strict registry caches.
+// The registry cache Pods pull their images on new Nodes before the registry caches are available. containerd does not
+// fall back to the upstream for strict registry caches, hence the upstreams of the extension's images cannot be strict.
+// gardener-node-agent writes the files of the OperatingSystemConfig before it removes the hosts.toml files of the
+// upstreams which are no longer part of the registry config. Hence, a strict registry cache cannot be added for the
+// upstream of a mirror which is removed with the same update, the hosts.toml file written by the extension would be removed.
+func validateStrictRegistryCaches(config *api.RegistryConfig, oldMirrorConfig *mirrorapi.MirrorConfig, fldPath *field.Path, imageUpstreams sets.Set[string]) field.ErrorList {
+	oldMirrorUpstreams := sets.New[string]()
+	if oldMirrorConfig != nil {
+		for _, mirror := range oldMirrorConfig.Mirrors {
+			oldMirrorUpstreams.Insert(mirror.Upstream)
+		}
+	}
+
+	allErrs := field.ErrorList{}
+
+	for i, cache := range config.Caches {
+		if !ptr.Deref(cache.Strict, false) {
+			continue
+		}
+
+		strictFldPath := fldPath.Child("caches").Index(i).Child("strict")
+		if imageUpstreams.Has(cache.Upstream) {
+			allErrs = append(allErrs, field.Forbidden(strictFldPath, fmt.Sprintf("the registry cache for upstream '%s' cannot be strict as the images of the registry cache are pulled from the upstream", cache.Upstream)))
+		}
+		if oldMirrorUpstreams.Has(cache.Upstream) {
+			allErrs = append(allErrs, field.Forbidden(strictFldPath, fmt.Sprintf("the registry cache for upstream '%s' cannot be strict as the upstream is configured as a registry-mirror upstream in the old Shoot, remove the mirror first and add the strict registry cache with a subsequent update", cache.Upstream)))
+		}
+	}
+
+	return allErrs
+}
+
+// imageUpstreams returns the upstreams of the images in the given image vector.
+func imageUpstreams(imageVector imagevectorutils.ImageVector) sets.Set[string] {
+	upstreams := sets.New[string]()
+	for _, image := range imageVector {
+		repository := ptr.Deref(image.Repository, ptr.Deref(image.Ref, ""))
+		if host, _, found := strings.Cut(repository, "/"); found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+			upstreams.Insert(host)
+		} else if repository != "" {
+			// Images without registry host are pulled from Docker Hub.
+			upstreams.Insert("docker.io")
+		}
+	}
+
+	return upstreams
+}
+
+// secretReference is a reference to a Secret in the registry-cache providerConfig together with the func validating it.
+type secretReference struct {
+	fldPath  *field.Path
+	name     *string
+	validate func(*corev1.Secret, *field.Path, string) field.ErrorList
+}
+
+// validateReferencedSecrets validates the Secrets referenced by the registry caches.
+func (s *shoot) validateReferencedSecrets(ctx context.Context, config *api.RegistryConfig, fldPath *field.Path, resources []core.NamedResourceReference, namespace string) (field.ErrorList, error) {
 	allErrs := field.ErrorList{}
 
 	for i, cache := range config.Caches {
 		cacheFldPath := fldPath.Child("caches").Index(i)
 
-		if cache.SecretReferenceName != nil {
-			secretRefFldPath := cacheFldPath.Child("secretReferenceName")
+		refs := []secretReference{
+			{cacheFldPath.Child("secretReferenceName"), cache.SecretReferenceName, validation.ValidateUpstreamRegistrySecret},
+		}
+		if cache.HTTP != nil {
+			refs = append(refs,
+				secretReference{cacheFldPath.Child("http", "tlsSecretReferenceName"), cache.HTTP.TLSSecretReferenceName, validation.ValidateTLSSecret},
+				secretReference{cacheFldPath.Child("http", "caBundleSecretReferenceName"), cache.HTTP.CABundleSecretReferenceName, validation.ValidateCABundleSecret},
+			)
+		}
+		if cache.Tracing != nil {
+			refs = append(refs, secretReference{cacheFldPath.Child("tracing", "headersSecretReferenceName"), cache.Tracing.HeadersSecretReferenceName, validation.ValidateHeadersSecret})
+		}
+		if cache.Notifications != nil {
+			for j, endpoint := range cache.Notifications.Endpoints {
+				refs = append(refs, secretReference{cacheFldPath.Child("notifications", "endpoints").Index(j).Child("headersSecretReferenceName"), endpoint.HeadersSecretReferenceName, validation.ValidateHeadersSecret})
+			}
+		}
+
+		for _, ref := range refs {
+			if ref.name == nil {
+				continue
+			}
 
-			ref := gardencorehelper.GetResourceByName(resources, *cache.SecretReferenceName)
-			if ref == nil || ref.ResourceRef.Kind != "Secret" {
-				allErrs = append(allErrs, field.Invalid(secretRefFldPath, *cache.SecretReferenceName, fmt.Sprintf("failed to find referenced resource with name %s and kind Secret", *cache.SecretReferenceName)))
+			resource := gardencorehelper.GetResourceByName(resources, *ref.name)
+			if resource == nil || resource.ResourceRef.Kind != "Secret" {
+				allErrs = append(allErrs, field.Invalid(ref.fldPath, *ref.name, fmt.Sprintf("failed to find referenced resource with name %s and kind Secret", *ref.name)))
 				continue
 			}
 
 			secret := &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      ref.ResourceRef.Name,
+					Name:      resource.ResourceRef.Name,
 					Namespace: namespace,
 				},
 			}
 			// Explicitly use the client.Reader to prevent controller-runtime to start Informer for Secrets
 			// under the hood. The latter increases the memory usage of the component.
 			if err := s.apiReader.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
-				return allErrs, fmt.Errorf("failed to get secret %s for secretReferenceName %s: %w", client.ObjectKeyFromObject(secret), *cache.SecretReferenceName, err)
+				return allErrs, fmt.Errorf("failed to get secret %s for %s %s: %w", client.ObjectKeyFromObject(secret), ref.fldPath.String(), *ref.name, err)
 			}
 
-			allErrs = append(allErrs, validation.ValidateUpstreamRegistrySecret(secret, secretRefFldPath, *cache.SecretReferenceName)...)
+			allErrs = append(allErrs, ref.validate(secret, ref.fldPath, *ref.name)...)
 		}
 	}
 
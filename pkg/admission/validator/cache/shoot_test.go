@@ -12,6 +12,8 @@ import (
 
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	"github.com/gardener/gardener/pkg/apis/core"
+	imagevectorutils "github.com/gardener/gardener/pkg/utils/imagevector"
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
 	mockclient "github.com/gardener/gardener/third_party/mock/controller-runtime/client"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -28,6 +30,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/admission/validator/cache"
+	mirrorinstall "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/install"
+	mirrorv1alpha1 "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/v1alpha1"
 	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha3"
 )
@@ -55,6 +59,7 @@ var _ = Describe("Shoot validator", func() {
 			scheme := runtime.NewScheme()
 			Expect(api.AddToScheme(scheme)).To(Succeed())
 			Expect(v1alpha3.AddToScheme(scheme)).To(Succeed())
+			mirrorinstall.Install(scheme)
 
 			decoder := serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
 			ctrl = gomock.NewController(GinkgoT())
@@ -376,6 +381,428 @@ var _ = Describe("Shoot validator", func() {
 				))
 			})
 		})
+
+		Context("TLS secrets", func() {
+			var (
+				tlsSecret      *corev1.Secret
+				caBundleSecret *corev1.Secret
+			)
+
+			BeforeEach(func() {
+				certificate, err := (&secretsutils.CertificateSecretConfig{
+					Name:       "registry-docker-io",
+					CommonName: "registry-docker-io",
+					CertType:   secretsutils.CACert,
+				}).GenerateCertificate()
+				Expect(err).NotTo(HaveOccurred())
+
+				tlsSecret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "garden-tst",
+						Name:      "docker-tls",
+					},
+					Immutable: ptr.To(true),
+					Data: map[string][]byte{
+						"tls.crt": certificate.CertificatePEM,
+						"tls.key": certificate.PrivateKeyPEM,
+					},
+				}
+				caBundleSecret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "garden-tst",
+						Name:      "docker-ca",
+					},
+					Immutable: ptr.To(true),
+					Data: map[string][]byte{
+						"ca.crt": certificate.CertificatePEM,
+					},
+				}
+				shoot.Spec.Resources = []core.NamedResourceReference{
+					{
+						Name: "tls",
+						ResourceRef: autoscalingv1.CrossVersionObjectReference{
+							Kind: "Secret",
+							Name: "docker-tls",
+						},
+					},
+					{
+						Name: "ca",
+						ResourceRef: autoscalingv1.CrossVersionObjectReference{
+							Kind: "Secret",
+							Name: "docker-ca",
+						},
+					},
+				}
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{
+					Raw: encode(&v1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: v1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []v1alpha3.RegistryCache{
+							{
+								Upstream: "docker.io",
+								Volume: &v1alpha3.Volume{
+									Size: &size,
+								},
+								HTTP: &v1alpha3.HTTP{
+									TLS:                         true,
+									TLSSecretReferenceName:      ptr.To("tls"),
+									CABundleSecretReferenceName: ptr.To("ca"),
+								},
+							},
+						},
+					}),
+				}
+			})
+
+			It("should succeed for valid configuration", func() {
+				for _, secret := range []*corev1.Secret{tlsSecret, caBundleSecret} {
+					apiReader.EXPECT().Get(ctx, client.ObjectKeyFromObject(secret), gomock.AssignableToTypeOf(&corev1.Secret{})).
+						DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj *corev1.Secret, _ ...client.GetOption) error {
+							*obj = *secret
+							return nil
+						})
+				}
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err when the secrets are invalid", func() {
+				tlsSecret.Data["tls.key"] = []byte("foo")
+				caBundleSecret.Immutable = nil
+				for _, secret := range []*corev1.Secret{tlsSecret, caBundleSecret} {
+					apiReader.EXPECT().Get(ctx, client.ObjectKeyFromObject(secret), gomock.AssignableToTypeOf(&corev1.Secret{})).
+						DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj *corev1.Secret, _ ...client.GetOption) error {
+							*obj = *secret
+							return nil
+						})
+				}
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("spec.extensions[0].providerConfig.caches[0].http.tlsSecretReferenceName"),
+						"Detail": ContainSubstring("referenced secret \"garden-tst/docker-tls\" does not contain a valid certificate and key pair"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("spec.extensions[0].providerConfig.caches[0].http.caBundleSecretReferenceName"),
+						"Detail": Equal("referenced secret \"garden-tst/docker-ca\" should be immutable"),
+					})),
+				))
+			})
+		})
+
+		Context("Tracing headers secret", func() {
+			var headersSecret *corev1.Secret
+
+			BeforeEach(func() {
+				headersSecret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "garden-tst",
+						Name:      "otel-headers",
+					},
+					Immutable: ptr.To(true),
+					Data: map[string][]byte{
+						"Authorization": []byte("Bearer token"),
+					},
+				}
+				shoot.Spec.Resources = []core.NamedResourceReference{
+					{
+						Name: "headers",
+						ResourceRef: autoscalingv1.CrossVersionObjectReference{
+							Kind: "Secret",
+							Name: "otel-headers",
+						},
+					},
+				}
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{
+					Raw: encode(&v1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: v1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []v1alpha3.RegistryCache{
+							{
+								Upstream: "docker.io",
+								Volume: &v1alpha3.Volume{
+									Size: &size,
+								},
+								Tracing: &v1alpha3.Tracing{
+									Endpoint:                   "http://otel-collector.monitoring.svc:4318",
+									HeadersSecretReferenceName: ptr.To("headers"),
+								},
+							},
+						},
+					}),
+				}
+			})
+
+			It("should succeed for valid configuration", func() {
+				apiReader.EXPECT().Get(ctx, client.ObjectKeyFromObject(headersSecret), gomock.AssignableToTypeOf(&corev1.Secret{})).
+					DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj *corev1.Secret, _ ...client.GetOption) error {
+						*obj = *headersSecret
+						return nil
+					})
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err when the secret is invalid", func() {
+				headersSecret.Immutable = nil
+				apiReader.EXPECT().Get(ctx, client.ObjectKeyFromObject(headersSecret), gomock.AssignableToTypeOf(&corev1.Secret{})).
+					DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj *corev1.Secret, _ ...client.GetOption) error {
+						*obj = *headersSecret
+						return nil
+					})
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("spec.extensions[0].providerConfig.caches[0].tracing.headersSecretReferenceName"),
+						"Detail": Equal("referenced secret \"garden-tst/otel-headers\" should be immutable"),
+					})),
+				))
+			})
+		})
+
+		Context("Notification headers secret", func() {
+			var headersSecret *corev1.Secret
+
+			BeforeEach(func() {
+				headersSecret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "garden-tst",
+						Name:      "audit-headers",
+					},
+					Immutable: ptr.To(true),
+					Data: map[string][]byte{
+						"Authorization": []byte("Bearer token"),
+					},
+				}
+				shoot.Spec.Resources = []core.NamedResourceReference{
+					{
+						Name: "headers",
+						ResourceRef: autoscalingv1.CrossVersionObjectReference{
+							Kind: "Secret",
+							Name: "audit-headers",
+						},
+					},
+				}
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{
+					Raw: encode(&v1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: v1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []v1alpha3.RegistryCache{
+							{
+								Upstream: "docker.io",
+								Volume: &v1alpha3.Volume{
+									Size: &size,
+								},
+								Notifications: &v1alpha3.Notifications{
+									Endpoints: []v1alpha3.NotificationEndpoint{
+										{
+											Name: "receiver",
+											URL:  "http://receiver.monitoring.svc:8080",
+										},
+										{
+											Name:                       "audit",
+											URL:                        "https://audit.example.com/events",
+											HeadersSecretReferenceName: ptr.To("headers"),
+										},
+									},
+								},
+							},
+						},
+					}),
+				}
+			})
+
+			It("should succeed for valid configuration", func() {
+				apiReader.EXPECT().Get(ctx, client.ObjectKeyFromObject(headersSecret), gomock.AssignableToTypeOf(&corev1.Secret{})).
+					DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj *corev1.Secret, _ ...client.GetOption) error {
+						*obj = *headersSecret
+						return nil
+					})
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err when the secret is invalid", func() {
+				headersSecret.Data = nil
+				apiReader.EXPECT().Get(ctx, client.ObjectKeyFromObject(headersSecret), gomock.AssignableToTypeOf(&corev1.Secret{})).
+					DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj *corev1.Secret, _ ...client.GetOption) error {
+						*obj = *headersSecret
+						return nil
+					})
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("spec.extensions[0].providerConfig.caches[0].notifications.endpoints[1].headersSecretReferenceName"),
+						"Detail": Equal("referenced secret \"garden-tst/audit-headers\" should have at least one data entry"),
+					})),
+				))
+			})
+		})
+
+		Context("Strict registry caches", func() {
+			setStrictCache := func(upstream string) {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{
+					Raw: encode(&v1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: v1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []v1alpha3.RegistryCache{
+							{
+								Upstream: upstream,
+								Strict:   ptr.To(true),
+							},
+						},
+					}),
+				}
+			}
+
+			It("should succeed for a strict registry cache", func() {
+				setStrictCache("ghcr.io")
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err for a strict registry cache for an upstream of the extension's images", func() {
+				setStrictCache("europe-docker.pkg.dev")
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeForbidden),
+						"Field":  Equal("spec.extensions[0].providerConfig.caches[0].strict"),
+						"Detail": Equal("the registry cache for upstream 'europe-docker.pkg.dev' cannot be strict as the images of the registry cache are pulled from the upstream"),
+					})),
+				))
+			})
+
+			It("should return err when a strict registry cache is added for the upstream of a mirror removed with the same update", func() {
+				oldShoot := shoot.DeepCopy()
+				oldShoot.Spec.Extensions = append(oldShoot.Spec.Extensions, core.Extension{
+					Type: "registry-mirror",
+					ProviderConfig: &runtime.RawExtension{
+						Raw: encode(&mirrorv1alpha1.MirrorConfig{
+							TypeMeta: metav1.TypeMeta{
+								APIVersion: mirrorv1alpha1.SchemeGroupVersion.String(),
+								Kind:       "MirrorConfig",
+							},
+							Mirrors: []mirrorv1alpha1.MirrorConfiguration{
+								{
+									Upstream: "ghcr.io",
+									Hosts:    []mirrorv1alpha1.MirrorHost{{Host: "https://mirror.example.com"}},
+								},
+							},
+						}),
+					},
+				})
+				setStrictCache("ghcr.io")
+
+				Expect(shootValidator.Validate(ctx, shoot, oldShoot)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeForbidden),
+						"Field":  Equal("spec.extensions[0].providerConfig.caches[0].strict"),
+						"Detail": Equal("the registry cache for upstream 'ghcr.io' cannot be strict as the upstream is configured as a registry-mirror upstream in the old Shoot, remove the mirror first and add the strict registry cache with a subsequent update"),
+					})),
+				))
+			})
+
+			It("should return err when a registry cache is replaced by a strict registry cache for the same upstream", func() {
+				oldShoot := shoot.DeepCopy()
+				oldShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{
+					Raw: encode(&v1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: v1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []v1alpha3.RegistryCache{{Upstream: "ghcr.io"}},
+					}),
+				}
+				setStrictCache("ghcr.io")
+
+				Expect(shootValidator.Validate(ctx, shoot, oldShoot)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("spec.extensions[0].providerConfig.caches[0].strict"),
+					})),
+				))
+			})
+		})
+
+		Context("Internal load balancer exposure", func() {
+			var exposure *v1alpha3.Exposure
+
+			BeforeEach(func() {
+				exposure = &v1alpha3.Exposure{Type: v1alpha3.ExposureTypeInternalLoadBalancer}
+			})
+
+			setExposure := func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{
+					Raw: encode(&v1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: v1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []v1alpha3.RegistryCache{
+							{
+								Upstream: "docker.io",
+								Exposure: exposure,
+							},
+						},
+					}),
+				}
+			}
+
+			It("should succeed for a provider type with known annotations", func() {
+				shoot.Spec.Provider.Type = "aws"
+				setExposure()
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should succeed for an unknown provider type when annotations are provided", func() {
+				shoot.Spec.Provider.Type = "local"
+				exposure.Annotations = map[string]string{"example.com/internal": "true"}
+				setExposure()
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err for an unknown provider type without annotations", func() {
+				shoot.Spec.Provider.Type = "local"
+				setExposure()
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeRequired),
+						"Field":  Equal("spec.extensions[0].providerConfig.caches[0].exposure.annotations"),
+						"Detail": Equal("annotations for an internal load balancer must be provided for provider type \"local\""),
+					})),
+				))
+			})
+		})
+	})
+})
+
+var _ = Describe("#ImageUpstreams", func() {
+	It("should return the upstreams of the images", func() {
+		Expect(cache.ImageUpstreams(imagevectorutils.ImageVector{
+			{Name: "registry", Repository: ptr.To("europe-docker.pkg.dev/gardener-project/releases/3rd/registry")},
+			{Name: "redis", Repository: ptr.To("docker.io/library/redis")},
+			{Name: "ref", Ref: ptr.To("ghcr.io/example/image:v1")},
+			{Name: "port", Repository: ptr.To("registry.local:5001/image")},
+			{Name: "localhost", Repository: ptr.To("localhost/image")},
+			{Name: "docker-hub", Repository: ptr.To("library/busybox")},
+			{Name: "docker-hub-short", Repository: ptr.To("busybox")},
+		}).UnsortedList()).To(ConsistOf("europe-docker.pkg.dev", "docker.io", "ghcr.io", "registry.local:5001", "localhost"))
 	})
 })
 
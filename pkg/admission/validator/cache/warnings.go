@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gardener/gardener/pkg/apis/core"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/admission/validator/helper"
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+)
+
+// NewWarningsHandler returns an admission.Handler that adds warnings about the registry-cache providerConfig of the Shoot
+// to the responses of the given handler which allow the request.
+func NewWarningsHandler(handler admission.Handler, decoder runtime.Decoder) admission.Handler {
+	return &warningsHandler{
+		handler: handler,
+		decoder: decoder,
+	}
+}
+
+type warningsHandler struct {
+	handler admission.Handler
+	decoder runtime.Decoder
+}
+
+// Handle handles the given admission request.
+func (w *warningsHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	response := w.handler.Handle(ctx, req)
+	if !response.Allowed || req.Operation == admissionv1.Delete {
+		return response
+	}
+
+	shoot := &core.Shoot{}
+	if _, _, err := w.decoder.Decode(req.Object.Raw, nil, shoot); err != nil {
+		return response
+	}
+
+	return response.WithWarnings(w.warnings(shoot)...)
+}
+
+func (w *warningsHandler) warnings(shoot *core.Shoot) []string {
+	i, ext := helper.FindExtension(shoot.Spec.Extensions, constants.RegistryCacheExtensionType)
+	if i == -1 || ext.ProviderConfig == nil {
+		return nil
+	}
+
+	registryConfig := &api.RegistryConfig{}
+	if err := runtime.DecodeInto(w.decoder, ext.ProviderConfig.Raw, registryConfig); err != nil {
+		return nil
+	}
+
+	var (
+		warnings      []string
+		cachesFldPath = field.NewPath("spec", "extensions").Index(i).Child("providerConfig", "caches")
+	)
+	for j, cache := range registryConfig.Caches {
+		if ptr.Deref(cache.Strict, false) {
+			warnings = append(warnings, fmt.Sprintf("%s: the registry cache for upstream '%s' is strict, image pulls from the upstream fail on the Nodes when the registry cache is not available", cachesFldPath.Index(j).Child("strict"), cache.Upstream))
+		}
+		if cache.ConfigOverrides != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: the configuration of the registry cache for upstream '%s' is overridden, the overridden options are not validated and invalid options prevent the registry cache from starting", cachesFldPath.Index(j).Child("configOverrides"), cache.Upstream))
+		}
+		if cache.Exposure != nil && cache.Exposure.Type == api.ExposureTypeLoadBalancer {
+			warnings = append(warnings, fmt.Sprintf("%s: the registry cache for upstream '%s' is exposed via a public load balancer, the cached content is served without authentication to all clients which can reach it", cachesFldPath.Index(j).Child("exposure", "type"), cache.Upstream))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	gardencoreinstall "github.com/gardener/gardener/pkg/apis/core/install"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/admission/validator/cache"
+	registryinstall "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/install"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha3"
+)
+
+var _ = Describe("Warnings handler", func() {
+	var (
+		ctx  = context.Background()
+		size = resource.MustParse("10Gi")
+
+		response admission.Response
+		handler  admission.Handler
+
+		registryConfig *v1alpha3.RegistryConfig
+		shoot          *gardencorev1beta1.Shoot
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		gardencoreinstall.Install(scheme)
+		registryinstall.Install(scheme)
+		decoder := serializer.NewCodecFactory(scheme).UniversalDecoder()
+
+		response = admission.Allowed("")
+		handler = cache.NewWarningsHandler(admission.HandlerFunc(func(context.Context, admission.Request) admission.Response {
+			return response
+		}), decoder)
+
+		registryConfig = &v1alpha3.RegistryConfig{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha3.SchemeGroupVersion.String(),
+				Kind:       "RegistryConfig",
+			},
+			Caches: []v1alpha3.RegistryCache{
+				{
+					Upstream: "docker.io",
+					Volume:   &v1alpha3.Volume{Size: &size},
+				},
+				{
+					Upstream: "ghcr.io",
+					Volume:   &v1alpha3.Volume{Size: &size},
+					Strict:   ptr.To(true),
+				},
+			},
+		}
+
+		shoot = &gardencorev1beta1.Shoot{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: gardencorev1beta1.SchemeGroupVersion.String(),
+				Kind:       "Shoot",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "garden-tst",
+				Name:      "tst",
+			},
+		}
+	})
+
+	request := func(operation admissionv1.Operation) admission.Request {
+		shoot.Spec.Extensions = []gardencorev1beta1.Extension{{
+			Type:           "registry-cache",
+			ProviderConfig: &runtime.RawExtension{Raw: encode(registryConfig)},
+		}}
+
+		raw, err := json.Marshal(shoot)
+		Expect(err).NotTo(HaveOccurred())
+
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	It("should add a warning for a strict registry cache", func() {
+		Expect(handler.Handle(ctx, request(admissionv1.Create)).Warnings).To(ConsistOf(
+			"spec.extensions[0].providerConfig.caches[1].strict: the registry cache for upstream 'ghcr.io' is strict, image pulls from the upstream fail on the Nodes when the registry cache is not available",
+		))
+	})
+
+	It("should add a warning for a registry cache exposed via a public load balancer", func() {
+		registryConfig.Caches[0].Exposure = &v1alpha3.Exposure{Type: v1alpha3.ExposureTypeLoadBalancer}
+		registryConfig.Caches[1].Exposure = &v1alpha3.Exposure{Type: v1alpha3.ExposureTypeInternalLoadBalancer}
+
+		Expect(handler.Handle(ctx, request(admissionv1.Create)).Warnings).To(ConsistOf(
+			"spec.extensions[0].providerConfig.caches[0].exposure.type: the registry cache for upstream 'docker.io' is exposed via a public load balancer, the cached content is served without authentication to all clients which can reach it",
+			"spec.extensions[0].providerConfig.caches[1].strict: the registry cache for upstream 'ghcr.io' is strict, image pulls from the upstream fail on the Nodes when the registry cache is not available",
+		))
+	})
+
+	It("should add a warning for a registry cache with config overrides", func() {
+		registryConfig.Caches[0].ConfigOverrides = &runtime.RawExtension{Raw: []byte(`{"storage":{"tag":{"concurrencylimit":10}}}`)}
+
+		Expect(handler.Handle(ctx, request(admissionv1.Update)).Warnings).To(ConsistOf(
+			"spec.extensions[0].providerConfig.caches[0].configOverrides: the configuration of the registry cache for upstream 'docker.io' is overridden, the overridden options are not validated and invalid options prevent the registry cache from starting",
+			"spec.extensions[0].providerConfig.caches[1].strict: the registry cache for upstream 'ghcr.io' is strict, image pulls from the upstream fail on the Nodes when the registry cache is not available",
+		))
+	})
+
+	It("should not add warnings when there is no strict registry cache", func() {
+		registryConfig.Caches[1].Strict = ptr.To(false)
+
+		Expect(handler.Handle(ctx, request(admissionv1.Update)).Warnings).To(BeEmpty())
+	})
+
+	It("should not add warnings when the request is denied", func() {
+		response = admission.Errored(http.StatusUnprocessableEntity, errors.New("invalid"))
+
+		Expect(handler.Handle(ctx, request(admissionv1.Create)).Warnings).To(BeEmpty())
+	})
+})
@@ -29,7 +29,7 @@ func New(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
 	decoder := serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict).UniversalDecoder()
 	apiReader := mgr.GetAPIReader()
 
-	return extensionswebhook.New(mgr, extensionswebhook.Args{
+	webhook, err := extensionswebhook.New(mgr, extensionswebhook.Args{
 		Provider: constants.RegistryCacheExtensionType,
 		Name:     Name,
 		Path:     "/webhooks/registry-cache",
@@ -41,4 +41,12 @@ func New(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
 			MatchLabels: map[string]string{"extensions.extensions.gardener.cloud/registry-cache": "true"},
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The generic validation handler does not support warnings, hence it is wrapped by a handler that adds them.
+	webhook.Webhook.Handler = NewWarningsHandler(webhook.Webhook.Handler, serializer.NewCodecFactory(mgr.GetScheme()).UniversalDecoder())
+
+	return webhook, nil
 }
@@ -10,31 +10,40 @@ import (
 
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	"github.com/gardener/gardener/pkg/apis/core"
+	gardencorehelper "github.com/gardener/gardener/pkg/apis/core/helper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/admission/validator/helper"
 	mirrorapi "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/validation"
 	cacheapi "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
 )
 
 type shoot struct {
-	decoder runtime.Decoder
+	apiReader client.Reader
+	decoder   runtime.Decoder
 }
 
 // NewShootValidator returns a new instance of a shoot validator that validates:
 // - the registry-mirror providerConfig
-// - the registry-mirror providerConfig against registry-cache providerConfig (if there is any)
-func NewShootValidator(decoder runtime.Decoder) extensionswebhook.Validator {
+// - the registry-mirror providerConfig update against the providerConfig of the old Shoot
+// - the Secrets referenced by the registry-mirror providerConfig
+// - the registry-mirror providerConfig against the registry-cache providerConfig of the Shoot and the old Shoot (if there is any)
+func NewShootValidator(apiReader client.Reader, decoder runtime.Decoder) extensionswebhook.Validator {
 	return &shoot{
-		decoder: decoder,
+		apiReader: apiReader,
+		decoder:   decoder,
 	}
 }
 
-func (s *shoot) Validate(_ context.Context, newObj, _ client.Object) error {
+func (s *shoot) Validate(ctx context.Context, newObj, oldObj client.Object) error {
 	shoot, ok := newObj.(*core.Shoot)
 	if !ok {
 		return fmt.Errorf("wrong object type %T", newObj)
@@ -61,41 +70,184 @@ func (s *shoot) Validate(_ context.Context, newObj, _ client.Object) error {
 		return fmt.Errorf("failed to decode providerConfig: %w", err)
 	}
 
-	allErrs := field.ErrorList{}
+	var (
+		allErrs                = field.ErrorList{}
+		oldCacheRegistryConfig *cacheapi.RegistryConfig
+	)
 	allErrs = append(allErrs, validation.ValidateMirrorConfig(mirrorConfig, providerConfigPath)...)
 
+	if oldObj != nil {
+		oldShoot, ok := oldObj.(*core.Shoot)
+		if !ok {
+			return fmt.Errorf("wrong object type %T for old object", oldObj)
+		}
+
+		oldI, oldMirrorExt := helper.FindExtension(oldShoot.Spec.Extensions, "registry-mirror")
+		if oldI != -1 && oldMirrorExt.ProviderConfig != nil {
+			oldMirrorConfig := &mirrorapi.MirrorConfig{}
+			if err := runtime.DecodeInto(s.decoder, oldMirrorExt.ProviderConfig.Raw, oldMirrorConfig); err != nil {
+				return fmt.Errorf("failed to decode providerConfig of old Shoot: %w", err)
+			}
+
+			allErrs = append(allErrs, validation.ValidateMirrorConfigUpdate(oldMirrorConfig, mirrorConfig, providerConfigPath)...)
+		}
+
+		oldJ, oldCacheExt := helper.FindExtension(oldShoot.Spec.Extensions, "registry-cache")
+		if oldJ != -1 && oldCacheExt.ProviderConfig != nil {
+			oldCacheRegistryConfig = &cacheapi.RegistryConfig{}
+			if err := runtime.DecodeInto(s.decoder, oldCacheExt.ProviderConfig.Raw, oldCacheRegistryConfig); err != nil {
+				return fmt.Errorf("failed to decode registry-cache providerConfig of old Shoot: %w", err)
+			}
+		}
+	}
+
+	errList, err := s.validateReferencedSecrets(ctx, mirrorConfig, providerConfigPath, shoot.Spec.Resources, shoot.Namespace)
+	if err != nil {
+		return err
+	}
+	allErrs = append(allErrs, errList...)
+
+	var cacheRegistryConfig *cacheapi.RegistryConfig
 	j, cacheExt := helper.FindExtension(shoot.Spec.Extensions, "registry-cache")
 	if j != -1 {
 		if cacheExt.ProviderConfig == nil {
 			return fmt.Errorf("providerConfig is not available for registry-cache extension")
 		}
 
-		cacheRegistryConfig := &cacheapi.RegistryConfig{}
+		cacheRegistryConfig = &cacheapi.RegistryConfig{}
 		if err := runtime.DecodeInto(s.decoder, cacheExt.ProviderConfig.Raw, cacheRegistryConfig); err != nil {
 			return fmt.Errorf("failed to decode providerConfig: %w", err)
 		}
 
 		allErrs = append(allErrs, validateMirrorConfigAgainstRegistryCache(mirrorConfig, cacheRegistryConfig, providerConfigPath)...)
+	}
 
+	if oldCacheRegistryConfig != nil {
+		allErrs = append(allErrs, validateMirrorConfigAgainstRemovedStrictRegistryCaches(mirrorConfig, oldCacheRegistryConfig, cacheRegistryConfig, providerConfigPath)...)
 	}
 
 	return allErrs.ToAggregate()
 }
 
+// validateReferencedSecrets validates the Secrets referenced by the mirror hosts.
+func (s *shoot) validateReferencedSecrets(ctx context.Context, mirrorConfig *mirrorapi.MirrorConfig, fldPath *field.Path, resources []core.NamedResourceReference, namespace string) (field.ErrorList, error) {
+	allErrs := field.ErrorList{}
+
+	for i, mirror := range mirrorConfig.Mirrors {
+		for j, host := range mirror.Hosts {
+			hostFldPath := fldPath.Child("mirrors").Index(i).Child("hosts").Index(j)
+
+			for _, ref := range []struct {
+				fldName  string
+				name     *string
+				validate func(*corev1.Secret, *field.Path, string) field.ErrorList
+			}{
+				{"caBundleSecretReferenceName", host.CABundleSecretReferenceName, validation.ValidateCABundleSecret},
+				{"clientCertificateSecretReferenceName", host.ClientCertificateSecretReferenceName, validation.ValidateClientCertificateSecret},
+				{"authSecretReferenceName", host.AuthSecretReferenceName, validation.ValidateAuthSecret},
+			} {
+				if ref.name == nil || len(*ref.name) == 0 {
+					continue
+				}
+
+				refFldPath := hostFldPath.Child(ref.fldName)
+
+				resource := gardencorehelper.GetResourceByName(resources, *ref.name)
+				if resource == nil || resource.ResourceRef.Kind != "Secret" {
+					allErrs = append(allErrs, field.Invalid(refFldPath, *ref.name, fmt.Sprintf("failed to find referenced resource with name %s and kind Secret", *ref.name)))
+					continue
+				}
+
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resource.ResourceRef.Name,
+						Namespace: namespace,
+					},
+				}
+				// Explicitly use the client.Reader to prevent controller-runtime to start Informer for Secrets
+				// under the hood. The latter increases the memory usage of the component.
+				if err := s.apiReader.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+					return allErrs, fmt.Errorf("failed to get secret %s for %s %s: %w", client.ObjectKeyFromObject(secret), ref.fldName, *ref.name, err)
+				}
+
+				allErrs = append(allErrs, ref.validate(secret, refFldPath, *ref.name)...)
+			}
+		}
+	}
+
+	return allErrs, nil
+}
+
+// validateMirrorConfigAgainstRegistryCache validates the mirrors whose upstream is also configured as a registry cache upstream.
+// The hosts of such mirrors are merged with the registry cache host into a single registry config. Host options which
+// require a hosts.toml file rendered by the extension cannot be expressed in the registry config and are forbidden.
+// Mirrors for the upstream of a strict registry cache are forbidden.
 func validateMirrorConfigAgainstRegistryCache(mirrorConfig *mirrorapi.MirrorConfig, cacheRegistryConfig *cacheapi.RegistryConfig, fldPath *field.Path) field.ErrorList {
-	upstreams := sets.New[string]()
+	upstreams, strictUpstreams := sets.New[string](), sets.New[string]()
 	for _, cache := range cacheRegistryConfig.Caches {
 		upstreams.Insert(cache.Upstream)
+		if ptr.Deref(cache.Strict, false) {
+			strictUpstreams.Insert(cache.Upstream)
+		}
 	}
 
 	var allErrs field.ErrorList
 	for i, mirror := range mirrorConfig.Mirrors {
-		configFldPath := fldPath.Child("mirrors").Index(i)
+		if !upstreams.Has(mirror.Upstream) {
+			continue
+		}
+
+		if strictUpstreams.Has(mirror.Upstream) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("mirrors").Index(i).Child("upstream"), fmt.Sprintf("upstream '%s' is configured as a strict registry cache upstream, image pulls from it must go through the registry cache only", mirror.Upstream)))
+			continue
+		}
+
+		for j, host := range mirror.Hosts {
+			hostFldPath := fldPath.Child("mirrors").Index(i).Child("hosts").Index(j)
+			detail := fmt.Sprintf("cannot be set for a mirror host of upstream '%s' which is also configured as a registry cache upstream", mirror.Upstream)
+
+			if host.ClientCertificateSecretReferenceName != nil {
+				allErrs = append(allErrs, field.Forbidden(hostFldPath.Child("clientCertificateSecretReferenceName"), detail))
+			}
+			if host.AuthSecretReferenceName != nil {
+				allErrs = append(allErrs, field.Forbidden(hostFldPath.Child("authSecretReferenceName"), detail))
+			}
+			if ptr.Deref(host.SkipVerify, false) {
+				allErrs = append(allErrs, field.Forbidden(hostFldPath.Child("skipVerify"), detail))
+			}
+			if registryutils.RequiresOverridePath(host.Host) {
+				allErrs = append(allErrs, field.Forbidden(hostFldPath.Child("host"), fmt.Sprintf("host with registry API root path cannot be used for upstream '%s' which is also configured as a registry cache upstream", mirror.Upstream)))
+			}
+		}
+	}
+
+	return allErrs
+}
 
-		if upstreams.Has(mirror.Upstream) {
-			allErrs = append(allErrs, field.Invalid(configFldPath.Child("upstream"), mirror.Upstream, fmt.Sprintf("upstream host '%s' is also configured as a registry cache upstream", mirror.Upstream)))
-		} else {
-			upstreams.Insert(mirror.Upstream)
+// validateMirrorConfigAgainstRemovedStrictRegistryCaches validates the mirrors whose upstream was configured as a strict
+// registry cache upstream in the old Shoot. gardener-node-agent removes the hosts.toml file written by the extension for
+// the strict registry cache after it applied the registry config of the mirror. Hence, such mirrors can only be added
+// with a subsequent update once the strict registry cache is removed.
+func validateMirrorConfigAgainstRemovedStrictRegistryCaches(mirrorConfig *mirrorapi.MirrorConfig, oldCacheRegistryConfig, cacheRegistryConfig *cacheapi.RegistryConfig, fldPath *field.Path) field.ErrorList {
+	strictUpstreams, oldStrictUpstreams := sets.New[string](), sets.New[string]()
+	if cacheRegistryConfig != nil {
+		for _, cache := range cacheRegistryConfig.Caches {
+			if ptr.Deref(cache.Strict, false) {
+				strictUpstreams.Insert(cache.Upstream)
+			}
+		}
+	}
+	for _, cache := range oldCacheRegistryConfig.Caches {
+		if ptr.Deref(cache.Strict, false) {
+			oldStrictUpstreams.Insert(cache.Upstream)
+		}
+	}
+
+	var allErrs field.ErrorList
+	for i, mirror := range mirrorConfig.Mirrors {
+		// Mirrors for the upstream of a strict registry cache are rejected by validateMirrorConfigAgainstRegistryCache.
+		if oldStrictUpstreams.Has(mirror.Upstream) && !strictUpstreams.Has(mirror.Upstream) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("mirrors").Index(i).Child("upstream"), fmt.Sprintf("upstream '%s' is configured as a strict registry cache upstream in the old Shoot, remove the strict registry cache first and add the mirror with a subsequent update", mirror.Upstream)))
 		}
 	}
 
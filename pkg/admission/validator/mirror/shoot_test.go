@@ -7,19 +7,26 @@ package mirror_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	"github.com/gardener/gardener/pkg/apis/core"
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
+	mockclient "github.com/gardener/gardener/third_party/mock/controller-runtime/client"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
+	"go.uber.org/mock/gomock"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/admission/validator/mirror"
 	mirrorinstall "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/install"
@@ -40,6 +47,8 @@ var _ = Describe("Shoot validator", func() {
 		size = resource.MustParse("20Gi")
 
 		shootValidator extensionswebhook.Validator
+		ctrl           *gomock.Controller
+		apiReader      *mockclient.MockReader
 
 		shoot *core.Shoot
 	)
@@ -52,7 +61,10 @@ var _ = Describe("Shoot validator", func() {
 
 			decoder := serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
 
-			shootValidator = mirror.NewShootValidator(decoder)
+			ctrl = gomock.NewController(GinkgoT())
+			apiReader = mockclient.NewMockReader(ctrl)
+
+			shootValidator = mirror.NewShootValidator(apiReader, decoder)
 
 			shoot = &core.Shoot{
 				ObjectMeta: metav1.ObjectMeta{
@@ -184,6 +196,64 @@ var _ = Describe("Shoot validator", func() {
 		})
 
 		It("should return err when registry-mirror providerConfig is invalid against registry-cache providerConfig", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{
+				Raw: encode(&v1alpha1.MirrorConfig{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: v1alpha1.SchemeGroupVersion.String(),
+						Kind:       "MirrorConfig",
+					},
+					Mirrors: []v1alpha1.MirrorConfiguration{
+						{
+							Upstream: "docker.io",
+							Hosts: []v1alpha1.MirrorHost{
+								{
+									Host:       "https://mirror.gcr.io",
+									SkipVerify: ptr.To(true),
+								},
+								{
+									Host: "https://artifactory.example.com/v2/docker-remote",
+								},
+							},
+						},
+					},
+				}),
+			}
+			shoot.Spec.Extensions = append(shoot.Spec.Extensions, core.Extension{
+				Type: "registry-cache",
+				ProviderConfig: &runtime.RawExtension{
+					Raw: encode(&registryv1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: registryv1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []registryv1alpha3.RegistryCache{
+							{
+								Upstream: "docker.io",
+								Volume: &registryv1alpha3.Volume{
+									Size: &size,
+								},
+							},
+						},
+					}),
+				},
+			})
+
+			err := shootValidator.Validate(ctx, shoot, nil)
+			Expect(err).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("spec.extensions[0].providerConfig.mirrors[0].hosts[0].skipVerify"),
+					"Detail": Equal("cannot be set for a mirror host of upstream 'docker.io' which is also configured as a registry cache upstream"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("spec.extensions[0].providerConfig.mirrors[0].hosts[1].host"),
+					"Detail": Equal("host with registry API root path cannot be used for upstream 'docker.io' which is also configured as a registry cache upstream"),
+				})),
+			))
+		})
+
+		It("should return err when registry-mirror is configured for the upstream of a strict registry cache", func() {
 			shoot.Spec.Extensions = append(shoot.Spec.Extensions, core.Extension{
 				Type: "registry-cache",
 				ProviderConfig: &runtime.RawExtension{
@@ -198,6 +268,7 @@ var _ = Describe("Shoot validator", func() {
 								Volume: &registryv1alpha3.Volume{
 									Size: &size,
 								},
+								Strict: ptr.To(true),
 							},
 						},
 					}),
@@ -206,16 +277,283 @@ var _ = Describe("Shoot validator", func() {
 
 			err := shootValidator.Validate(ctx, shoot, nil)
 			Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
-				"Type":     Equal(field.ErrorTypeInvalid),
-				"Field":    Equal("spec.extensions[0].providerConfig.mirrors[0].upstream"),
-				"BadValue": Equal("docker.io"),
-				"Detail":   Equal("upstream host 'docker.io' is also configured as a registry cache upstream"),
+				"Type":   Equal(field.ErrorTypeForbidden),
+				"Field":  Equal("spec.extensions[0].providerConfig.mirrors[0].upstream"),
+				"Detail": Equal("upstream 'docker.io' is configured as a strict registry cache upstream, image pulls from it must go through the registry cache only"),
+			}))))
+		})
+
+		It("should succeed when registry-mirror and registry-cache are configured for the same upstream", func() {
+			shoot.Spec.Extensions = append(shoot.Spec.Extensions, core.Extension{
+				Type: "registry-cache",
+				ProviderConfig: &runtime.RawExtension{
+					Raw: encode(&registryv1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: registryv1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []registryv1alpha3.RegistryCache{
+							{
+								Upstream: "docker.io",
+								Volume: &registryv1alpha3.Volume{
+									Size: &size,
+								},
+							},
+						},
+					}),
+				},
+			})
+
+			Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+		})
+
+		It("should return err when registry-mirror is added for the upstream of a strict registry cache removed with the same update", func() {
+			oldShoot := shoot.DeepCopy()
+			oldShoot.Spec.Extensions = []core.Extension{{
+				Type: "registry-cache",
+				ProviderConfig: &runtime.RawExtension{
+					Raw: encode(&registryv1alpha3.RegistryConfig{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: registryv1alpha3.SchemeGroupVersion.String(),
+							Kind:       "RegistryConfig",
+						},
+						Caches: []registryv1alpha3.RegistryCache{
+							{
+								Upstream: "docker.io",
+								Volume: &registryv1alpha3.Volume{
+									Size: &size,
+								},
+								Strict: ptr.To(true),
+							},
+						},
+					}),
+				},
+			}}
+
+			err := shootValidator.Validate(ctx, shoot, oldShoot)
+			Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeForbidden),
+				"Field":  Equal("spec.extensions[0].providerConfig.mirrors[0].upstream"),
+				"Detail": Equal("upstream 'docker.io' is configured as a strict registry cache upstream in the old Shoot, remove the strict registry cache first and add the mirror with a subsequent update"),
 			}))))
 		})
 
 		It("should succeed for valid Shoot", func() {
 			Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
 		})
+
+		It("should return err when an existing mirror switches to a host option requiring a hosts.toml file", func() {
+			oldShoot := shoot.DeepCopy()
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{
+				Raw: encode(&v1alpha1.MirrorConfig{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: v1alpha1.SchemeGroupVersion.String(),
+						Kind:       "MirrorConfig",
+					},
+					Mirrors: []v1alpha1.MirrorConfiguration{
+						{
+							Upstream: "docker.io",
+							Hosts: []v1alpha1.MirrorHost{
+								{
+									Host:       "https://mirror.gcr.io",
+									SkipVerify: ptr.To(true),
+								},
+							},
+						},
+					},
+				}),
+			}
+
+			err := shootValidator.Validate(ctx, shoot, oldShoot)
+			Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":  Equal(field.ErrorTypeForbidden),
+				"Field": Equal("spec.extensions[0].providerConfig.mirrors[0].hosts"),
+			}))))
+
+			Expect(shootValidator.Validate(ctx, oldShoot, shoot)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":  Equal(field.ErrorTypeForbidden),
+				"Field": Equal("spec.extensions[0].providerConfig.mirrors[0].hosts"),
+			}))))
+		})
+
+		It("should succeed for an unchanged Shoot", func() {
+			Expect(shootValidator.Validate(ctx, shoot, shoot.DeepCopy())).To(Succeed())
+		})
+
+		Context("referenced secrets", func() {
+			var (
+				fakeErr = fmt.Errorf("fake err")
+
+				caSecret     *corev1.Secret
+				clientSecret *corev1.Secret
+
+				expectGetSecret = func(name string, secret *corev1.Secret) {
+					apiReader.EXPECT().Get(ctx, client.ObjectKey{Namespace: "garden-tst", Name: name}, gomock.AssignableToTypeOf(&corev1.Secret{})).
+						DoAndReturn(func(_ context.Context, _ client.ObjectKey, obj *corev1.Secret, _ ...client.GetOption) error {
+							*obj = *secret
+							return nil
+						})
+				}
+			)
+
+			BeforeEach(func() {
+				certificate, err := (&secretsutils.CertificateSecretConfig{
+					Name:       "mirror",
+					CommonName: "mirror",
+					CertType:   secretsutils.CACert,
+				}).GenerateCertificate()
+				Expect(err).NotTo(HaveOccurred())
+
+				caSecret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "mirror-ca", Namespace: "garden-tst"},
+					Immutable:  ptr.To(true),
+					Data:       map[string][]byte{"ca.crt": certificate.CertificatePEM},
+				}
+				clientSecret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "mirror-client", Namespace: "garden-tst"},
+					Immutable:  ptr.To(true),
+					Data: map[string][]byte{
+						"tls.crt": certificate.CertificatePEM,
+						"tls.key": certificate.PrivateKeyPEM,
+					},
+				}
+
+				shoot.Spec.Resources = []core.NamedResourceReference{
+					{Name: "ca", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "mirror-ca"}},
+					{Name: "client", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "mirror-client"}},
+				}
+				shoot.Spec.Extensions[0].ProviderConfig.Raw = encode(&v1alpha1.MirrorConfig{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: v1alpha1.SchemeGroupVersion.String(),
+						Kind:       "MirrorConfig",
+					},
+					Mirrors: []v1alpha1.MirrorConfiguration{
+						{
+							Upstream: "docker.io",
+							Hosts: []v1alpha1.MirrorHost{
+								{
+									Host:                                 "https://mirror.example.com",
+									CABundleSecretReferenceName:          ptr.To("ca"),
+									ClientCertificateSecretReferenceName: ptr.To("client"),
+								},
+							},
+						},
+					},
+				})
+			})
+
+			It("should succeed for valid secrets", func() {
+				expectGetSecret("mirror-ca", caSecret)
+				expectGetSecret("mirror-client", clientSecret)
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should succeed for a valid auth secret", func() {
+				shoot.Spec.Resources = append(shoot.Spec.Resources, core.NamedResourceReference{
+					Name: "auth", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "mirror-auth"},
+				})
+				shoot.Spec.Extensions[0].ProviderConfig.Raw = encode(&v1alpha1.MirrorConfig{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: v1alpha1.SchemeGroupVersion.String(),
+						Kind:       "MirrorConfig",
+					},
+					Mirrors: []v1alpha1.MirrorConfiguration{
+						{
+							Upstream: "docker.io",
+							Hosts: []v1alpha1.MirrorHost{
+								{
+									Host:                    "https://mirror.example.com",
+									AuthSecretReferenceName: ptr.To("auth"),
+								},
+							},
+						},
+					},
+				})
+				expectGetSecret("mirror-auth", &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "mirror-auth", Namespace: "garden-tst"},
+					Immutable:  ptr.To(true),
+					Data:       map[string][]byte{"username": []byte("foo"), "password": []byte("bar")},
+				})
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err when the auth secret is invalid", func() {
+				shoot.Spec.Resources = append(shoot.Spec.Resources, core.NamedResourceReference{
+					Name: "auth", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "mirror-auth"},
+				})
+				shoot.Spec.Extensions[0].ProviderConfig.Raw = encode(&v1alpha1.MirrorConfig{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: v1alpha1.SchemeGroupVersion.String(),
+						Kind:       "MirrorConfig",
+					},
+					Mirrors: []v1alpha1.MirrorConfiguration{
+						{
+							Upstream: "docker.io",
+							Hosts: []v1alpha1.MirrorHost{
+								{
+									Host:                    "https://mirror.example.com",
+									AuthSecretReferenceName: ptr.To("auth"),
+								},
+							},
+						},
+					},
+				})
+				expectGetSecret("mirror-auth", &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "mirror-auth", Namespace: "garden-tst"},
+					Immutable:  ptr.To(true),
+					Data:       map[string][]byte{"username": []byte("foo")},
+				})
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("spec.extensions[0].providerConfig.mirrors[0].hosts[0].authSecretReferenceName"),
+						"Detail": Equal(`data entries "username" and "password" in referenced secret "garden-tst/mirror-auth" must be set together`),
+					})),
+				))
+			})
+
+			It("should return err when the referenced resource is missing", func() {
+				shoot.Spec.Resources = shoot.Spec.Resources[1:]
+				expectGetSecret("mirror-client", clientSecret)
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("spec.extensions[0].providerConfig.mirrors[0].hosts[0].caBundleSecretReferenceName"),
+						"Detail": Equal("failed to find referenced resource with name ca and kind Secret"),
+					})),
+				))
+			})
+
+			It("should return err when failed to get secret", func() {
+				apiReader.EXPECT().Get(ctx, client.ObjectKey{Namespace: "garden-tst", Name: "mirror-ca"}, gomock.AssignableToTypeOf(&corev1.Secret{})).Return(fakeErr)
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(MatchError(fakeErr))
+			})
+
+			It("should return err when the secrets are invalid", func() {
+				delete(caSecret.Data, "ca.crt")
+				clientSecret.Immutable = nil
+				expectGetSecret("mirror-ca", caSecret)
+				expectGetSecret("mirror-client", clientSecret)
+
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("spec.extensions[0].providerConfig.mirrors[0].hosts[0].caBundleSecretReferenceName"),
+						"Detail": Equal(`missing "ca.crt" data entry in referenced secret "garden-tst/mirror-ca"`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("spec.extensions[0].providerConfig.mirrors[0].hosts[0].clientCertificateSecretReferenceName"),
+						"Detail": Equal(`referenced secret "garden-tst/mirror-client" should be immutable`),
+					})),
+				))
+			})
+		})
 	})
 })
 
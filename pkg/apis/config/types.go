@@ -5,6 +5,7 @@
 package config
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,4 +14,42 @@ import (
 // Configuration contains information about the registry service configuration.
 type Configuration struct {
 	metav1.TypeMeta
+
+	// SharedCache contains settings for the shared registry caches in the seed.
+	SharedCache *SharedCache
+	// Certificates contains settings for the TLS certificates of the Shoot and the shared registry caches.
+	Certificates *Certificates
+}
+
+// Certificates contains settings for the TLS certificates of the Shoot and the shared registry caches.
+type Certificates struct {
+	// CAValidity is the validity of the CA certificate which signs the server certificates of the registry caches.
+	CAValidity *metav1.Duration
+	// ServerCertificateValidity is the validity of the server certificates of the registry caches.
+	ServerCertificateValidity *metav1.Duration
+}
+
+// SharedCache contains settings for the shared registry caches in the seed.
+// The Shoot registry caches for the same upstreams use the shared registry caches as remote registry.
+type SharedCache struct {
+	// Namespace is the seed namespace in which the shared registry caches are deployed.
+	Namespace string
+	// Caches is a slice of shared registry caches to deploy.
+	Caches []SharedRegistryCache
+	// LoadBalancerSourceRanges are the CIDRs from which the load balancers of the shared registry caches are reachable.
+	LoadBalancerSourceRanges []string
+}
+
+// SharedRegistryCache represents a shared registry cache to deploy in the seed.
+type SharedRegistryCache struct {
+	// Upstream is the remote registry host to cache.
+	Upstream string
+	// RemoteURL is the remote registry URL.
+	RemoteURL *string
+	// Size is the size of the shared registry cache volume.
+	Size *resource.Quantity
+	// StorageClassName is the name of the StorageClass used by the shared registry cache volume.
+	StorageClassName *string
+	// GarbageCollectionTTL is the time to live of a blob in the shared registry cache.
+	GarbageCollectionTTL *metav1.Duration
 }
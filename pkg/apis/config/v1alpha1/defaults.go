@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetDefaults_SharedCache sets the defaults for a SharedCache.
+func SetDefaults_SharedCache(sharedCache *SharedCache) {
+	if sharedCache.Namespace == "" {
+		sharedCache.Namespace = "registry-cache-shared"
+	}
+}
+
+// SetDefaults_SharedRegistryCache sets the defaults for a SharedRegistryCache.
+func SetDefaults_SharedRegistryCache(cache *SharedRegistryCache) {
+	if cache.Size == nil {
+		defaultSize := resource.MustParse("100Gi")
+		cache.Size = &defaultSize
+	}
+
+	if cache.GarbageCollectionTTL == nil {
+		cache.GarbageCollectionTTL = &metav1.Duration{Duration: 7 * 24 * time.Hour}
+	}
+}
@@ -5,6 +5,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,4 +14,62 @@ import (
 // Configuration contains information about the registry service configuration.
 type Configuration struct {
 	metav1.TypeMeta `json:",inline"`
+
+	// SharedCache contains settings for the shared registry caches in the seed.
+	// +optional
+	SharedCache *SharedCache `json:"sharedCache,omitempty"`
+	// Certificates contains settings for the TLS certificates of the Shoot and the shared registry caches.
+	// +optional
+	Certificates *Certificates `json:"certificates,omitempty"`
+}
+
+// Certificates contains settings for the TLS certificates of the Shoot and the shared registry caches.
+type Certificates struct {
+	// CAValidity is the validity of the CA certificate which signs the server certificates of the registry caches.
+	// Defaults to 17520h (730 days).
+	// +optional
+	CAValidity *metav1.Duration `json:"caValidity,omitempty"`
+	// ServerCertificateValidity is the validity of the server certificates of the registry caches.
+	// Defaults to 2160h (90 days).
+	// +optional
+	ServerCertificateValidity *metav1.Duration `json:"serverCertificateValidity,omitempty"`
+}
+
+// SharedCache contains settings for the shared registry caches in the seed.
+// The Shoot registry caches for the same upstreams use the shared registry caches as remote registry.
+type SharedCache struct {
+	// Namespace is the seed namespace in which the shared registry caches are deployed.
+	// Defaults to "registry-cache-shared".
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Caches is a slice of shared registry caches to deploy.
+	Caches []SharedRegistryCache `json:"caches"`
+	// LoadBalancerSourceRanges are the CIDRs from which the load balancers of the shared registry caches are reachable.
+	// The shared registry caches do not require authentication, hence the CIDRs should contain only the egress
+	// addresses of the Shoot Nodes using them.
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges"`
+}
+
+// SharedRegistryCache represents a shared registry cache to deploy in the seed.
+type SharedRegistryCache struct {
+	// Upstream is the remote registry host to cache.
+	// The value must be a valid DNS subdomain (RFC 1123) and optionally a port.
+	Upstream string `json:"upstream"`
+	// RemoteURL is the remote registry URL. The format must be `<scheme><host>[:<port>][<path>]` where
+	// `<scheme>` is `https://` or `http://` and `<host>[:<port>]` corresponds to the Upstream.
+	// The optional `<path>` is the path prefix under which the registry API root `/v2` is served.
+	// +optional
+	RemoteURL *string `json:"remoteURL,omitempty"`
+	// Size is the size of the shared registry cache volume.
+	// Defaults to 100Gi.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// StorageClassName is the name of the StorageClass used by the shared registry cache volume.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// GarbageCollectionTTL is the time to live of a blob in the shared registry cache.
+	// Set to 0s to disable the garbage collection.
+	// Defaults to 168h (7 days).
+	// +optional
+	GarbageCollectionTTL *metav1.Duration `json:"garbageCollectionTTL,omitempty"`
 }
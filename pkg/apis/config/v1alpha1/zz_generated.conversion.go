@@ -9,7 +9,11 @@
 package v1alpha1
 
 import (
+	unsafe "unsafe"
+
 	config "github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -21,6 +25,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*Certificates)(nil), (*config.Certificates)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Certificates_To_config_Certificates(a.(*Certificates), b.(*config.Certificates), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.Certificates)(nil), (*Certificates)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_Certificates_To_v1alpha1_Certificates(a.(*config.Certificates), b.(*Certificates), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Configuration)(nil), (*config.Configuration)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Configuration_To_config_Configuration(a.(*Configuration), b.(*config.Configuration), scope)
 	}); err != nil {
@@ -31,10 +45,54 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*SharedCache)(nil), (*config.SharedCache)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SharedCache_To_config_SharedCache(a.(*SharedCache), b.(*config.SharedCache), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.SharedCache)(nil), (*SharedCache)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_SharedCache_To_v1alpha1_SharedCache(a.(*config.SharedCache), b.(*SharedCache), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SharedRegistryCache)(nil), (*config.SharedRegistryCache)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SharedRegistryCache_To_config_SharedRegistryCache(a.(*SharedRegistryCache), b.(*config.SharedRegistryCache), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.SharedRegistryCache)(nil), (*SharedRegistryCache)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_SharedRegistryCache_To_v1alpha1_SharedRegistryCache(a.(*config.SharedRegistryCache), b.(*SharedRegistryCache), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
+func autoConvert_v1alpha1_Certificates_To_config_Certificates(in *Certificates, out *config.Certificates, s conversion.Scope) error {
+	out.CAValidity = (*v1.Duration)(unsafe.Pointer(in.CAValidity))
+	out.ServerCertificateValidity = (*v1.Duration)(unsafe.Pointer(in.ServerCertificateValidity))
+	return nil
+}
+
+// Convert_v1alpha1_Certificates_To_config_Certificates is an autogenerated conversion function.
+func Convert_v1alpha1_Certificates_To_config_Certificates(in *Certificates, out *config.Certificates, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Certificates_To_config_Certificates(in, out, s)
+}
+
+func autoConvert_config_Certificates_To_v1alpha1_Certificates(in *config.Certificates, out *Certificates, s conversion.Scope) error {
+	out.CAValidity = (*v1.Duration)(unsafe.Pointer(in.CAValidity))
+	out.ServerCertificateValidity = (*v1.Duration)(unsafe.Pointer(in.ServerCertificateValidity))
+	return nil
+}
+
+// Convert_config_Certificates_To_v1alpha1_Certificates is an autogenerated conversion function.
+func Convert_config_Certificates_To_v1alpha1_Certificates(in *config.Certificates, out *Certificates, s conversion.Scope) error {
+	return autoConvert_config_Certificates_To_v1alpha1_Certificates(in, out, s)
+}
+
 func autoConvert_v1alpha1_Configuration_To_config_Configuration(in *Configuration, out *config.Configuration, s conversion.Scope) error {
+	out.SharedCache = (*config.SharedCache)(unsafe.Pointer(in.SharedCache))
+	out.Certificates = (*config.Certificates)(unsafe.Pointer(in.Certificates))
 	return nil
 }
 
@@ -44,6 +102,8 @@ func Convert_v1alpha1_Configuration_To_config_Configuration(in *Configuration, o
 }
 
 func autoConvert_config_Configuration_To_v1alpha1_Configuration(in *config.Configuration, out *Configuration, s conversion.Scope) error {
+	out.SharedCache = (*SharedCache)(unsafe.Pointer(in.SharedCache))
+	out.Certificates = (*Certificates)(unsafe.Pointer(in.Certificates))
 	return nil
 }
 
@@ -51,3 +111,55 @@ func autoConvert_config_Configuration_To_v1alpha1_Configuration(in *config.Confi
 func Convert_config_Configuration_To_v1alpha1_Configuration(in *config.Configuration, out *Configuration, s conversion.Scope) error {
 	return autoConvert_config_Configuration_To_v1alpha1_Configuration(in, out, s)
 }
+
+func autoConvert_v1alpha1_SharedCache_To_config_SharedCache(in *SharedCache, out *config.SharedCache, s conversion.Scope) error {
+	out.Namespace = in.Namespace
+	out.Caches = *(*[]config.SharedRegistryCache)(unsafe.Pointer(&in.Caches))
+	out.LoadBalancerSourceRanges = *(*[]string)(unsafe.Pointer(&in.LoadBalancerSourceRanges))
+	return nil
+}
+
+// Convert_v1alpha1_SharedCache_To_config_SharedCache is an autogenerated conversion function.
+func Convert_v1alpha1_SharedCache_To_config_SharedCache(in *SharedCache, out *config.SharedCache, s conversion.Scope) error {
+	return autoConvert_v1alpha1_SharedCache_To_config_SharedCache(in, out, s)
+}
+
+func autoConvert_config_SharedCache_To_v1alpha1_SharedCache(in *config.SharedCache, out *SharedCache, s conversion.Scope) error {
+	out.Namespace = in.Namespace
+	out.Caches = *(*[]SharedRegistryCache)(unsafe.Pointer(&in.Caches))
+	out.LoadBalancerSourceRanges = *(*[]string)(unsafe.Pointer(&in.LoadBalancerSourceRanges))
+	return nil
+}
+
+// Convert_config_SharedCache_To_v1alpha1_SharedCache is an autogenerated conversion function.
+func Convert_config_SharedCache_To_v1alpha1_SharedCache(in *config.SharedCache, out *SharedCache, s conversion.Scope) error {
+	return autoConvert_config_SharedCache_To_v1alpha1_SharedCache(in, out, s)
+}
+
+func autoConvert_v1alpha1_SharedRegistryCache_To_config_SharedRegistryCache(in *SharedRegistryCache, out *config.SharedRegistryCache, s conversion.Scope) error {
+	out.Upstream = in.Upstream
+	out.RemoteURL = (*string)(unsafe.Pointer(in.RemoteURL))
+	out.Size = (*resource.Quantity)(unsafe.Pointer(in.Size))
+	out.StorageClassName = (*string)(unsafe.Pointer(in.StorageClassName))
+	out.GarbageCollectionTTL = (*v1.Duration)(unsafe.Pointer(in.GarbageCollectionTTL))
+	return nil
+}
+
+// Convert_v1alpha1_SharedRegistryCache_To_config_SharedRegistryCache is an autogenerated conversion function.
+func Convert_v1alpha1_SharedRegistryCache_To_config_SharedRegistryCache(in *SharedRegistryCache, out *config.SharedRegistryCache, s conversion.Scope) error {
+	return autoConvert_v1alpha1_SharedRegistryCache_To_config_SharedRegistryCache(in, out, s)
+}
+
+func autoConvert_config_SharedRegistryCache_To_v1alpha1_SharedRegistryCache(in *config.SharedRegistryCache, out *SharedRegistryCache, s conversion.Scope) error {
+	out.Upstream = in.Upstream
+	out.RemoteURL = (*string)(unsafe.Pointer(in.RemoteURL))
+	out.Size = (*resource.Quantity)(unsafe.Pointer(in.Size))
+	out.StorageClassName = (*string)(unsafe.Pointer(in.StorageClassName))
+	out.GarbageCollectionTTL = (*v1.Duration)(unsafe.Pointer(in.GarbageCollectionTTL))
+	return nil
+}
+
+// Convert_config_SharedRegistryCache_To_v1alpha1_SharedRegistryCache is an autogenerated conversion function.
+func Convert_config_SharedRegistryCache_To_v1alpha1_SharedRegistryCache(in *config.SharedRegistryCache, out *SharedRegistryCache, s conversion.Scope) error {
+	return autoConvert_config_SharedRegistryCache_To_v1alpha1_SharedRegistryCache(in, out, s)
+}
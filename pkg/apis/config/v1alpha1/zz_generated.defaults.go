@@ -16,5 +16,16 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&Configuration{}, func(obj interface{}) { SetObjectDefaults_Configuration(obj.(*Configuration)) })
 	return nil
 }
+
+func SetObjectDefaults_Configuration(in *Configuration) {
+	if in.SharedCache != nil {
+		SetDefaults_SharedCache(in.SharedCache)
+		for i := range in.SharedCache.Caches {
+			a := &in.SharedCache.Caches[i]
+			SetDefaults_SharedRegistryCache(a)
+		}
+	}
+}
@@ -5,14 +5,96 @@
 package validation
 
 import (
+	"net"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	registryvalidation "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/validation"
 )
 
 // ValidateConfiguration validates the passed configuration instance.
-func ValidateConfiguration(_ *config.Configuration) field.ErrorList {
+func ValidateConfiguration(config *config.Configuration) field.ErrorList {
 	allErrs := field.ErrorList{}
 
+	if config.SharedCache != nil {
+		allErrs = append(allErrs, validateSharedCache(config.SharedCache, field.NewPath("sharedCache"))...)
+	}
+
+	if config.Certificates != nil {
+		allErrs = append(allErrs, validateCertificates(config.Certificates, field.NewPath("certificates"))...)
+	}
+
+	return allErrs
+}
+
+func validateCertificates(certificates *config.Certificates, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, validity := range []struct {
+		fldName string
+		value   *metav1.Duration
+	}{
+		{"caValidity", certificates.CAValidity},
+		{"serverCertificateValidity", certificates.ServerCertificateValidity},
+	} {
+		if validity.value != nil && validity.value.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(validity.fldName), validity.value.Duration.String(), "validity must be positive"))
+		}
+	}
+
+	if certificates.CAValidity != nil && certificates.ServerCertificateValidity != nil && certificates.ServerCertificateValidity.Duration > certificates.CAValidity.Duration {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("serverCertificateValidity"), certificates.ServerCertificateValidity.Duration.String(), "validity must not be greater than the CA validity"))
+	}
+
+	return allErrs
+}
+
+func validateSharedCache(sharedCache *config.SharedCache, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, msg := range apivalidation.ValidateNamespaceName(sharedCache.Namespace, false) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), sharedCache.Namespace, msg))
+	}
+
+	if len(sharedCache.Caches) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("caches"), "at least one cache must be provided"))
+	}
+
+	upstreams := sets.New[string]()
+	for i, cache := range sharedCache.Caches {
+		cacheFldPath := fldPath.Child("caches").Index(i)
+
+		allErrs = append(allErrs, registryvalidation.ValidateUpstream(cacheFldPath.Child("upstream"), cache.Upstream)...)
+		if cache.RemoteURL != nil {
+			allErrs = append(allErrs, registryvalidation.ValidateRemoteURL(cacheFldPath.Child("remoteURL"), *cache.RemoteURL)...)
+		}
+		if cache.Size != nil && cache.Size.Cmp(resource.Quantity{}) <= 0 {
+			allErrs = append(allErrs, field.Invalid(cacheFldPath.Child("size"), cache.Size.String(), "must be greater than 0"))
+		}
+		if cache.GarbageCollectionTTL != nil && cache.GarbageCollectionTTL.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(cacheFldPath.Child("garbageCollectionTTL"), cache.GarbageCollectionTTL.Duration.String(), "ttl must be a non-negative duration"))
+		}
+
+		if upstreams.Has(cache.Upstream) {
+			allErrs = append(allErrs, field.Duplicate(cacheFldPath.Child("upstream"), cache.Upstream))
+		} else {
+			upstreams.Insert(cache.Upstream)
+		}
+	}
+
+	if len(sharedCache.LoadBalancerSourceRanges) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("loadBalancerSourceRanges"), "at least one CIDR must be provided"))
+	}
+	for i, cidr := range sharedCache.LoadBalancerSourceRanges {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("loadBalancerSourceRanges").Index(i), cidr, "must be a valid CIDR"))
+		}
+	}
+
 	return allErrs
 }
@@ -5,20 +5,120 @@
 package validation_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 	gomegatypes "github.com/onsi/gomega/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config/validation"
 )
 
 var _ = Describe("Validation", func() {
+	var (
+		size        = resource.MustParse("100Gi")
+		invalidSize = resource.MustParse("-1Gi")
+	)
+
 	DescribeTable("#ValidateConfiguration",
 		func(config config.Configuration, match gomegatypes.GomegaMatcher) {
 			err := validation.ValidateConfiguration(&config)
 			Expect(err).To(match)
 		},
 		Entry("config", config.Configuration{}, BeEmpty()),
+		Entry("valid shared cache", config.Configuration{
+			SharedCache: &config.SharedCache{
+				Namespace: "registry-cache-shared",
+				Caches: []config.SharedRegistryCache{
+					{Upstream: "docker.io", RemoteURL: ptr.To("https://registry-1.docker.io"), Size: &size},
+					{Upstream: "ghcr.io", GarbageCollectionTTL: &metav1.Duration{}},
+				},
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8", "2001:db8::/32"},
+			},
+		}, BeEmpty()),
+		Entry("shared cache without caches", config.Configuration{
+			SharedCache: &config.SharedCache{
+				Namespace: "registry-cache-shared",
+			},
+		}, ConsistOf(
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeRequired),
+				"Field":  Equal("sharedCache.caches"),
+				"Detail": Equal("at least one cache must be provided"),
+			})),
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeRequired),
+				"Field":  Equal("sharedCache.loadBalancerSourceRanges"),
+				"Detail": Equal("at least one CIDR must be provided"),
+			})),
+		)),
+		Entry("invalid shared cache", config.Configuration{
+			SharedCache: &config.SharedCache{
+				Namespace: "Invalid_Namespace",
+				Caches: []config.SharedRegistryCache{
+					{Upstream: "docker.io", RemoteURL: ptr.To("registry-1.docker.io"), Size: &invalidSize},
+					{Upstream: "docker.io", GarbageCollectionTTL: &metav1.Duration{Duration: -1}},
+				},
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8", "10.0.0.1"},
+			},
+		}, ConsistOf(
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":  Equal(field.ErrorTypeInvalid),
+				"Field": Equal("sharedCache.namespace"),
+			})),
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeInvalid),
+				"Field":  Equal("sharedCache.caches[0].remoteURL"),
+				"Detail": Equal("url must start with 'http://' or 'https://' scheme"),
+			})),
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeInvalid),
+				"Field":  Equal("sharedCache.caches[0].size"),
+				"Detail": Equal("must be greater than 0"),
+			})),
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeInvalid),
+				"Field":  Equal("sharedCache.caches[1].garbageCollectionTTL"),
+				"Detail": Equal("ttl must be a non-negative duration"),
+			})),
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":  Equal(field.ErrorTypeDuplicate),
+				"Field": Equal("sharedCache.caches[1].upstream"),
+			})),
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeInvalid),
+				"Field":  Equal("sharedCache.loadBalancerSourceRanges[1]"),
+				"Detail": Equal("must be a valid CIDR"),
+			})),
+		)),
+		Entry("valid certificates", config.Configuration{
+			Certificates: &config.Certificates{
+				CAValidity:                &metav1.Duration{Duration: 365 * 24 * time.Hour},
+				ServerCertificateValidity: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+			},
+		}, BeEmpty()),
+		Entry("invalid certificates", config.Configuration{
+			Certificates: &config.Certificates{
+				CAValidity:                &metav1.Duration{Duration: -24 * time.Hour},
+				ServerCertificateValidity: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+			},
+		}, ConsistOf(
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeInvalid),
+				"Field":  Equal("certificates.caValidity"),
+				"Detail": Equal("validity must be positive"),
+			})),
+			PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(field.ErrorTypeInvalid),
+				"Field":  Equal("certificates.serverCertificateValidity"),
+				"Detail": Equal("validity must not be greater than the CA validity"),
+			})),
+		)),
 	)
 })
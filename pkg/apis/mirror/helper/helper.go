@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package helper
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/utils/containerd"
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+)
+
+// AuthHeaders returns the HTTP headers which are sent to a mirror host for the given auth Secret.
+// The 'username' and 'password' data keys result in a basic authentication 'Authorization' header, the 'token' data
+// key results in a bearer token 'Authorization' header and each 'header.<name>' data key results in a '<name>' header.
+func AuthHeaders(secret *corev1.Secret) map[string][]string {
+	headers := map[string][]string{}
+
+	for key, value := range secret.Data {
+		if name, ok := strings.CutPrefix(key, mirror.AuthSecretHeaderKeyPrefix); ok {
+			headers[name] = []string{string(value)}
+		}
+	}
+
+	username, hasUsername := secret.Data[mirror.AuthSecretUsernameKey]
+	password, hasPassword := secret.Data[mirror.AuthSecretPasswordKey]
+	if hasUsername && hasPassword {
+		credentials := base64.StdEncoding.EncodeToString([]byte(string(username) + ":" + string(password)))
+		headers["Authorization"] = []string{"Basic " + credentials}
+	}
+
+	if token, ok := secret.Data[mirror.AuthSecretTokenKey]; ok {
+		headers["Authorization"] = []string{"Bearer " + string(token)}
+	}
+
+	return headers
+}
+
+// ComputeMirrorStatus returns the effective containerd host configurations of the given mirror configuration. The given
+// secretName function returns the name of the Secret in the Shoot namespace for a resource reference name.
+func ComputeMirrorStatus(mirrorConfig *mirror.MirrorConfig, secretName func(referenceName string) (string, error)) (*mirror.MirrorStatus, error) {
+	mirrors := make([]mirror.MirrorConfigurationStatus, 0, len(mirrorConfig.Mirrors))
+	for _, m := range mirrorConfig.Mirrors {
+		mirrorStatus := mirror.MirrorConfigurationStatus{
+			Upstream: m.Upstream,
+		}
+		if m.Upstream == mirror.WildcardUpstream {
+			mirrorStatus.Upstream = containerd.DefaultUpstream
+		} else {
+			mirrorStatus.Server = ptr.To(registryutils.GetUpstreamURL(m.Upstream))
+		}
+
+		for _, host := range m.Hosts {
+			hostStatus := mirror.MirrorHostStatus{
+				Host:         host.Host,
+				Capabilities: host.Capabilities,
+				OverridePath: registryutils.RequiresOverridePath(host.Host),
+				SkipVerify:   ptr.Deref(host.SkipVerify, false),
+			}
+
+			for _, ref := range []struct {
+				referenceName *string
+				secretName    **string
+			}{
+				{host.CABundleSecretReferenceName, &hostStatus.CABundleSecretName},
+				{host.ClientCertificateSecretReferenceName, &hostStatus.ClientCertificateSecretName},
+				{host.AuthSecretReferenceName, &hostStatus.AuthSecretName},
+			} {
+				if ref.referenceName == nil {
+					continue
+				}
+
+				name, err := secretName(*ref.referenceName)
+				if err != nil {
+					return nil, err
+				}
+				*ref.secretName = &name
+			}
+
+			mirrorStatus.Hosts = append(mirrorStatus.Hosts, hostStatus)
+		}
+
+		mirrors = append(mirrors, mirrorStatus)
+	}
+
+	return &mirror.MirrorStatus{Mirrors: mirrors}, nil
+}
+
+// RequiresHostsFile returns true if the given mirror uses host options which are not supported by containerd's registry
+// configuration via the OperatingSystemConfig. The hosts.toml file of such a mirror is written by the extension itself.
+func RequiresHostsFile(mirrorConfiguration mirror.MirrorConfiguration) bool {
+	return slices.ContainsFunc(mirrorConfiguration.Hosts, func(host mirror.MirrorHost) bool {
+		return host.ClientCertificateSecretReferenceName != nil || host.AuthSecretReferenceName != nil || ptr.Deref(host.SkipVerify, false) || registryutils.RequiresOverridePath(host.Host)
+	})
+}
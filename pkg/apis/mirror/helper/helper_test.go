@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package helper_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/helper"
+)
+
+func TestHelper(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "APIs Mirror Helper Suite")
+}
+
+var _ = Describe("Helpers", func() {
+	DescribeTable("#AuthHeaders",
+		func(data map[string][]byte, expected map[string][]string) {
+			Expect(helper.AuthHeaders(&corev1.Secret{Data: data})).To(Equal(expected))
+		},
+
+		Entry("no data", nil, map[string][]string{}),
+		Entry("basic authentication",
+			map[string][]byte{"username": []byte("foo"), "password": []byte("bar")},
+			map[string][]string{"Authorization": {"Basic Zm9vOmJhcg=="}},
+		),
+		Entry("bearer token authentication",
+			map[string][]byte{"token": []byte("s3cr3t")},
+			map[string][]string{"Authorization": {"Bearer s3cr3t"}},
+		),
+		Entry("custom headers",
+			map[string][]byte{"header.X-JFrog-Art-Api": []byte("key"), "header.X-Foo": []byte("bar")},
+			map[string][]string{"X-JFrog-Art-Api": {"key"}, "X-Foo": {"bar"}},
+		),
+		Entry("basic authentication and custom headers",
+			map[string][]byte{"username": []byte("foo"), "password": []byte("bar"), "header.X-Foo": []byte("bar")},
+			map[string][]string{"Authorization": {"Basic Zm9vOmJhcg=="}, "X-Foo": {"bar"}},
+		),
+	)
+
+	DescribeTable("#RequiresHostsFile",
+		func(host mirror.MirrorHost, expected bool) {
+			Expect(helper.RequiresHostsFile(mirror.MirrorConfiguration{
+				Upstream: "docker.io",
+				Hosts:    []mirror.MirrorHost{{Host: "https://mirror.gcr.io"}, host},
+			})).To(Equal(expected))
+		},
+
+		Entry("host with CA bundle", mirror.MirrorHost{Host: "https://mirror.example.com", CABundleSecretReferenceName: ptr.To("mirror-ca")}, false),
+		Entry("host with client certificate", mirror.MirrorHost{Host: "https://mirror.example.com", ClientCertificateSecretReferenceName: ptr.To("mirror-client-certificate")}, true),
+		Entry("host with auth", mirror.MirrorHost{Host: "https://mirror.example.com", AuthSecretReferenceName: ptr.To("mirror-auth")}, true),
+		Entry("host with skip verify", mirror.MirrorHost{Host: "https://mirror.example.com", SkipVerify: ptr.To(true)}, true),
+		Entry("host with registry API root path", mirror.MirrorHost{Host: "https://artifactory.example.com/v2/docker-remote"}, true),
+	)
+})
@@ -22,6 +22,7 @@ type MirrorConfig struct {
 type MirrorConfiguration struct {
 	// Upstream is the remote registry host to mirror.
 	// The value must be a valid DNS subdomain (RFC 1123) and optionally a port.
+	// The wildcard value "*" configures the mirror for all upstreams without an explicit mirror configuration.
 	Upstream string
 	// Hosts are the mirror hosts to be used for the upstream.
 	Hosts []MirrorHost
@@ -29,12 +30,26 @@ type MirrorConfiguration struct {
 
 // MirrorHost represents a mirror host.
 type MirrorHost struct {
-	// Host is the mirror host.
+	// Host is the mirror host. The format must be `<scheme><host>[:<port>][<path>]` where `<scheme>` is `https://` or `http://`.
+	// The optional `<path>` is either the path prefix under which the registry API root `/v2` is served or, if it
+	// contains a `v2` segment which is not the last one, the registry API root itself (e.g. `https://artifactory.example.com/v2/docker-remote`).
 	Host string
 	// Capabilities are the operations a host is capable of performing.
 	// This also represents the set of operations for which the mirror host may be trusted to perform.
 	// The supported values are "pull" and "resolve".
 	Capabilities []MirrorHostCapability
+	// CABundleSecretReferenceName is the name of the reference for the Secret containing the CA bundle used to verify
+	// the TLS certificate of the mirror host. The Secret must contain the CA bundle under the 'ca.crt' data key.
+	CABundleSecretReferenceName *string
+	// ClientCertificateSecretReferenceName is the name of the reference for the Secret containing the client certificate
+	// used to authenticate against the mirror host. The Secret must contain the 'tls.crt' and 'tls.key' data keys.
+	ClientCertificateSecretReferenceName *string
+	// SkipVerify disables the verification of the mirror host's TLS certificate.
+	SkipVerify *bool
+	// AuthSecretReferenceName is the name of the reference for the Secret containing the credentials or headers used to
+	// authenticate against the mirror host. The Secret may contain the 'username' and 'password' data keys (basic
+	// authentication), the 'token' data key (bearer token authentication) and 'header.<name>' data keys (custom headers).
+	AuthSecretReferenceName *string
 }
 
 // MirrorHostCapability represents a mirror host capability.
@@ -46,3 +61,61 @@ const (
 	// MirrorHostCapabilityResolve represents the capability to fetch manifests by name.
 	MirrorHostCapabilityResolve MirrorHostCapability = "resolve"
 )
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MirrorStatus contains the effective containerd host configurations of the registry mirrors.
+type MirrorStatus struct {
+	metav1.TypeMeta
+
+	// Mirrors is a slice of the effective containerd host configurations of the registry mirrors.
+	Mirrors []MirrorConfigurationStatus
+}
+
+// MirrorConfigurationStatus represents the effective containerd host configuration of a registry mirror.
+type MirrorConfigurationStatus struct {
+	// Upstream is the upstream of the containerd host configuration.
+	// It is "_default" for a mirror with wildcard upstream.
+	Upstream string
+	// Server is the upstream registry server which is used when all mirror hosts fail.
+	// It is nil for a mirror with wildcard upstream.
+	Server *string
+	// Hosts are the effective mirror hosts.
+	Hosts []MirrorHostStatus
+}
+
+// MirrorHostStatus represents an effective mirror host.
+type MirrorHostStatus struct {
+	// Host is the mirror host.
+	Host string
+	// Capabilities are the operations the host is capable of performing.
+	Capabilities []MirrorHostCapability
+	// OverridePath denotes that the path of the host is the registry API root.
+	OverridePath bool
+	// SkipVerify disables the verification of the mirror host's TLS certificate.
+	SkipVerify bool
+	// CABundleSecretName is the name of the Secret in the Shoot namespace containing the CA bundle of the host.
+	CABundleSecretName *string
+	// ClientCertificateSecretName is the name of the Secret in the Shoot namespace containing the client certificate
+	// of the host.
+	ClientCertificateSecretName *string
+	// AuthSecretName is the name of the Secret in the Shoot namespace containing the auth credentials and headers of
+	// the host.
+	AuthSecretName *string
+}
+
+// WildcardUpstream is the upstream of a mirror configuration which applies to all upstreams without an explicit mirror
+// configuration. It is configured as containerd's "_default" host configuration.
+const WildcardUpstream = "*"
+
+const (
+	// AuthSecretUsernameKey is the data key of the username for basic authentication in a mirror host auth Secret.
+	AuthSecretUsernameKey = "username"
+	// AuthSecretPasswordKey is the data key of the password for basic authentication in a mirror host auth Secret.
+	AuthSecretPasswordKey = "password"
+	// AuthSecretTokenKey is the data key of the bearer token in a mirror host auth Secret.
+	AuthSecretTokenKey = "token"
+	// AuthSecretHeaderKeyPrefix is the prefix of the data keys of custom headers in a mirror host auth Secret.
+	// The remainder of the data key is the header name.
+	AuthSecretHeaderKeyPrefix = "header."
+)
@@ -22,6 +22,7 @@ type MirrorConfig struct {
 type MirrorConfiguration struct {
 	// Upstream is the remote registry host to mirror.
 	// The value must be a valid DNS subdomain (RFC 1123) and optionally a port.
+	// The wildcard value "*" configures the mirror for all upstreams without an explicit mirror configuration.
 	Upstream string `json:"upstream"`
 	// Hosts are the mirror hosts to be used for the upstream.
 	Hosts []MirrorHost `json:"hosts"`
@@ -29,7 +30,9 @@ type MirrorConfiguration struct {
 
 // MirrorHost represents a mirror host.
 type MirrorHost struct {
-	// Host is the mirror host.
+	// Host is the mirror host. The format must be `<scheme><host>[:<port>][<path>]` where `<scheme>` is `https://` or `http://`.
+	// The optional `<path>` is either the path prefix under which the registry API root `/v2` is served or, if it
+	// contains a `v2` segment which is not the last one, the registry API root itself (e.g. `https://artifactory.example.com/v2/docker-remote`).
 	Host string `json:"host"`
 	// Capabilities are the operations a host is capable of performing.
 	// This also represents the set of operations for which the mirror host may be trusted to perform.
@@ -37,6 +40,74 @@ type MirrorHost struct {
 	// Defaults to ["pull"].
 	// +optional
 	Capabilities []MirrorHostCapability `json:"capabilities"`
+	// CABundleSecretReferenceName is the name of the reference for the Secret containing the CA bundle used to verify
+	// the TLS certificate of the mirror host. The Secret must contain the CA bundle under the 'ca.crt' data key.
+	// The reference must be specified in the Shoot .spec.resources field.
+	// +optional
+	CABundleSecretReferenceName *string `json:"caBundleSecretReferenceName,omitempty"`
+	// ClientCertificateSecretReferenceName is the name of the reference for the Secret containing the client certificate
+	// used to authenticate against the mirror host. The Secret must contain the 'tls.crt' and 'tls.key' data keys.
+	// The reference must be specified in the Shoot .spec.resources field.
+	// +optional
+	ClientCertificateSecretReferenceName *string `json:"clientCertificateSecretReferenceName,omitempty"`
+	// SkipVerify disables the verification of the mirror host's TLS certificate.
+	// Defaults to false.
+	// +optional
+	SkipVerify *bool `json:"skipVerify,omitempty"`
+	// AuthSecretReferenceName is the name of the reference for the Secret containing the credentials or headers used to
+	// authenticate against the mirror host. The Secret may contain the 'username' and 'password' data keys (basic
+	// authentication), the 'token' data key (bearer token authentication) and 'header.<name>' data keys (custom headers).
+	// The reference must be specified in the Shoot .spec.resources field.
+	// +optional
+	AuthSecretReferenceName *string `json:"authSecretReferenceName,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MirrorStatus contains the effective containerd host configurations of the registry mirrors.
+type MirrorStatus struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Mirrors is a slice of the effective containerd host configurations of the registry mirrors.
+	Mirrors []MirrorConfigurationStatus `json:"mirrors"`
+}
+
+// MirrorConfigurationStatus represents the effective containerd host configuration of a registry mirror.
+type MirrorConfigurationStatus struct {
+	// Upstream is the upstream of the containerd host configuration.
+	// It is "_default" for a mirror with wildcard upstream.
+	Upstream string `json:"upstream"`
+	// Server is the upstream registry server which is used when all mirror hosts fail.
+	// It is nil for a mirror with wildcard upstream.
+	// +optional
+	Server *string `json:"server,omitempty"`
+	// Hosts are the effective mirror hosts.
+	Hosts []MirrorHostStatus `json:"hosts"`
+}
+
+// MirrorHostStatus represents an effective mirror host.
+type MirrorHostStatus struct {
+	// Host is the mirror host.
+	Host string `json:"host"`
+	// Capabilities are the operations the host is capable of performing.
+	Capabilities []MirrorHostCapability `json:"capabilities"`
+	// OverridePath denotes that the path of the host is the registry API root.
+	// +optional
+	OverridePath bool `json:"overridePath,omitempty"`
+	// SkipVerify disables the verification of the mirror host's TLS certificate.
+	// +optional
+	SkipVerify bool `json:"skipVerify,omitempty"`
+	// CABundleSecretName is the name of the Secret in the Shoot namespace containing the CA bundle of the host.
+	// +optional
+	CABundleSecretName *string `json:"caBundleSecretName,omitempty"`
+	// ClientCertificateSecretName is the name of the Secret in the Shoot namespace containing the client certificate
+	// of the host.
+	// +optional
+	ClientCertificateSecretName *string `json:"clientCertificateSecretName,omitempty"`
+	// AuthSecretName is the name of the Secret in the Shoot namespace containing the auth credentials and headers of
+	// the host.
+	// +optional
+	AuthSecretName *string `json:"authSecretName,omitempty"`
 }
 
 // MirrorHostCapability represents a mirror host capability.
@@ -43,6 +43,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*MirrorConfigurationStatus)(nil), (*mirror.MirrorConfigurationStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MirrorConfigurationStatus_To_mirror_MirrorConfigurationStatus(a.(*MirrorConfigurationStatus), b.(*mirror.MirrorConfigurationStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*mirror.MirrorConfigurationStatus)(nil), (*MirrorConfigurationStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_mirror_MirrorConfigurationStatus_To_v1alpha1_MirrorConfigurationStatus(a.(*mirror.MirrorConfigurationStatus), b.(*MirrorConfigurationStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*MirrorHost)(nil), (*mirror.MirrorHost)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_MirrorHost_To_mirror_MirrorHost(a.(*MirrorHost), b.(*mirror.MirrorHost), scope)
 	}); err != nil {
@@ -53,6 +63,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*MirrorHostStatus)(nil), (*mirror.MirrorHostStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MirrorHostStatus_To_mirror_MirrorHostStatus(a.(*MirrorHostStatus), b.(*mirror.MirrorHostStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*mirror.MirrorHostStatus)(nil), (*MirrorHostStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_mirror_MirrorHostStatus_To_v1alpha1_MirrorHostStatus(a.(*mirror.MirrorHostStatus), b.(*MirrorHostStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*MirrorStatus)(nil), (*mirror.MirrorStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MirrorStatus_To_mirror_MirrorStatus(a.(*MirrorStatus), b.(*mirror.MirrorStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*mirror.MirrorStatus)(nil), (*MirrorStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_mirror_MirrorStatus_To_v1alpha1_MirrorStatus(a.(*mirror.MirrorStatus), b.(*MirrorStatus), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -98,9 +128,37 @@ func Convert_mirror_MirrorConfiguration_To_v1alpha1_MirrorConfiguration(in *mirr
 	return autoConvert_mirror_MirrorConfiguration_To_v1alpha1_MirrorConfiguration(in, out, s)
 }
 
+func autoConvert_v1alpha1_MirrorConfigurationStatus_To_mirror_MirrorConfigurationStatus(in *MirrorConfigurationStatus, out *mirror.MirrorConfigurationStatus, s conversion.Scope) error {
+	out.Upstream = in.Upstream
+	out.Server = (*string)(unsafe.Pointer(in.Server))
+	out.Hosts = *(*[]mirror.MirrorHostStatus)(unsafe.Pointer(&in.Hosts))
+	return nil
+}
+
+// Convert_v1alpha1_MirrorConfigurationStatus_To_mirror_MirrorConfigurationStatus is an autogenerated conversion function.
+func Convert_v1alpha1_MirrorConfigurationStatus_To_mirror_MirrorConfigurationStatus(in *MirrorConfigurationStatus, out *mirror.MirrorConfigurationStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MirrorConfigurationStatus_To_mirror_MirrorConfigurationStatus(in, out, s)
+}
+
+func autoConvert_mirror_MirrorConfigurationStatus_To_v1alpha1_MirrorConfigurationStatus(in *mirror.MirrorConfigurationStatus, out *MirrorConfigurationStatus, s conversion.Scope) error {
+	out.Upstream = in.Upstream
+	out.Server = (*string)(unsafe.Pointer(in.Server))
+	out.Hosts = *(*[]MirrorHostStatus)(unsafe.Pointer(&in.Hosts))
+	return nil
+}
+
+// Convert_mirror_MirrorConfigurationStatus_To_v1alpha1_MirrorConfigurationStatus is an autogenerated conversion function.
+func Convert_mirror_MirrorConfigurationStatus_To_v1alpha1_MirrorConfigurationStatus(in *mirror.MirrorConfigurationStatus, out *MirrorConfigurationStatus, s conversion.Scope) error {
+	return autoConvert_mirror_MirrorConfigurationStatus_To_v1alpha1_MirrorConfigurationStatus(in, out, s)
+}
+
 func autoConvert_v1alpha1_MirrorHost_To_mirror_MirrorHost(in *MirrorHost, out *mirror.MirrorHost, s conversion.Scope) error {
 	out.Host = in.Host
 	out.Capabilities = *(*[]mirror.MirrorHostCapability)(unsafe.Pointer(&in.Capabilities))
+	out.CABundleSecretReferenceName = (*string)(unsafe.Pointer(in.CABundleSecretReferenceName))
+	out.ClientCertificateSecretReferenceName = (*string)(unsafe.Pointer(in.ClientCertificateSecretReferenceName))
+	out.SkipVerify = (*bool)(unsafe.Pointer(in.SkipVerify))
+	out.AuthSecretReferenceName = (*string)(unsafe.Pointer(in.AuthSecretReferenceName))
 	return nil
 }
 
@@ -112,6 +170,10 @@ func Convert_v1alpha1_MirrorHost_To_mirror_MirrorHost(in *MirrorHost, out *mirro
 func autoConvert_mirror_MirrorHost_To_v1alpha1_MirrorHost(in *mirror.MirrorHost, out *MirrorHost, s conversion.Scope) error {
 	out.Host = in.Host
 	out.Capabilities = *(*[]MirrorHostCapability)(unsafe.Pointer(&in.Capabilities))
+	out.CABundleSecretReferenceName = (*string)(unsafe.Pointer(in.CABundleSecretReferenceName))
+	out.ClientCertificateSecretReferenceName = (*string)(unsafe.Pointer(in.ClientCertificateSecretReferenceName))
+	out.SkipVerify = (*bool)(unsafe.Pointer(in.SkipVerify))
+	out.AuthSecretReferenceName = (*string)(unsafe.Pointer(in.AuthSecretReferenceName))
 	return nil
 }
 
@@ -119,3 +181,55 @@ func autoConvert_mirror_MirrorHost_To_v1alpha1_MirrorHost(in *mirror.MirrorHost,
 func Convert_mirror_MirrorHost_To_v1alpha1_MirrorHost(in *mirror.MirrorHost, out *MirrorHost, s conversion.Scope) error {
 	return autoConvert_mirror_MirrorHost_To_v1alpha1_MirrorHost(in, out, s)
 }
+
+func autoConvert_v1alpha1_MirrorHostStatus_To_mirror_MirrorHostStatus(in *MirrorHostStatus, out *mirror.MirrorHostStatus, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Capabilities = *(*[]mirror.MirrorHostCapability)(unsafe.Pointer(&in.Capabilities))
+	out.OverridePath = in.OverridePath
+	out.SkipVerify = in.SkipVerify
+	out.CABundleSecretName = (*string)(unsafe.Pointer(in.CABundleSecretName))
+	out.ClientCertificateSecretName = (*string)(unsafe.Pointer(in.ClientCertificateSecretName))
+	out.AuthSecretName = (*string)(unsafe.Pointer(in.AuthSecretName))
+	return nil
+}
+
+// Convert_v1alpha1_MirrorHostStatus_To_mirror_MirrorHostStatus is an autogenerated conversion function.
+func Convert_v1alpha1_MirrorHostStatus_To_mirror_MirrorHostStatus(in *MirrorHostStatus, out *mirror.MirrorHostStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MirrorHostStatus_To_mirror_MirrorHostStatus(in, out, s)
+}
+
+func autoConvert_mirror_MirrorHostStatus_To_v1alpha1_MirrorHostStatus(in *mirror.MirrorHostStatus, out *MirrorHostStatus, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Capabilities = *(*[]MirrorHostCapability)(unsafe.Pointer(&in.Capabilities))
+	out.OverridePath = in.OverridePath
+	out.SkipVerify = in.SkipVerify
+	out.CABundleSecretName = (*string)(unsafe.Pointer(in.CABundleSecretName))
+	out.ClientCertificateSecretName = (*string)(unsafe.Pointer(in.ClientCertificateSecretName))
+	out.AuthSecretName = (*string)(unsafe.Pointer(in.AuthSecretName))
+	return nil
+}
+
+// Convert_mirror_MirrorHostStatus_To_v1alpha1_MirrorHostStatus is an autogenerated conversion function.
+func Convert_mirror_MirrorHostStatus_To_v1alpha1_MirrorHostStatus(in *mirror.MirrorHostStatus, out *MirrorHostStatus, s conversion.Scope) error {
+	return autoConvert_mirror_MirrorHostStatus_To_v1alpha1_MirrorHostStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_MirrorStatus_To_mirror_MirrorStatus(in *MirrorStatus, out *mirror.MirrorStatus, s conversion.Scope) error {
+	out.Mirrors = *(*[]mirror.MirrorConfigurationStatus)(unsafe.Pointer(&in.Mirrors))
+	return nil
+}
+
+// Convert_v1alpha1_MirrorStatus_To_mirror_MirrorStatus is an autogenerated conversion function.
+func Convert_v1alpha1_MirrorStatus_To_mirror_MirrorStatus(in *MirrorStatus, out *mirror.MirrorStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MirrorStatus_To_mirror_MirrorStatus(in, out, s)
+}
+
+func autoConvert_mirror_MirrorStatus_To_v1alpha1_MirrorStatus(in *mirror.MirrorStatus, out *MirrorStatus, s conversion.Scope) error {
+	out.Mirrors = *(*[]MirrorConfigurationStatus)(unsafe.Pointer(&in.Mirrors))
+	return nil
+}
+
+// Convert_mirror_MirrorStatus_To_v1alpha1_MirrorStatus is an autogenerated conversion function.
+func Convert_mirror_MirrorStatus_To_v1alpha1_MirrorStatus(in *mirror.MirrorStatus, out *MirrorStatus, s conversion.Scope) error {
+	return autoConvert_mirror_MirrorStatus_To_v1alpha1_MirrorStatus(in, out, s)
+}
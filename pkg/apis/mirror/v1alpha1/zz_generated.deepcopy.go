@@ -67,6 +67,34 @@ func (in *MirrorConfiguration) DeepCopy() *MirrorConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorConfigurationStatus) DeepCopyInto(out *MirrorConfigurationStatus) {
+	*out = *in
+	if in.Server != nil {
+		in, out := &in.Server, &out.Server
+		*out = new(string)
+		**out = **in
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]MirrorHostStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorConfigurationStatus.
+func (in *MirrorConfigurationStatus) DeepCopy() *MirrorConfigurationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorConfigurationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MirrorHost) DeepCopyInto(out *MirrorHost) {
 	*out = *in
@@ -75,6 +103,26 @@ func (in *MirrorHost) DeepCopyInto(out *MirrorHost) {
 		*out = make([]MirrorHostCapability, len(*in))
 		copy(*out, *in)
 	}
+	if in.CABundleSecretReferenceName != nil {
+		in, out := &in.CABundleSecretReferenceName, &out.CABundleSecretReferenceName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ClientCertificateSecretReferenceName != nil {
+		in, out := &in.ClientCertificateSecretReferenceName, &out.ClientCertificateSecretReferenceName
+		*out = new(string)
+		**out = **in
+	}
+	if in.SkipVerify != nil {
+		in, out := &in.SkipVerify, &out.SkipVerify
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AuthSecretReferenceName != nil {
+		in, out := &in.AuthSecretReferenceName, &out.AuthSecretReferenceName
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -87,3 +135,71 @@ func (in *MirrorHost) DeepCopy() *MirrorHost {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorHostStatus) DeepCopyInto(out *MirrorHostStatus) {
+	*out = *in
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]MirrorHostCapability, len(*in))
+		copy(*out, *in)
+	}
+	if in.CABundleSecretName != nil {
+		in, out := &in.CABundleSecretName, &out.CABundleSecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ClientCertificateSecretName != nil {
+		in, out := &in.ClientCertificateSecretName, &out.ClientCertificateSecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AuthSecretName != nil {
+		in, out := &in.AuthSecretName, &out.AuthSecretName
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorHostStatus.
+func (in *MirrorHostStatus) DeepCopy() *MirrorHostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorHostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorStatus) DeepCopyInto(out *MirrorStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]MirrorConfigurationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorStatus.
+func (in *MirrorStatus) DeepCopy() *MirrorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MirrorStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
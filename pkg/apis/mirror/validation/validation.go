@@ -5,10 +5,18 @@
 package validation
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/helper"
 	registryvalidation "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/validation"
 )
 
@@ -26,32 +34,57 @@ func ValidateMirrorConfig(mirrorConfig *mirror.MirrorConfig, fldPath *field.Path
 	}
 
 	upstreams := sets.New[string]()
-	for i, mirror := range mirrorConfig.Mirrors {
+	for i, mirrorConfiguration := range mirrorConfig.Mirrors {
 		configFldPath := fldPath.Child("mirrors").Index(i)
 
-		allErrs = append(allErrs, validateMirrorConfiguration(mirror, configFldPath)...)
+		allErrs = append(allErrs, validateMirrorConfiguration(mirrorConfiguration, configFldPath)...)
 
-		if upstreams.Has(mirror.Upstream) {
-			allErrs = append(allErrs, field.Duplicate(configFldPath.Child("upstream"), mirror.Upstream))
+		if upstreams.Has(mirrorConfiguration.Upstream) && mirrorConfiguration.Upstream == mirror.WildcardUpstream {
+			allErrs = append(allErrs, field.Forbidden(configFldPath.Child("upstream"), fmt.Sprintf("at most one mirror with wildcard upstream %q can be configured", mirror.WildcardUpstream)))
+		} else if upstreams.Has(mirrorConfiguration.Upstream) {
+			allErrs = append(allErrs, field.Duplicate(configFldPath.Child("upstream"), mirrorConfiguration.Upstream))
 		} else {
-			upstreams.Insert(mirror.Upstream)
+			upstreams.Insert(mirrorConfiguration.Upstream)
 		}
 	}
 
 	return allErrs
 }
 
-func validateMirrorConfiguration(mirror mirror.MirrorConfiguration, fldPath *field.Path) field.ErrorList {
+// ValidateMirrorConfigUpdate validates the passed configuration update.
+func ValidateMirrorConfigUpdate(oldConfig, newConfig *mirror.MirrorConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, newMirror := range newConfig.Mirrors {
+		j := slices.IndexFunc(oldConfig.Mirrors, func(m mirror.MirrorConfiguration) bool { return m.Upstream == newMirror.Upstream })
+		if j == -1 {
+			continue
+		}
+
+		// The hosts.toml file of a mirror is written either by gardener-node-agent from the registry config or by the
+		// extension as a file. gardener-node-agent removes the hosts.toml file written by the respective other mechanism
+		// when a mirror switches between both, hence the switch is forbidden.
+		if helper.RequiresHostsFile(oldConfig.Mirrors[j]) != helper.RequiresHostsFile(newMirror) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("mirrors").Index(i).Child("hosts"), fmt.Sprintf("an existing mirror cannot start or stop using the 'clientCertificateSecretReferenceName', 'authSecretReferenceName' or 'skipVerify' field or a host with a registry API root path, remove the mirror for upstream '%s' first and add it again with a subsequent update", newMirror.Upstream)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateMirrorConfiguration(mirrorConfiguration mirror.MirrorConfiguration, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
-	allErrs = append(allErrs, registryvalidation.ValidateUpstream(fldPath.Child("upstream"), mirror.Upstream)...)
+	if mirrorConfiguration.Upstream != mirror.WildcardUpstream {
+		allErrs = append(allErrs, registryvalidation.ValidateUpstream(fldPath.Child("upstream"), mirrorConfiguration.Upstream)...)
+	}
 
-	if len(mirror.Hosts) == 0 {
+	if len(mirrorConfiguration.Hosts) == 0 {
 		allErrs = append(allErrs, field.Required(fldPath.Child("hosts"), "at least one host must be provided"))
 	}
 
 	hosts := sets.New[string]()
-	for i, host := range mirror.Hosts {
+	for i, host := range mirrorConfiguration.Hosts {
 		hostFldPath := fldPath.Child("hosts").Index(i)
 
 		allErrs = append(allErrs, registryvalidation.ValidateURL(hostFldPath.Child("host"), host.Host)...)
@@ -63,6 +96,39 @@ func validateMirrorConfiguration(mirror mirror.MirrorConfiguration, fldPath *fie
 		}
 
 		allErrs = append(allErrs, validateCapabilities(hostFldPath.Child("capabilities"), host.Capabilities)...)
+		allErrs = append(allErrs, validateTLSAndAuth(hostFldPath, host)...)
+	}
+
+	return allErrs
+}
+
+func validateTLSAndAuth(fldPath *field.Path, host mirror.MirrorHost) field.ErrorList {
+	var allErrs field.ErrorList
+
+	httpsHost := strings.HasPrefix(host.Host, "https://")
+
+	for _, ref := range []struct {
+		fldName string
+		name    *string
+	}{
+		{"caBundleSecretReferenceName", host.CABundleSecretReferenceName},
+		{"clientCertificateSecretReferenceName", host.ClientCertificateSecretReferenceName},
+		{"authSecretReferenceName", host.AuthSecretReferenceName},
+	} {
+		if ref.name == nil {
+			continue
+		}
+
+		if len(*ref.name) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child(ref.fldName), "secret reference name must not be empty"))
+		}
+		if !httpsHost {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child(ref.fldName), "can only be set for hosts with 'https://' scheme"))
+		}
+	}
+
+	if host.SkipVerify != nil && *host.SkipVerify && !httpsHost {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("skipVerify"), "can only be set for hosts with 'https://' scheme"))
 	}
 
 	return allErrs
@@ -88,3 +154,109 @@ func validateCapabilities(fldPath *field.Path, capabilities []mirror.MirrorHostC
 
 	return allErrs
 }
+
+// ValidateCABundleSecret checks whether the given Secret is a valid CA bundle Secret for a mirror host.
+func ValidateCABundleSecret(secret *corev1.Secret, fldPath *field.Path, secretReference string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	secretRef := fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+
+	allErrs = append(allErrs, validateSecretImmutable(secret, fldPath, secretReference)...)
+
+	caBundle, ok := secret.Data["ca.crt"]
+	if !ok {
+		allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("missing %q data entry in referenced secret %q", "ca.crt", secretRef)))
+	} else if !x509.NewCertPool().AppendCertsFromPEM(caBundle) {
+		allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q does not contain a valid PEM encoded certificate", "ca.crt", secretRef)))
+	}
+
+	return allErrs
+}
+
+// ValidateClientCertificateSecret checks whether the given Secret is a valid client certificate Secret for a mirror host.
+func ValidateClientCertificateSecret(secret *corev1.Secret, fldPath *field.Path, secretReference string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	secretRef := fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+
+	allErrs = append(allErrs, validateSecretImmutable(secret, fldPath, secretReference)...)
+
+	cert, certOK := secret.Data[corev1.TLSCertKey]
+	if !certOK {
+		allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("missing %q data entry in referenced secret %q", corev1.TLSCertKey, secretRef)))
+	}
+	key, keyOK := secret.Data[corev1.TLSPrivateKeyKey]
+	if !keyOK {
+		allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("missing %q data entry in referenced secret %q", corev1.TLSPrivateKeyKey, secretRef)))
+	}
+	if certOK && keyOK {
+		if _, err := tls.X509KeyPair(cert, key); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("referenced secret %q does not contain a valid certificate and key pair: %s", secretRef, err)))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateAuthSecret checks whether the given Secret is a valid auth Secret for a mirror host.
+func ValidateAuthSecret(secret *corev1.Secret, fldPath *field.Path, secretReference string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	secretRef := fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+
+	allErrs = append(allErrs, validateSecretImmutable(secret, fldPath, secretReference)...)
+
+	if len(secret.Data) == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("referenced secret %q should have at least one data entry", secretRef)))
+	}
+
+	_, hasUsername := secret.Data[mirror.AuthSecretUsernameKey]
+	_, hasPassword := secret.Data[mirror.AuthSecretPasswordKey]
+	_, hasToken := secret.Data[mirror.AuthSecretTokenKey]
+
+	if hasUsername != hasPassword {
+		allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entries %q and %q in referenced secret %q must be set together", mirror.AuthSecretUsernameKey, mirror.AuthSecretPasswordKey, secretRef)))
+	}
+	if hasToken && (hasUsername || hasPassword) {
+		allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q cannot be set together with %q and %q", mirror.AuthSecretTokenKey, secretRef, mirror.AuthSecretUsernameKey, mirror.AuthSecretPasswordKey)))
+	}
+
+	for _, key := range sets.List(sets.KeySet(secret.Data)) {
+		value := string(secret.Data[key])
+
+		switch key {
+		case mirror.AuthSecretUsernameKey, mirror.AuthSecretPasswordKey, mirror.AuthSecretTokenKey:
+		default:
+			name, ok := strings.CutPrefix(key, mirror.AuthSecretHeaderKeyPrefix)
+			if !ok {
+				allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q is not supported, supported data entries are %q, %q, %q and %q prefixed ones", key, secretRef, mirror.AuthSecretUsernameKey, mirror.AuthSecretPasswordKey, mirror.AuthSecretTokenKey, mirror.AuthSecretHeaderKeyPrefix)))
+				continue
+			}
+			if len(name) == 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q does not specify a header name", key, secretRef)))
+			}
+			if strings.EqualFold(name, "Authorization") && (hasUsername || hasToken) {
+				allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q cannot be set together with %q or %q", key, secretRef, mirror.AuthSecretUsernameKey, mirror.AuthSecretTokenKey)))
+			}
+		}
+
+		if len(value) == 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q must not be empty", key, secretRef)))
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q must not contain line breaks", key, secretRef)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateSecretImmutable(secret *corev1.Secret, fldPath *field.Path, secretReference string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if secret.Immutable == nil || !*secret.Immutable {
+		allErrs = append(allErrs, field.Invalid(fldPath, secretReference, fmt.Sprintf("referenced secret %q should be immutable", fmt.Sprintf("%s/%s", secret.Namespace, secret.Name))))
+	}
+
+	return allErrs
+}
@@ -5,10 +5,14 @@
 package validation_test
 
 import (
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 
 	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror"
 	. "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/validation"
@@ -224,6 +228,111 @@ var _ = Describe("Validation", func() {
 			))
 		})
 
+		It("should allow TLS options for https hosts", func() {
+			mirrorConfig.Mirrors[0].Hosts[0].CABundleSecretReferenceName = ptr.To("mirror-ca")
+			mirrorConfig.Mirrors[0].Hosts[0].ClientCertificateSecretReferenceName = ptr.To("mirror-client")
+			mirrorConfig.Mirrors[0].Hosts[0].SkipVerify = ptr.To(true)
+
+			Expect(ValidateMirrorConfig(mirrorConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny invalid TLS options", func() {
+			mirrorConfig.Mirrors[0].Hosts[0].CABundleSecretReferenceName = ptr.To("")
+			mirrorConfig.Mirrors[0].Hosts = append(mirrorConfig.Mirrors[0].Hosts, api.MirrorHost{
+				Host:                                 "http://mirror.example.com",
+				CABundleSecretReferenceName:          ptr.To("mirror-ca"),
+				ClientCertificateSecretReferenceName: ptr.To("mirror-client"),
+				SkipVerify:                           ptr.To(true),
+			})
+
+			Expect(ValidateMirrorConfig(mirrorConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.mirrors[0].hosts[0].caBundleSecretReferenceName"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.mirrors[0].hosts[1].caBundleSecretReferenceName"),
+					"Detail": Equal("can only be set for hosts with 'https://' scheme"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.mirrors[0].hosts[1].clientCertificateSecretReferenceName"),
+					"Detail": Equal("can only be set for hosts with 'https://' scheme"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.mirrors[0].hosts[1].skipVerify"),
+					"Detail": Equal("can only be set for hosts with 'https://' scheme"),
+				})),
+			))
+		})
+
+		It("should allow a mirror with wildcard upstream", func() {
+			mirrorConfig.Mirrors = append(mirrorConfig.Mirrors, api.MirrorConfiguration{
+				Upstream: "*",
+				Hosts:    []api.MirrorHost{{Host: "https://mirror.example.com"}},
+			})
+
+			Expect(ValidateMirrorConfig(mirrorConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny more than one mirror with wildcard upstream", func() {
+			mirrorConfig.Mirrors = append(mirrorConfig.Mirrors,
+				api.MirrorConfiguration{
+					Upstream: "*",
+					Hosts:    []api.MirrorHost{{Host: "https://mirror.example.com"}},
+				},
+				api.MirrorConfiguration{
+					Upstream: "*",
+					Hosts:    []api.MirrorHost{{Host: "https://mirror2.example.com"}},
+				},
+			)
+
+			Expect(ValidateMirrorConfig(mirrorConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.mirrors[2].upstream"),
+					"Detail": Equal(`at most one mirror with wildcard upstream "*" can be configured`),
+				})),
+			))
+		})
+
+		It("should allow mirror hosts with path", func() {
+			mirrorConfig.Mirrors[0].Hosts = append(mirrorConfig.Mirrors[0].Hosts,
+				api.MirrorHost{Host: "https://nexus.example.com/repository/docker-proxy"},
+				api.MirrorHost{Host: "https://artifactory.example.com/v2/docker-remote"},
+			)
+
+			Expect(ValidateMirrorConfig(mirrorConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow auth options for https hosts", func() {
+			mirrorConfig.Mirrors[0].Hosts[0].AuthSecretReferenceName = ptr.To("mirror-auth")
+
+			Expect(ValidateMirrorConfig(mirrorConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny invalid auth options", func() {
+			mirrorConfig.Mirrors[0].Hosts[0].AuthSecretReferenceName = ptr.To("")
+			mirrorConfig.Mirrors[0].Hosts = append(mirrorConfig.Mirrors[0].Hosts, api.MirrorHost{
+				Host:                    "http://mirror.example.com",
+				AuthSecretReferenceName: ptr.To("mirror-auth"),
+			})
+
+			Expect(ValidateMirrorConfig(mirrorConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.mirrors[0].hosts[0].authSecretReferenceName"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.mirrors[0].hosts[1].authSecretReferenceName"),
+					"Detail": Equal("can only be set for hosts with 'https://' scheme"),
+				})),
+			))
+		})
+
 		It("should deny duplicate mirror upstreams", func() {
 			mirrorConfig.Mirrors = append(mirrorConfig.Mirrors, *mirrorConfig.Mirrors[0].DeepCopy())
 
@@ -235,4 +344,253 @@ var _ = Describe("Validation", func() {
 			))
 		})
 	})
+
+	Describe("#ValidateMirrorConfigUpdate", func() {
+		var newMirrorConfig *api.MirrorConfig
+
+		BeforeEach(func() {
+			newMirrorConfig = mirrorConfig.DeepCopy()
+		})
+
+		It("should allow changing the hosts of an existing mirror", func() {
+			newMirrorConfig.Mirrors[0].Hosts = append(newMirrorConfig.Mirrors[0].Hosts, api.MirrorHost{
+				Host:                        "https://mirror.example.com",
+				CABundleSecretReferenceName: ptr.To("mirror-ca"),
+			})
+
+			Expect(ValidateMirrorConfigUpdate(mirrorConfig, newMirrorConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow adding a mirror which requires a hosts.toml file", func() {
+			newMirrorConfig.Mirrors = append(newMirrorConfig.Mirrors, api.MirrorConfiguration{
+				Upstream: "ghcr.io",
+				Hosts:    []api.MirrorHost{{Host: "https://mirror.example.com", SkipVerify: ptr.To(true)}},
+			})
+
+			Expect(ValidateMirrorConfigUpdate(mirrorConfig, newMirrorConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow changing the host options of a mirror which requires a hosts.toml file", func() {
+			mirrorConfig.Mirrors[0].Hosts[0].SkipVerify = ptr.To(true)
+			newMirrorConfig.Mirrors[0].Hosts[0].AuthSecretReferenceName = ptr.To("mirror-auth")
+
+			Expect(ValidateMirrorConfigUpdate(mirrorConfig, newMirrorConfig, fldPath)).To(BeEmpty())
+		})
+
+		DescribeTable("should deny switching an existing mirror between the registry config and the hosts.toml file",
+			func(mutate func(host *api.MirrorHost)) {
+				mutate(&newMirrorConfig.Mirrors[0].Hosts[0])
+				matcher := ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.mirrors[0].hosts"),
+					"Detail": Equal("an existing mirror cannot start or stop using the 'clientCertificateSecretReferenceName', 'authSecretReferenceName' or 'skipVerify' field or a host with a registry API root path, remove the mirror for upstream 'docker.io' first and add it again with a subsequent update"),
+				})))
+
+				Expect(ValidateMirrorConfigUpdate(mirrorConfig, newMirrorConfig, fldPath)).To(matcher)
+				Expect(ValidateMirrorConfigUpdate(newMirrorConfig, mirrorConfig, fldPath)).To(matcher)
+			},
+
+			Entry("client certificate", func(host *api.MirrorHost) {
+				host.ClientCertificateSecretReferenceName = ptr.To("mirror-client-certificate")
+			}),
+			Entry("auth", func(host *api.MirrorHost) { host.AuthSecretReferenceName = ptr.To("mirror-auth") }),
+			Entry("skip verify", func(host *api.MirrorHost) { host.SkipVerify = ptr.To(true) }),
+			Entry("host with registry API root path", func(host *api.MirrorHost) { host.Host = "https://artifactory.example.com/v2/docker-remote" }),
+		)
+	})
+
+	Context("Secrets", func() {
+		var (
+			fldPath     = field.NewPath("providerConfig", "mirrors").Index(0).Child("hosts").Index(0)
+			certificate *secretsutils.Certificate
+			secret      *corev1.Secret
+		)
+
+		BeforeEach(func() {
+			var err error
+			certificate, err = (&secretsutils.CertificateSecretConfig{
+				Name:       "mirror",
+				CommonName: "mirror",
+				CertType:   secretsutils.CACert,
+			}).GenerateCertificate()
+			Expect(err).NotTo(HaveOccurred())
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mirror-secret",
+					Namespace: "garden-foo",
+				},
+				Immutable: ptr.To(true),
+			}
+		})
+
+		Describe("#ValidateCABundleSecret", func() {
+			It("should allow a valid CA bundle secret", func() {
+				secret.Data = map[string][]byte{"ca.crt": certificate.CertificatePEM}
+
+				Expect(ValidateCABundleSecret(secret, fldPath.Child("caBundleSecretReferenceName"), "mirror-ca")).To(BeEmpty())
+			})
+
+			It("should deny a mutable secret without a valid CA bundle", func() {
+				secret.Immutable = nil
+				secret.Data = map[string][]byte{"ca.crt": []byte("foo")}
+
+				Expect(ValidateCABundleSecret(secret, fldPath.Child("caBundleSecretReferenceName"), "mirror-ca")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.mirrors[0].hosts[0].caBundleSecretReferenceName"),
+						"Detail": Equal(`referenced secret "garden-foo/mirror-secret" should be immutable`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.mirrors[0].hosts[0].caBundleSecretReferenceName"),
+						"Detail": Equal(`data entry "ca.crt" in referenced secret "garden-foo/mirror-secret" does not contain a valid PEM encoded certificate`),
+					})),
+				))
+			})
+
+			It("should deny a secret without CA bundle", func() {
+				Expect(ValidateCABundleSecret(secret, fldPath.Child("caBundleSecretReferenceName"), "mirror-ca")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": Equal(`missing "ca.crt" data entry in referenced secret "garden-foo/mirror-secret"`),
+					})),
+				))
+			})
+		})
+
+		Describe("#ValidateClientCertificateSecret", func() {
+			It("should allow a valid client certificate secret", func() {
+				secret.Data = map[string][]byte{
+					"tls.crt": certificate.CertificatePEM,
+					"tls.key": certificate.PrivateKeyPEM,
+				}
+
+				Expect(ValidateClientCertificateSecret(secret, fldPath.Child("clientCertificateSecretReferenceName"), "mirror-client")).To(BeEmpty())
+			})
+
+			It("should deny a secret without certificate and key", func() {
+				Expect(ValidateClientCertificateSecret(secret, fldPath.Child("clientCertificateSecretReferenceName"), "mirror-client")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.mirrors[0].hosts[0].clientCertificateSecretReferenceName"),
+						"Detail": Equal(`missing "tls.crt" data entry in referenced secret "garden-foo/mirror-secret"`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.mirrors[0].hosts[0].clientCertificateSecretReferenceName"),
+						"Detail": Equal(`missing "tls.key" data entry in referenced secret "garden-foo/mirror-secret"`),
+					})),
+				))
+			})
+
+			It("should deny a secret with an invalid key pair", func() {
+				secret.Data = map[string][]byte{
+					"tls.crt": certificate.CertificatePEM,
+					"tls.key": []byte("foo"),
+				}
+
+				Expect(ValidateClientCertificateSecret(secret, fldPath.Child("clientCertificateSecretReferenceName"), "mirror-client")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": ContainSubstring(`referenced secret "garden-foo/mirror-secret" does not contain a valid certificate and key pair`),
+					})),
+				))
+			})
+		})
+
+		Describe("#ValidateAuthSecret", func() {
+			authFldPath := fldPath.Child("authSecretReferenceName")
+
+			It("should allow a secret with basic authentication credentials and custom headers", func() {
+				secret.Data = map[string][]byte{
+					"username":     []byte("foo"),
+					"password":     []byte("bar"),
+					"header.X-Foo": []byte("baz"),
+				}
+
+				Expect(ValidateAuthSecret(secret, authFldPath, "mirror-auth")).To(BeEmpty())
+			})
+
+			It("should allow a secret with a bearer token", func() {
+				secret.Data = map[string][]byte{"token": []byte("foo")}
+
+				Expect(ValidateAuthSecret(secret, authFldPath, "mirror-auth")).To(BeEmpty())
+			})
+
+			It("should allow a secret with a custom authorization header", func() {
+				secret.Data = map[string][]byte{"header.Authorization": []byte("Custom foo")}
+
+				Expect(ValidateAuthSecret(secret, authFldPath, "mirror-auth")).To(BeEmpty())
+			})
+
+			It("should deny a mutable secret without data entries", func() {
+				secret.Immutable = nil
+
+				Expect(ValidateAuthSecret(secret, authFldPath, "mirror-auth")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.mirrors[0].hosts[0].authSecretReferenceName"),
+						"Detail": Equal(`referenced secret "garden-foo/mirror-secret" should be immutable`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.mirrors[0].hosts[0].authSecretReferenceName"),
+						"Detail": Equal(`referenced secret "garden-foo/mirror-secret" should have at least one data entry`),
+					})),
+				))
+			})
+
+			It("should deny a secret with conflicting credentials", func() {
+				secret.Data = map[string][]byte{
+					"username":             []byte("foo"),
+					"token":                []byte("bar"),
+					"header.Authorization": []byte("Custom baz"),
+				}
+
+				Expect(ValidateAuthSecret(secret, authFldPath, "mirror-auth")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": Equal(`data entries "username" and "password" in referenced secret "garden-foo/mirror-secret" must be set together`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": Equal(`data entry "token" in referenced secret "garden-foo/mirror-secret" cannot be set together with "username" and "password"`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": Equal(`data entry "header.Authorization" in referenced secret "garden-foo/mirror-secret" cannot be set together with "username" or "token"`),
+					})),
+				))
+			})
+
+			It("should deny a secret with invalid data entries", func() {
+				secret.Data = map[string][]byte{
+					"foo":          []byte("bar"),
+					"header.":      []byte("bar"),
+					"header.X-Foo": []byte(""),
+					"token":        []byte("foo\nbar"),
+				}
+
+				Expect(ValidateAuthSecret(secret, authFldPath, "mirror-auth")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": Equal(`data entry "foo" in referenced secret "garden-foo/mirror-secret" is not supported, supported data entries are "username", "password", "token" and "header." prefixed ones`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": Equal(`data entry "header." in referenced secret "garden-foo/mirror-secret" does not specify a header name`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": Equal(`data entry "header.X-Foo" in referenced secret "garden-foo/mirror-secret" must not be empty`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": Equal(`data entry "token" in referenced secret "garden-foo/mirror-secret" must not contain line breaks`),
+					})),
+				))
+			})
+		})
+	})
 })
@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
 )
 
 // GarbageCollectionEnabled returns whether the garbage collection is enabled (ttl > 0) for the given cache.
@@ -64,3 +65,65 @@ func TLSEnabled(cache *registry.RegistryCache) bool {
 
 	return cache.HTTP.TLS
 }
+
+// TLSCertificateProvided returns whether the registry cache serves a certificate provided via a Secret reference
+// instead of a certificate issued by the CA of the extension.
+func TLSCertificateProvided(cache *registry.RegistryCache) bool {
+	return TLSEnabled(cache) && cache.HTTP != nil && cache.HTTP.TLSSecretReferenceName != nil
+}
+
+// Port returns the port on which the registry cache serves requests.
+func Port(cache *registry.RegistryCache) int32 {
+	if cache.HTTP == nil || cache.HTTP.Port == nil {
+		return constants.RegistryCachePort
+	}
+
+	return *cache.HTTP.Port
+}
+
+// DebugPort returns the port of the debug endpoint of the registry cache.
+func DebugPort(cache *registry.RegistryCache) int32 {
+	if cache.HTTP == nil || cache.HTTP.DebugPort == nil {
+		return constants.RegistryCacheDebugPort
+	}
+
+	return *cache.HTTP.DebugPort
+}
+
+// HostnameEndpointsEnabled returns whether containerd uses the hostnames of the registry cache Services instead of
+// their cluster IPs.
+func HostnameEndpointsEnabled(config *registry.RegistryConfig) bool {
+	return config.EndpointType != nil && *config.EndpointType == registry.EndpointTypeHostname
+}
+
+// TracingProtocol returns the transport protocol of the OTLP endpoint of the given tracing settings.
+func TracingProtocol(tracing *registry.Tracing) registry.TracingProtocol {
+	if tracing.Protocol == nil {
+		return registry.TracingProtocolHTTPProtobuf
+	}
+
+	return *tracing.Protocol
+}
+
+// TracingSamplingRatio returns the ratio of the sampled traces of the given tracing settings.
+func TracingSamplingRatio(tracing *registry.Tracing) string {
+	if tracing.SamplingRatio == nil {
+		return "1"
+	}
+
+	return *tracing.SamplingRatio
+}
+
+// BlobDescriptorCacheEnabled returns whether the blob descriptors of the given cache are cached in a Redis instance.
+func BlobDescriptorCacheEnabled(cache *registry.RegistryCache) bool {
+	return cache.BlobDescriptorCache != nil && cache.BlobDescriptorCache.Type == registry.BlobDescriptorCacheTypeRedis
+}
+
+// BlobDescriptorCacheMaxMemory returns the maximum memory used by the blob descriptor cache of the given cache.
+func BlobDescriptorCacheMaxMemory(cache *registry.RegistryCache) resource.Quantity {
+	if cache.BlobDescriptorCache == nil || cache.BlobDescriptorCache.MaxMemory == nil {
+		return registry.DefaultBlobDescriptorCacheMaxMemory
+	}
+
+	return *cache.BlobDescriptorCache.MaxMemory
+}
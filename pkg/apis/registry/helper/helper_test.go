@@ -114,4 +114,74 @@ var _ = Describe("Helpers", func() {
 		Entry("http.tls is false", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: false}}, false),
 		Entry("http.tls is true", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: true}}, true),
 	)
+
+	DescribeTable("#TLSCertificateProvided",
+		func(cache *registry.RegistryCache, expected bool) {
+			Expect(helper.TLSCertificateProvided(cache)).To(Equal(expected))
+		},
+		Entry("http is nil", &registry.RegistryCache{HTTP: nil}, false),
+		Entry("http.tlsSecretReferenceName is nil", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: true}}, false),
+		Entry("http.tls is false", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: false, TLSSecretReferenceName: ptr.To("foo")}}, false),
+		Entry("http.tlsSecretReferenceName is set", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: true, TLSSecretReferenceName: ptr.To("foo")}}, true),
+	)
+
+	DescribeTable("#HostnameEndpointsEnabled",
+		func(config *registry.RegistryConfig, expected bool) {
+			Expect(helper.HostnameEndpointsEnabled(config)).To(Equal(expected))
+		},
+		Entry("endpointType is nil", &registry.RegistryConfig{}, false),
+		Entry("endpointType is ClusterIP", &registry.RegistryConfig{EndpointType: ptr.To(registry.EndpointTypeClusterIP)}, false),
+		Entry("endpointType is Hostname", &registry.RegistryConfig{EndpointType: ptr.To(registry.EndpointTypeHostname)}, true),
+	)
+
+	DescribeTable("#Port",
+		func(cache *registry.RegistryCache, expected int32) {
+			Expect(helper.Port(cache)).To(Equal(expected))
+		},
+		Entry("http is nil", &registry.RegistryCache{HTTP: nil}, int32(5000)),
+		Entry("http.port is nil", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: true}}, int32(5000)),
+		Entry("http.port is set", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: true, Port: ptr.To[int32](8443)}}, int32(8443)),
+	)
+
+	DescribeTable("#DebugPort",
+		func(cache *registry.RegistryCache, expected int32) {
+			Expect(helper.DebugPort(cache)).To(Equal(expected))
+		},
+		Entry("http is nil", &registry.RegistryCache{HTTP: nil}, int32(5001)),
+		Entry("http.debugPort is nil", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: true}}, int32(5001)),
+		Entry("http.debugPort is set", &registry.RegistryCache{HTTP: &registry.HTTP{TLS: true, DebugPort: ptr.To[int32](9090)}}, int32(9090)),
+	)
+
+	DescribeTable("#BlobDescriptorCacheEnabled",
+		func(cache *registry.RegistryCache, expected bool) {
+			Expect(helper.BlobDescriptorCacheEnabled(cache)).To(Equal(expected))
+		},
+		Entry("blobDescriptorCache is nil", &registry.RegistryCache{BlobDescriptorCache: nil}, false),
+		Entry("blobDescriptorCache.type is Redis", &registry.RegistryCache{BlobDescriptorCache: &registry.BlobDescriptorCache{Type: registry.BlobDescriptorCacheTypeRedis}}, true),
+	)
+
+	DescribeTable("#BlobDescriptorCacheMaxMemory",
+		func(cache *registry.RegistryCache, expected resource.Quantity) {
+			Expect(helper.BlobDescriptorCacheMaxMemory(cache)).To(Equal(expected))
+		},
+		Entry("blobDescriptorCache is nil", &registry.RegistryCache{BlobDescriptorCache: nil}, resource.MustParse("64Mi")),
+		Entry("blobDescriptorCache.maxMemory is nil", &registry.RegistryCache{BlobDescriptorCache: &registry.BlobDescriptorCache{Type: registry.BlobDescriptorCacheTypeRedis}}, resource.MustParse("64Mi")),
+		Entry("blobDescriptorCache.maxMemory is set", &registry.RegistryCache{BlobDescriptorCache: &registry.BlobDescriptorCache{Type: registry.BlobDescriptorCacheTypeRedis, MaxMemory: ptr.To(resource.MustParse("256Mi"))}}, resource.MustParse("256Mi")),
+	)
+
+	DescribeTable("#TracingProtocol",
+		func(tracing *registry.Tracing, expected registry.TracingProtocol) {
+			Expect(helper.TracingProtocol(tracing)).To(Equal(expected))
+		},
+		Entry("protocol is nil", &registry.Tracing{}, registry.TracingProtocolHTTPProtobuf),
+		Entry("protocol is set", &registry.Tracing{Protocol: ptr.To(registry.TracingProtocolGRPC)}, registry.TracingProtocolGRPC),
+	)
+
+	DescribeTable("#TracingSamplingRatio",
+		func(tracing *registry.Tracing, expected string) {
+			Expect(helper.TracingSamplingRatio(tracing)).To(Equal(expected))
+		},
+		Entry("sampling ratio is nil", &registry.Tracing{}, "1"),
+		Entry("sampling ratio is set", &registry.Tracing{SamplingRatio: ptr.To("0.25")}, "0.25"),
+	)
 })
@@ -25,6 +25,10 @@ func SetDefaults_RegistryCache(cache *RegistryCache) {
 			TLS: true,
 		}
 	}
+
+	if len(cache.Capabilities) == 0 {
+		cache.Capabilities = []RegistryCacheCapability{RegistryCacheCapabilityPull, RegistryCacheCapabilityResolve}
+	}
 }
 
 // SetDefaults_Volume sets the defaults for a Volume.
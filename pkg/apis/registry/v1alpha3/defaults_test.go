@@ -43,6 +43,7 @@ var _ = Describe("Defaults", func() {
 						HTTP: &v1alpha3.HTTP{
 							TLS: true,
 						},
+						Capabilities: []v1alpha3.RegistryCacheCapability{v1alpha3.RegistryCacheCapabilityPull, v1alpha3.RegistryCacheCapabilityResolve},
 					},
 				},
 			}
@@ -63,6 +64,7 @@ var _ = Describe("Defaults", func() {
 						HTTP: &v1alpha3.HTTP{
 							TLS: false,
 						},
+						Capabilities: []v1alpha3.RegistryCacheCapability{v1alpha3.RegistryCacheCapabilityPull},
 					},
 				},
 			}
@@ -9,6 +9,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -19,15 +20,33 @@ type RegistryConfig struct {
 
 	// Caches is a slice of registry caches to deploy.
 	Caches []RegistryCache `json:"caches"`
+	// EndpointType is the type of the registry cache endpoints in the containerd configuration of the Nodes.
+	// Supported values are 'ClusterIP' and 'Hostname'. Defaults to 'ClusterIP'.
+	// With 'Hostname', containerd uses the stable hostname of the registry cache Service
+	// (e.g. `registry-docker-io.kube-system.svc`) which is resolved via a hosts file entry on the Nodes.
+	// +optional
+	EndpointType *EndpointType `json:"endpointType,omitempty"`
 }
 
+// EndpointType represents a type of the registry cache endpoints in the containerd configuration.
+type EndpointType string
+
+const (
+	// EndpointTypeClusterIP uses the cluster IP of the registry cache Service.
+	EndpointTypeClusterIP EndpointType = "ClusterIP"
+	// EndpointTypeHostname uses the hostname of the registry cache Service.
+	EndpointTypeHostname EndpointType = "Hostname"
+)
+
 // RegistryCache represents a registry cache to deploy.
 type RegistryCache struct {
 	// Upstream is the remote registry host to cache.
 	// The value must be a valid DNS subdomain (RFC 1123) and optionally a port.
 	Upstream string `json:"upstream"`
-	// RemoteURL is the remote registry URL. The format must be `<scheme><host>[:<port>]` where
-	// `<scheme>` is `https://` or `http://` and `<host>[:<port>]` corresponds to the Upstream
+	// RemoteURL is the remote registry URL. The format must be `<scheme><host>[:<port>][<path>]` where
+	// `<scheme>` is `https://` or `http://` and `<host>[:<port>]` corresponds to the Upstream.
+	// The optional `<path>` is the path prefix under which the registry API root `/v2` is served,
+	// a trailing `/v2` segment is allowed.
 	//
 	// If defined, the value is set as `proxy.remoteurl` in the registry [configuration](https://github.com/distribution/distribution/blob/main/docs/content/recipes/mirror.md#configure-the-cache)
 	// and in containerd configuration as `server` field in [hosts.toml](https://github.com/containerd/containerd/blob/main/docs/hosts.md#server-field) file.
@@ -43,14 +62,67 @@ type RegistryCache struct {
 	// SecretReferenceName is the name of the reference for the Secret containing the upstream registry credentials.
 	// +optional
 	SecretReferenceName *string `json:"secretReferenceName,omitempty"`
+	// UpstreamRateLimits contains settings for limiting the requests of the registry cache to the upstream.
+	// By default, the requests to the upstream are not limited.
+	// +optional
+	UpstreamRateLimits *UpstreamRateLimits `json:"upstreamRateLimits,omitempty"`
 	// Proxy contains settings for a proxy used in the registry cache.
 	// +optional
 	Proxy *Proxy `json:"proxy,omitempty"`
 	// HTTP contains settings for the HTTP server that hosts the registry cache.
 	// +optional
 	HTTP *HTTP `json:"http,omitempty"`
+	// Strict disables the fallback of containerd to the upstream registry when the registry cache is not available.
+	// When enabled, all image pulls from the upstream go through the registry cache and fail when the registry cache
+	// is not available.
+	// Defaults to false.
+	// This field is immutable.
+	// +optional
+	Strict *bool `json:"strict,omitempty"`
+	// Capabilities are the operations the registry cache is capable of performing for containerd.
+	// A registry cache with capability 'pull' only is used for fetching content by digest, while tags are resolved
+	// against the upstream registry.
+	// Defaults to ['pull', 'resolve'].
+	// +optional
+	Capabilities []RegistryCacheCapability `json:"capabilities,omitempty"`
+	// Exposure contains settings for exposing the registry cache outside of the Shoot cluster.
+	// By default, the registry cache is only reachable within the Shoot cluster.
+	// +optional
+	Exposure *Exposure `json:"exposure,omitempty"`
+	// Log contains settings for the logging of the registry cache.
+	// +optional
+	Log *Log `json:"log,omitempty"`
+	// Tracing contains settings for exporting traces of the registry cache via the OpenTelemetry Protocol (OTLP).
+	// By default, traces are not exported.
+	// +optional
+	Tracing *Tracing `json:"tracing,omitempty"`
+	// Notifications contains settings for sending notifications about the events of the registry cache, e.g. image
+	// pulls, to HTTP endpoints. By default, no notifications are sent.
+	// +optional
+	Notifications *Notifications `json:"notifications,omitempty"`
+	// BlobDescriptorCache contains settings for caching the descriptors of the blobs in the registry cache.
+	// By default, the descriptors are not cached and the registry cache looks them up in the filesystem for each request.
+	// +optional
+	BlobDescriptorCache *BlobDescriptorCache `json:"blobDescriptorCache,omitempty"`
+	// ConfigOverrides is a patch which is merged into the generated configuration file (config.yml) of the registry cache.
+	// It allows setting options of the registry (https://distribution.github.io/distribution/about/configuration/)
+	// which are not exposed by this API. Objects are merged recursively, a null value removes an option and any other
+	// value replaces the generated one. Options managed by the extension, e.g. `http.addr` or `proxy.remoteurl`,
+	// cannot be overridden. Invalid options prevent the registry cache from starting, use with care.
+	// +optional
+	ConfigOverrides *runtime.RawExtension `json:"configOverrides,omitempty"`
 }
 
+// RegistryCacheCapability represents a registry cache capability.
+type RegistryCacheCapability string
+
+const (
+	// RegistryCacheCapabilityPull represents the capability to fetch manifests and blobs by digest.
+	RegistryCacheCapabilityPull RegistryCacheCapability = "pull"
+	// RegistryCacheCapabilityResolve represents the capability to fetch manifests by name.
+	RegistryCacheCapabilityResolve RegistryCacheCapability = "resolve"
+)
+
 // Volume contains settings for the registry cache volume.
 type Volume struct {
 	// Size is the size of the registry cache volume.
@@ -72,6 +144,20 @@ type GarbageCollection struct {
 	TTL metav1.Duration `json:"ttl"`
 }
 
+// UpstreamRateLimits contains settings for limiting the requests of the registry cache to the upstream.
+// The limits are enforced by a sidecar container of the registry cache which proxies the requests to the upstream.
+type UpstreamRateLimits struct {
+	// RequestsPerSecond is the maximum number of requests per second to the upstream.
+	// +optional
+	RequestsPerSecond *int32 `json:"requestsPerSecond,omitempty"`
+	// MaxConcurrentRequests is the maximum number of concurrent requests to the upstream.
+	// +optional
+	MaxConcurrentRequests *int32 `json:"maxConcurrentRequests,omitempty"`
+	// MaxBandwidth is the maximum bandwidth in bytes per second of the responses from the upstream, e.g. '50Mi'.
+	// +optional
+	MaxBandwidth *resource.Quantity `json:"maxBandwidth,omitempty"`
+}
+
 // Proxy contains settings for a proxy used in the registry cache.
 type Proxy struct {
 	// HTTPProxy field represents the proxy server for HTTP connections which is used by the registry cache.
@@ -87,8 +173,191 @@ type HTTP struct {
 	// TLS indicates whether TLS is enabled for the HTTP server of the registry cache.
 	// Defaults to true.
 	TLS bool `json:"tls"`
+	// TLSSecretReferenceName is the name of the reference for the Secret containing the server certificate (`tls.crt`)
+	// and key (`tls.key`) of the registry cache. The certificate replaces the one issued by the CA of the extension.
+	// It can only be set when TLS is enabled.
+	// +optional
+	TLSSecretReferenceName *string `json:"tlsSecretReferenceName,omitempty"`
+	// CABundleSecretReferenceName is the name of the reference for the Secret containing the CA bundle (`ca.crt`)
+	// used by containerd on the Nodes to verify the server certificate of the registry cache.
+	// If not set, the server certificate is verified with the system CAs of the Nodes.
+	// It can only be set together with TLSSecretReferenceName.
+	// +optional
+	CABundleSecretReferenceName *string `json:"caBundleSecretReferenceName,omitempty"`
+	// Port is the port on which the registry cache serves requests.
+	// Defaults to 5000.
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+	// DebugPort is the port of the debug endpoint of the registry cache serving the health checks and the metrics.
+	// Defaults to 5001.
+	// +optional
+	DebugPort *int32 `json:"debugPort,omitempty"`
+}
+
+// Log contains settings for the logging of the registry cache.
+type Log struct {
+	// Level is the log level of the registry cache.
+	// Supported values are 'error', 'warn', 'info' and 'debug'. Defaults to 'info'.
+	// +optional
+	Level *LogLevel `json:"level,omitempty"`
+	// Format is the log format of the registry cache.
+	// Supported values are 'text', 'json' and 'logstash'. Defaults to 'text'.
+	// +optional
+	Format *LogFormat `json:"format,omitempty"`
+	// AccessLog indicates whether the registry cache logs an entry for each request.
+	// Defaults to true.
+	// +optional
+	AccessLog *bool `json:"accessLog,omitempty"`
 }
 
+// LogLevel represents a log level of the registry cache.
+type LogLevel string
+
+const (
+	// LogLevelError logs errors only.
+	LogLevelError LogLevel = "error"
+	// LogLevelWarn logs warnings and errors.
+	LogLevelWarn LogLevel = "warn"
+	// LogLevelInfo logs informational messages, warnings and errors.
+	LogLevelInfo LogLevel = "info"
+	// LogLevelDebug logs all messages including debug messages.
+	LogLevelDebug LogLevel = "debug"
+)
+
+// LogFormat represents a log format of the registry cache.
+type LogFormat string
+
+const (
+	// LogFormatText is the plain text log format.
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON is the JSON log format.
+	LogFormatJSON LogFormat = "json"
+	// LogFormatLogstash is the Logstash log format.
+	LogFormatLogstash LogFormat = "logstash"
+)
+
+// Tracing contains settings for exporting traces of the registry cache via the OpenTelemetry Protocol (OTLP).
+type Tracing struct {
+	// Endpoint is the OTLP endpoint to which the traces are exported.
+	// The format must be `<scheme><host>[:<port>][<path>]` where `<scheme>` is `https://` or `http://`.
+	Endpoint string `json:"endpoint"`
+	// Protocol is the transport protocol of the OTLP endpoint.
+	// Supported values are 'grpc' and 'http/protobuf'. Defaults to 'http/protobuf'.
+	// +optional
+	Protocol *TracingProtocol `json:"protocol,omitempty"`
+	// SamplingRatio is the ratio of the traces which are sampled, in range [0, 1].
+	// Defaults to '1', i.e. all traces are sampled.
+	// +optional
+	SamplingRatio *string `json:"samplingRatio,omitempty"`
+	// HeadersSecretReferenceName is the name of the reference for the Secret containing the headers sent with each
+	// export request, e.g. for authentication. Each data entry of the Secret is a header name and its value.
+	// +optional
+	HeadersSecretReferenceName *string `json:"headersSecretReferenceName,omitempty"`
+}
+
+// TracingProtocol represents a transport protocol of the OTLP endpoint.
+type TracingProtocol string
+
+const (
+	// TracingProtocolGRPC is the OTLP/gRPC transport protocol.
+	TracingProtocolGRPC TracingProtocol = "grpc"
+	// TracingProtocolHTTPProtobuf is the OTLP/HTTP transport protocol with binary protobuf payloads.
+	TracingProtocolHTTPProtobuf TracingProtocol = "http/protobuf"
+)
+
+// Notifications contains settings for sending notifications about the events of the registry cache.
+type Notifications struct {
+	// Endpoints are the endpoints to which the events are sent.
+	Endpoints []NotificationEndpoint `json:"endpoints"`
+}
+
+// NotificationEndpoint contains settings for an endpoint to which the events of the registry cache are sent.
+type NotificationEndpoint struct {
+	// Name is the name of the endpoint. It must be unique within the registry cache.
+	Name string `json:"name"`
+	// URL is the URL to which the events are posted.
+	// The format must be `<scheme><host>[:<port>][<path>]` where `<scheme>` is `https://` or `http://`.
+	URL string `json:"url"`
+	// HeadersSecretReferenceName is the name of the reference for the Secret containing the headers sent with each
+	// request, e.g. for authentication. Each data entry of the Secret is a header name and its value.
+	// +optional
+	HeadersSecretReferenceName *string `json:"headersSecretReferenceName,omitempty"`
+	// Actions are the actions for which events are sent.
+	// Supported values are 'pull', 'push', 'mount' and 'delete'. Defaults to all actions.
+	// +optional
+	Actions []NotificationAction `json:"actions,omitempty"`
+	// IgnoredMediaTypes are the media types of the targets for which no events are sent,
+	// e.g. 'application/octet-stream' to ignore the events for blobs.
+	// +optional
+	IgnoredMediaTypes []string `json:"ignoredMediaTypes,omitempty"`
+}
+
+// NotificationAction represents an action of the registry cache for which an event is sent.
+type NotificationAction string
+
+const (
+	// NotificationActionPull is the action of pulling a manifest or blob.
+	NotificationActionPull NotificationAction = "pull"
+	// NotificationActionPush is the action of pushing a manifest or blob.
+	NotificationActionPush NotificationAction = "push"
+	// NotificationActionMount is the action of mounting a blob from another repository.
+	NotificationActionMount NotificationAction = "mount"
+	// NotificationActionDelete is the action of deleting a manifest or blob.
+	NotificationActionDelete NotificationAction = "delete"
+)
+
+// BlobDescriptorCache contains settings for caching the descriptors of the blobs in the registry cache.
+type BlobDescriptorCache struct {
+	// Type is the type of the blob descriptor cache.
+	// The only supported value is 'Redis', a Redis instance running as sidecar container of the registry cache.
+	Type BlobDescriptorCacheType `json:"type"`
+	// MaxMemory is the maximum memory used by the blob descriptor cache. The least recently used descriptors are
+	// evicted when the maximum memory is reached.
+	// Defaults to 64Mi.
+	// +optional
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+}
+
+// BlobDescriptorCacheType represents a type of the blob descriptor cache.
+type BlobDescriptorCacheType string
+
+const (
+	// BlobDescriptorCacheTypeRedis caches the blob descriptors in a Redis instance running as sidecar container.
+	BlobDescriptorCacheTypeRedis BlobDescriptorCacheType = "Redis"
+)
+
+// Exposure contains settings for exposing the registry cache outside of the Shoot cluster.
+type Exposure struct {
+	// Type is the type of the exposure.
+	// Supported values are 'LoadBalancer', 'InternalLoadBalancer' and 'Ingress'.
+	Type ExposureType `json:"type"`
+	// Hostname is the hostname under which the registry cache is exposed via the Ingress.
+	// It is required for exposure type 'Ingress' and forbidden otherwise.
+	// +optional
+	Hostname *string `json:"hostname,omitempty"`
+	// IngressClassName is the name of the IngressClass used by the Ingress.
+	// It can only be set for exposure type 'Ingress'.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Annotations are additional annotations for the Service of the registry cache (exposure types 'LoadBalancer' and
+	// 'InternalLoadBalancer') or for the Ingress (exposure type 'Ingress').
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ExposureType represents a type of exposure of a registry cache.
+type ExposureType string
+
+const (
+	// ExposureTypeLoadBalancer exposes the registry cache via a Service of type LoadBalancer.
+	ExposureTypeLoadBalancer ExposureType = "LoadBalancer"
+	// ExposureTypeInternalLoadBalancer exposes the registry cache via a Service of type LoadBalancer which is only
+	// reachable from the network of the Shoot cluster.
+	ExposureTypeInternalLoadBalancer ExposureType = "InternalLoadBalancer"
+	// ExposureTypeIngress exposes the registry cache via an Ingress with a hostname.
+	ExposureTypeIngress ExposureType = "Ingress"
+)
+
 var (
 	// DefaultTTL is the default time to live of a blob in the cache.
 	DefaultTTL = metav1.Duration{Duration: 7 * 24 * time.Hour}
@@ -112,9 +381,41 @@ type RegistryStatus struct {
 type RegistryCacheStatus struct {
 	// Upstream is the remote registry host (and optionally port).
 	Upstream string `json:"upstream"`
-	// Endpoint is the registry cache endpoint.
-	// Examples: "https://10.4.246.205:5000", "http://10.4.26.127:5000"
+	// Endpoint is the registry cache endpoint for the primary IP family of the Service.
+	// Examples: "https://10.4.246.205:5000", "http://10.4.26.127:5000", "https://[fd00:10:4::1a2b]:5000"
 	Endpoint string `json:"endpoint"`
+	// Endpoints are the registry cache endpoints for all IP families of the Service, ordered like the cluster IPs of the Service.
+	// The field contains two endpoints for dual-stack Shoots.
+	// +optional
+	Endpoints []string `json:"endpoints,omitempty"`
+	// Hostname is the stable hostname of the registry cache used by containerd instead of the cluster IP.
+	// The Nodes resolve the hostname to the cluster IP via a hosts file entry.
+	// The field is nil when the endpoint type is 'ClusterIP'.
+	// +optional
+	Hostname *string `json:"hostname,omitempty"`
 	// RemoteURL is the remote registry URL.
 	RemoteURL string `json:"remoteURL"`
+	// Strict denotes that containerd must not fall back to the upstream registry when the registry cache is not available.
+	// +optional
+	Strict bool `json:"strict,omitempty"`
+	// Capabilities are the operations the registry cache is capable of performing for containerd.
+	// +optional
+	Capabilities []RegistryCacheCapability `json:"capabilities,omitempty"`
+	// ProvidedCertificate denotes that the registry cache serves a certificate provided via a Secret reference
+	// instead of a certificate issued by the CA of the extension.
+	// +optional
+	ProvidedCertificate bool `json:"providedCertificate,omitempty"`
+	// CABundleSecretName is the name of the Secret containing the CA bundle used to verify the provided certificate
+	// of the registry cache. The field is nil when the provided certificate is verified with the system CAs of the Nodes.
+	// +optional
+	CABundleSecretName *string `json:"caBundleSecretName,omitempty"`
+	// ExternalEndpoint is the registry cache endpoint outside of the Shoot cluster.
+	// The field is nil when the registry cache is not exposed or the load balancer is not ready yet.
+	// Examples: "https://203.0.113.10:5000", "https://registry-cache.example.com"
+	// +optional
+	ExternalEndpoint *string `json:"externalEndpoint,omitempty"`
+	// PendingDisruptiveChanges denotes that the registry cache has changes which restart the registry cache Pod and
+	// which are deferred to the next maintenance time window of the Shoot.
+	// +optional
+	PendingDisruptiveChanges bool `json:"pendingDisruptiveChanges,omitempty"`
 }
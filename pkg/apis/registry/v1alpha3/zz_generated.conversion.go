@@ -24,6 +24,26 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*BlobDescriptorCache)(nil), (*registry.BlobDescriptorCache)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_BlobDescriptorCache_To_registry_BlobDescriptorCache(a.(*BlobDescriptorCache), b.(*registry.BlobDescriptorCache), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.BlobDescriptorCache)(nil), (*BlobDescriptorCache)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_BlobDescriptorCache_To_v1alpha3_BlobDescriptorCache(a.(*registry.BlobDescriptorCache), b.(*BlobDescriptorCache), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Exposure)(nil), (*registry.Exposure)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Exposure_To_registry_Exposure(a.(*Exposure), b.(*registry.Exposure), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.Exposure)(nil), (*Exposure)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_Exposure_To_v1alpha3_Exposure(a.(*registry.Exposure), b.(*Exposure), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*GarbageCollection)(nil), (*registry.GarbageCollection)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_GarbageCollection_To_registry_GarbageCollection(a.(*GarbageCollection), b.(*registry.GarbageCollection), scope)
 	}); err != nil {
@@ -44,6 +64,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*Log)(nil), (*registry.Log)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Log_To_registry_Log(a.(*Log), b.(*registry.Log), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.Log)(nil), (*Log)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_Log_To_v1alpha3_Log(a.(*registry.Log), b.(*Log), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*NotificationEndpoint)(nil), (*registry.NotificationEndpoint)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_NotificationEndpoint_To_registry_NotificationEndpoint(a.(*NotificationEndpoint), b.(*registry.NotificationEndpoint), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.NotificationEndpoint)(nil), (*NotificationEndpoint)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_NotificationEndpoint_To_v1alpha3_NotificationEndpoint(a.(*registry.NotificationEndpoint), b.(*NotificationEndpoint), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Notifications)(nil), (*registry.Notifications)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Notifications_To_registry_Notifications(a.(*Notifications), b.(*registry.Notifications), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.Notifications)(nil), (*Notifications)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_Notifications_To_v1alpha3_Notifications(a.(*registry.Notifications), b.(*Notifications), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Proxy)(nil), (*registry.Proxy)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_Proxy_To_registry_Proxy(a.(*Proxy), b.(*registry.Proxy), scope)
 	}); err != nil {
@@ -94,6 +144,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*Tracing)(nil), (*registry.Tracing)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Tracing_To_registry_Tracing(a.(*Tracing), b.(*registry.Tracing), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.Tracing)(nil), (*Tracing)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_Tracing_To_v1alpha3_Tracing(a.(*registry.Tracing), b.(*Tracing), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*UpstreamRateLimits)(nil), (*registry.UpstreamRateLimits)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_UpstreamRateLimits_To_registry_UpstreamRateLimits(a.(*UpstreamRateLimits), b.(*registry.UpstreamRateLimits), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*registry.UpstreamRateLimits)(nil), (*UpstreamRateLimits)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_registry_UpstreamRateLimits_To_v1alpha3_UpstreamRateLimits(a.(*registry.UpstreamRateLimits), b.(*UpstreamRateLimits), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Volume)(nil), (*registry.Volume)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_Volume_To_registry_Volume(a.(*Volume), b.(*registry.Volume), scope)
 	}); err != nil {
@@ -107,6 +177,54 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1alpha3_BlobDescriptorCache_To_registry_BlobDescriptorCache(in *BlobDescriptorCache, out *registry.BlobDescriptorCache, s conversion.Scope) error {
+	out.Type = registry.BlobDescriptorCacheType(in.Type)
+	out.MaxMemory = (*resource.Quantity)(unsafe.Pointer(in.MaxMemory))
+	return nil
+}
+
+// Convert_v1alpha3_BlobDescriptorCache_To_registry_BlobDescriptorCache is an autogenerated conversion function.
+func Convert_v1alpha3_BlobDescriptorCache_To_registry_BlobDescriptorCache(in *BlobDescriptorCache, out *registry.BlobDescriptorCache, s conversion.Scope) error {
+	return autoConvert_v1alpha3_BlobDescriptorCache_To_registry_BlobDescriptorCache(in, out, s)
+}
+
+func autoConvert_registry_BlobDescriptorCache_To_v1alpha3_BlobDescriptorCache(in *registry.BlobDescriptorCache, out *BlobDescriptorCache, s conversion.Scope) error {
+	out.Type = BlobDescriptorCacheType(in.Type)
+	out.MaxMemory = (*resource.Quantity)(unsafe.Pointer(in.MaxMemory))
+	return nil
+}
+
+// Convert_registry_BlobDescriptorCache_To_v1alpha3_BlobDescriptorCache is an autogenerated conversion function.
+func Convert_registry_BlobDescriptorCache_To_v1alpha3_BlobDescriptorCache(in *registry.BlobDescriptorCache, out *BlobDescriptorCache, s conversion.Scope) error {
+	return autoConvert_registry_BlobDescriptorCache_To_v1alpha3_BlobDescriptorCache(in, out, s)
+}
+
+func autoConvert_v1alpha3_Exposure_To_registry_Exposure(in *Exposure, out *registry.Exposure, s conversion.Scope) error {
+	out.Type = registry.ExposureType(in.Type)
+	out.Hostname = (*string)(unsafe.Pointer(in.Hostname))
+	out.IngressClassName = (*string)(unsafe.Pointer(in.IngressClassName))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
+	return nil
+}
+
+// Convert_v1alpha3_Exposure_To_registry_Exposure is an autogenerated conversion function.
+func Convert_v1alpha3_Exposure_To_registry_Exposure(in *Exposure, out *registry.Exposure, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Exposure_To_registry_Exposure(in, out, s)
+}
+
+func autoConvert_registry_Exposure_To_v1alpha3_Exposure(in *registry.Exposure, out *Exposure, s conversion.Scope) error {
+	out.Type = ExposureType(in.Type)
+	out.Hostname = (*string)(unsafe.Pointer(in.Hostname))
+	out.IngressClassName = (*string)(unsafe.Pointer(in.IngressClassName))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
+	return nil
+}
+
+// Convert_registry_Exposure_To_v1alpha3_Exposure is an autogenerated conversion function.
+func Convert_registry_Exposure_To_v1alpha3_Exposure(in *registry.Exposure, out *Exposure, s conversion.Scope) error {
+	return autoConvert_registry_Exposure_To_v1alpha3_Exposure(in, out, s)
+}
+
 func autoConvert_v1alpha3_GarbageCollection_To_registry_GarbageCollection(in *GarbageCollection, out *registry.GarbageCollection, s conversion.Scope) error {
 	out.TTL = in.TTL
 	return nil
@@ -129,6 +247,10 @@ func Convert_registry_GarbageCollection_To_v1alpha3_GarbageCollection(in *regist
 
 func autoConvert_v1alpha3_HTTP_To_registry_HTTP(in *HTTP, out *registry.HTTP, s conversion.Scope) error {
 	out.TLS = in.TLS
+	out.TLSSecretReferenceName = (*string)(unsafe.Pointer(in.TLSSecretReferenceName))
+	out.CABundleSecretReferenceName = (*string)(unsafe.Pointer(in.CABundleSecretReferenceName))
+	out.Port = (*int32)(unsafe.Pointer(in.Port))
+	out.DebugPort = (*int32)(unsafe.Pointer(in.DebugPort))
 	return nil
 }
 
@@ -139,6 +261,10 @@ func Convert_v1alpha3_HTTP_To_registry_HTTP(in *HTTP, out *registry.HTTP, s conv
 
 func autoConvert_registry_HTTP_To_v1alpha3_HTTP(in *registry.HTTP, out *HTTP, s conversion.Scope) error {
 	out.TLS = in.TLS
+	out.TLSSecretReferenceName = (*string)(unsafe.Pointer(in.TLSSecretReferenceName))
+	out.CABundleSecretReferenceName = (*string)(unsafe.Pointer(in.CABundleSecretReferenceName))
+	out.Port = (*int32)(unsafe.Pointer(in.Port))
+	out.DebugPort = (*int32)(unsafe.Pointer(in.DebugPort))
 	return nil
 }
 
@@ -147,6 +273,78 @@ func Convert_registry_HTTP_To_v1alpha3_HTTP(in *registry.HTTP, out *HTTP, s conv
 	return autoConvert_registry_HTTP_To_v1alpha3_HTTP(in, out, s)
 }
 
+func autoConvert_v1alpha3_Log_To_registry_Log(in *Log, out *registry.Log, s conversion.Scope) error {
+	out.Level = (*registry.LogLevel)(unsafe.Pointer(in.Level))
+	out.Format = (*registry.LogFormat)(unsafe.Pointer(in.Format))
+	out.AccessLog = (*bool)(unsafe.Pointer(in.AccessLog))
+	return nil
+}
+
+// Convert_v1alpha3_Log_To_registry_Log is an autogenerated conversion function.
+func Convert_v1alpha3_Log_To_registry_Log(in *Log, out *registry.Log, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Log_To_registry_Log(in, out, s)
+}
+
+func autoConvert_registry_Log_To_v1alpha3_Log(in *registry.Log, out *Log, s conversion.Scope) error {
+	out.Level = (*LogLevel)(unsafe.Pointer(in.Level))
+	out.Format = (*LogFormat)(unsafe.Pointer(in.Format))
+	out.AccessLog = (*bool)(unsafe.Pointer(in.AccessLog))
+	return nil
+}
+
+// Convert_registry_Log_To_v1alpha3_Log is an autogenerated conversion function.
+func Convert_registry_Log_To_v1alpha3_Log(in *registry.Log, out *Log, s conversion.Scope) error {
+	return autoConvert_registry_Log_To_v1alpha3_Log(in, out, s)
+}
+
+func autoConvert_v1alpha3_NotificationEndpoint_To_registry_NotificationEndpoint(in *NotificationEndpoint, out *registry.NotificationEndpoint, s conversion.Scope) error {
+	out.Name = in.Name
+	out.URL = in.URL
+	out.HeadersSecretReferenceName = (*string)(unsafe.Pointer(in.HeadersSecretReferenceName))
+	out.Actions = *(*[]registry.NotificationAction)(unsafe.Pointer(&in.Actions))
+	out.IgnoredMediaTypes = *(*[]string)(unsafe.Pointer(&in.IgnoredMediaTypes))
+	return nil
+}
+
+// Convert_v1alpha3_NotificationEndpoint_To_registry_NotificationEndpoint is an autogenerated conversion function.
+func Convert_v1alpha3_NotificationEndpoint_To_registry_NotificationEndpoint(in *NotificationEndpoint, out *registry.NotificationEndpoint, s conversion.Scope) error {
+	return autoConvert_v1alpha3_NotificationEndpoint_To_registry_NotificationEndpoint(in, out, s)
+}
+
+func autoConvert_registry_NotificationEndpoint_To_v1alpha3_NotificationEndpoint(in *registry.NotificationEndpoint, out *NotificationEndpoint, s conversion.Scope) error {
+	out.Name = in.Name
+	out.URL = in.URL
+	out.HeadersSecretReferenceName = (*string)(unsafe.Pointer(in.HeadersSecretReferenceName))
+	out.Actions = *(*[]NotificationAction)(unsafe.Pointer(&in.Actions))
+	out.IgnoredMediaTypes = *(*[]string)(unsafe.Pointer(&in.IgnoredMediaTypes))
+	return nil
+}
+
+// Convert_registry_NotificationEndpoint_To_v1alpha3_NotificationEndpoint is an autogenerated conversion function.
+func Convert_registry_NotificationEndpoint_To_v1alpha3_NotificationEndpoint(in *registry.NotificationEndpoint, out *NotificationEndpoint, s conversion.Scope) error {
+	return autoConvert_registry_NotificationEndpoint_To_v1alpha3_NotificationEndpoint(in, out, s)
+}
+
+func autoConvert_v1alpha3_Notifications_To_registry_Notifications(in *Notifications, out *registry.Notifications, s conversion.Scope) error {
+	out.Endpoints = *(*[]registry.NotificationEndpoint)(unsafe.Pointer(&in.Endpoints))
+	return nil
+}
+
+// Convert_v1alpha3_Notifications_To_registry_Notifications is an autogenerated conversion function.
+func Convert_v1alpha3_Notifications_To_registry_Notifications(in *Notifications, out *registry.Notifications, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Notifications_To_registry_Notifications(in, out, s)
+}
+
+func autoConvert_registry_Notifications_To_v1alpha3_Notifications(in *registry.Notifications, out *Notifications, s conversion.Scope) error {
+	out.Endpoints = *(*[]NotificationEndpoint)(unsafe.Pointer(&in.Endpoints))
+	return nil
+}
+
+// Convert_registry_Notifications_To_v1alpha3_Notifications is an autogenerated conversion function.
+func Convert_registry_Notifications_To_v1alpha3_Notifications(in *registry.Notifications, out *Notifications, s conversion.Scope) error {
+	return autoConvert_registry_Notifications_To_v1alpha3_Notifications(in, out, s)
+}
+
 func autoConvert_v1alpha3_Proxy_To_registry_Proxy(in *Proxy, out *registry.Proxy, s conversion.Scope) error {
 	out.HTTPProxy = (*string)(unsafe.Pointer(in.HTTPProxy))
 	out.HTTPSProxy = (*string)(unsafe.Pointer(in.HTTPSProxy))
@@ -175,8 +373,17 @@ func autoConvert_v1alpha3_RegistryCache_To_registry_RegistryCache(in *RegistryCa
 	out.Volume = (*registry.Volume)(unsafe.Pointer(in.Volume))
 	out.GarbageCollection = (*registry.GarbageCollection)(unsafe.Pointer(in.GarbageCollection))
 	out.SecretReferenceName = (*string)(unsafe.Pointer(in.SecretReferenceName))
+	out.UpstreamRateLimits = (*registry.UpstreamRateLimits)(unsafe.Pointer(in.UpstreamRateLimits))
 	out.Proxy = (*registry.Proxy)(unsafe.Pointer(in.Proxy))
 	out.HTTP = (*registry.HTTP)(unsafe.Pointer(in.HTTP))
+	out.Strict = (*bool)(unsafe.Pointer(in.Strict))
+	out.Capabilities = *(*[]registry.RegistryCacheCapability)(unsafe.Pointer(&in.Capabilities))
+	out.Exposure = (*registry.Exposure)(unsafe.Pointer(in.Exposure))
+	out.Log = (*registry.Log)(unsafe.Pointer(in.Log))
+	out.Tracing = (*registry.Tracing)(unsafe.Pointer(in.Tracing))
+	out.Notifications = (*registry.Notifications)(unsafe.Pointer(in.Notifications))
+	out.BlobDescriptorCache = (*registry.BlobDescriptorCache)(unsafe.Pointer(in.BlobDescriptorCache))
+	out.ConfigOverrides = (*runtime.RawExtension)(unsafe.Pointer(in.ConfigOverrides))
 	return nil
 }
 
@@ -191,8 +398,17 @@ func autoConvert_registry_RegistryCache_To_v1alpha3_RegistryCache(in *registry.R
 	out.Volume = (*Volume)(unsafe.Pointer(in.Volume))
 	out.GarbageCollection = (*GarbageCollection)(unsafe.Pointer(in.GarbageCollection))
 	out.SecretReferenceName = (*string)(unsafe.Pointer(in.SecretReferenceName))
+	out.UpstreamRateLimits = (*UpstreamRateLimits)(unsafe.Pointer(in.UpstreamRateLimits))
 	out.Proxy = (*Proxy)(unsafe.Pointer(in.Proxy))
 	out.HTTP = (*HTTP)(unsafe.Pointer(in.HTTP))
+	out.Strict = (*bool)(unsafe.Pointer(in.Strict))
+	out.Capabilities = *(*[]RegistryCacheCapability)(unsafe.Pointer(&in.Capabilities))
+	out.Exposure = (*Exposure)(unsafe.Pointer(in.Exposure))
+	out.Log = (*Log)(unsafe.Pointer(in.Log))
+	out.Tracing = (*Tracing)(unsafe.Pointer(in.Tracing))
+	out.Notifications = (*Notifications)(unsafe.Pointer(in.Notifications))
+	out.BlobDescriptorCache = (*BlobDescriptorCache)(unsafe.Pointer(in.BlobDescriptorCache))
+	out.ConfigOverrides = (*runtime.RawExtension)(unsafe.Pointer(in.ConfigOverrides))
 	return nil
 }
 
@@ -204,7 +420,15 @@ func Convert_registry_RegistryCache_To_v1alpha3_RegistryCache(in *registry.Regis
 func autoConvert_v1alpha3_RegistryCacheStatus_To_registry_RegistryCacheStatus(in *RegistryCacheStatus, out *registry.RegistryCacheStatus, s conversion.Scope) error {
 	out.Upstream = in.Upstream
 	out.Endpoint = in.Endpoint
+	out.Endpoints = *(*[]string)(unsafe.Pointer(&in.Endpoints))
+	out.Hostname = (*string)(unsafe.Pointer(in.Hostname))
 	out.RemoteURL = in.RemoteURL
+	out.Strict = in.Strict
+	out.Capabilities = *(*[]registry.RegistryCacheCapability)(unsafe.Pointer(&in.Capabilities))
+	out.ProvidedCertificate = in.ProvidedCertificate
+	out.CABundleSecretName = (*string)(unsafe.Pointer(in.CABundleSecretName))
+	out.ExternalEndpoint = (*string)(unsafe.Pointer(in.ExternalEndpoint))
+	out.PendingDisruptiveChanges = in.PendingDisruptiveChanges
 	return nil
 }
 
@@ -216,7 +440,15 @@ func Convert_v1alpha3_RegistryCacheStatus_To_registry_RegistryCacheStatus(in *Re
 func autoConvert_registry_RegistryCacheStatus_To_v1alpha3_RegistryCacheStatus(in *registry.RegistryCacheStatus, out *RegistryCacheStatus, s conversion.Scope) error {
 	out.Upstream = in.Upstream
 	out.Endpoint = in.Endpoint
+	out.Endpoints = *(*[]string)(unsafe.Pointer(&in.Endpoints))
+	out.Hostname = (*string)(unsafe.Pointer(in.Hostname))
 	out.RemoteURL = in.RemoteURL
+	out.Strict = in.Strict
+	out.Capabilities = *(*[]RegistryCacheCapability)(unsafe.Pointer(&in.Capabilities))
+	out.ProvidedCertificate = in.ProvidedCertificate
+	out.CABundleSecretName = (*string)(unsafe.Pointer(in.CABundleSecretName))
+	out.ExternalEndpoint = (*string)(unsafe.Pointer(in.ExternalEndpoint))
+	out.PendingDisruptiveChanges = in.PendingDisruptiveChanges
 	return nil
 }
 
@@ -227,6 +459,7 @@ func Convert_registry_RegistryCacheStatus_To_v1alpha3_RegistryCacheStatus(in *re
 
 func autoConvert_v1alpha3_RegistryConfig_To_registry_RegistryConfig(in *RegistryConfig, out *registry.RegistryConfig, s conversion.Scope) error {
 	out.Caches = *(*[]registry.RegistryCache)(unsafe.Pointer(&in.Caches))
+	out.EndpointType = (*registry.EndpointType)(unsafe.Pointer(in.EndpointType))
 	return nil
 }
 
@@ -237,6 +470,7 @@ func Convert_v1alpha3_RegistryConfig_To_registry_RegistryConfig(in *RegistryConf
 
 func autoConvert_registry_RegistryConfig_To_v1alpha3_RegistryConfig(in *registry.RegistryConfig, out *RegistryConfig, s conversion.Scope) error {
 	out.Caches = *(*[]RegistryCache)(unsafe.Pointer(&in.Caches))
+	out.EndpointType = (*EndpointType)(unsafe.Pointer(in.EndpointType))
 	return nil
 }
 
@@ -267,6 +501,56 @@ func Convert_registry_RegistryStatus_To_v1alpha3_RegistryStatus(in *registry.Reg
 	return autoConvert_registry_RegistryStatus_To_v1alpha3_RegistryStatus(in, out, s)
 }
 
+func autoConvert_v1alpha3_Tracing_To_registry_Tracing(in *Tracing, out *registry.Tracing, s conversion.Scope) error {
+	out.Endpoint = in.Endpoint
+	out.Protocol = (*registry.TracingProtocol)(unsafe.Pointer(in.Protocol))
+	out.SamplingRatio = (*string)(unsafe.Pointer(in.SamplingRatio))
+	out.HeadersSecretReferenceName = (*string)(unsafe.Pointer(in.HeadersSecretReferenceName))
+	return nil
+}
+
+// Convert_v1alpha3_Tracing_To_registry_Tracing is an autogenerated conversion function.
+func Convert_v1alpha3_Tracing_To_registry_Tracing(in *Tracing, out *registry.Tracing, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Tracing_To_registry_Tracing(in, out, s)
+}
+
+func autoConvert_registry_Tracing_To_v1alpha3_Tracing(in *registry.Tracing, out *Tracing, s conversion.Scope) error {
+	out.Endpoint = in.Endpoint
+	out.Protocol = (*TracingProtocol)(unsafe.Pointer(in.Protocol))
+	out.SamplingRatio = (*string)(unsafe.Pointer(in.SamplingRatio))
+	out.HeadersSecretReferenceName = (*string)(unsafe.Pointer(in.HeadersSecretReferenceName))
+	return nil
+}
+
+// Convert_registry_Tracing_To_v1alpha3_Tracing is an autogenerated conversion function.
+func Convert_registry_Tracing_To_v1alpha3_Tracing(in *registry.Tracing, out *Tracing, s conversion.Scope) error {
+	return autoConvert_registry_Tracing_To_v1alpha3_Tracing(in, out, s)
+}
+
+func autoConvert_v1alpha3_UpstreamRateLimits_To_registry_UpstreamRateLimits(in *UpstreamRateLimits, out *registry.UpstreamRateLimits, s conversion.Scope) error {
+	out.RequestsPerSecond = (*int32)(unsafe.Pointer(in.RequestsPerSecond))
+	out.MaxConcurrentRequests = (*int32)(unsafe.Pointer(in.MaxConcurrentRequests))
+	out.MaxBandwidth = (*resource.Quantity)(unsafe.Pointer(in.MaxBandwidth))
+	return nil
+}
+
+// Convert_v1alpha3_UpstreamRateLimits_To_registry_UpstreamRateLimits is an autogenerated conversion function.
+func Convert_v1alpha3_UpstreamRateLimits_To_registry_UpstreamRateLimits(in *UpstreamRateLimits, out *registry.UpstreamRateLimits, s conversion.Scope) error {
+	return autoConvert_v1alpha3_UpstreamRateLimits_To_registry_UpstreamRateLimits(in, out, s)
+}
+
+func autoConvert_registry_UpstreamRateLimits_To_v1alpha3_UpstreamRateLimits(in *registry.UpstreamRateLimits, out *UpstreamRateLimits, s conversion.Scope) error {
+	out.RequestsPerSecond = (*int32)(unsafe.Pointer(in.RequestsPerSecond))
+	out.MaxConcurrentRequests = (*int32)(unsafe.Pointer(in.MaxConcurrentRequests))
+	out.MaxBandwidth = (*resource.Quantity)(unsafe.Pointer(in.MaxBandwidth))
+	return nil
+}
+
+// Convert_registry_UpstreamRateLimits_To_v1alpha3_UpstreamRateLimits is an autogenerated conversion function.
+func Convert_registry_UpstreamRateLimits_To_v1alpha3_UpstreamRateLimits(in *registry.UpstreamRateLimits, out *UpstreamRateLimits, s conversion.Scope) error {
+	return autoConvert_registry_UpstreamRateLimits_To_v1alpha3_UpstreamRateLimits(in, out, s)
+}
+
 func autoConvert_v1alpha3_Volume_To_registry_Volume(in *Volume, out *registry.Volume, s conversion.Scope) error {
 	out.Size = (*resource.Quantity)(unsafe.Pointer(in.Size))
 	out.StorageClassName = (*string)(unsafe.Pointer(in.StorageClassName))
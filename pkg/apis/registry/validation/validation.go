@@ -5,20 +5,83 @@
 package validation
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/helper"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+)
+
+var (
+	supportedCapabilities = sets.New[string](
+		string(registry.RegistryCacheCapabilityPull),
+		string(registry.RegistryCacheCapabilityResolve),
+	)
+	supportedEndpointTypes = sets.New[string](
+		string(registry.EndpointTypeClusterIP),
+		string(registry.EndpointTypeHostname),
+	)
+	supportedLogLevels = sets.New[string](
+		string(registry.LogLevelError),
+		string(registry.LogLevelWarn),
+		string(registry.LogLevelInfo),
+		string(registry.LogLevelDebug),
+	)
+	supportedLogFormats = sets.New[string](
+		string(registry.LogFormatText),
+		string(registry.LogFormatJSON),
+		string(registry.LogFormatLogstash),
+	)
+	supportedTracingProtocols = sets.New[string](
+		string(registry.TracingProtocolGRPC),
+		string(registry.TracingProtocolHTTPProtobuf),
+	)
+	supportedNotificationActions = sets.New[string](
+		string(registry.NotificationActionPull),
+		string(registry.NotificationActionPush),
+		string(registry.NotificationActionMount),
+		string(registry.NotificationActionDelete),
+	)
+	supportedBlobDescriptorCacheTypes = sets.New[string](
+		string(registry.BlobDescriptorCacheTypeRedis),
+	)
+	// forbiddenConfigOverrides are the options of the registry configuration which are managed by the extension.
+	forbiddenConfigOverrides = [][]string{
+		{"version"},
+		{"storage", "filesystem", "rootdirectory"},
+		{"storage", "delete"},
+		{"http", "addr"},
+		{"http", "debug", "addr"},
+		{"http", "debug", "prometheus"},
+		{"http", "tls"},
+		{"proxy", "remoteurl"},
+		{"proxy", "username"},
+		{"proxy", "password"},
+		{"proxy", "ttl"},
+		{"health"},
+	}
+	supportedExposureTypes = sets.New[string](
+		string(registry.ExposureTypeLoadBalancer),
+		string(registry.ExposureTypeInternalLoadBalancer),
+		string(registry.ExposureTypeIngress),
+	)
 )
 
 // ValidateRegistryConfig validates the passed configuration instance.
@@ -29,6 +92,10 @@ func ValidateRegistryConfig(config *registry.RegistryConfig, fldPath *field.Path
 		allErrs = append(allErrs, field.Required(fldPath.Child("caches"), "at least one cache must be provided"))
 	}
 
+	if config.EndpointType != nil && !supportedEndpointTypes.Has(string(*config.EndpointType)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("endpointType"), *config.EndpointType, sets.List(supportedEndpointTypes)))
+	}
+
 	upstreams := sets.New[string]()
 	for i, cache := range config.Caches {
 		allErrs = append(allErrs, validateRegistryCache(cache, fldPath.Child("caches").Index(i))...)
@@ -59,6 +126,11 @@ func ValidateRegistryConfigUpdate(oldConfig, newConfig *registry.RegistryConfig,
 
 			allErrs = append(allErrs, apivalidation.ValidateImmutableField(helper.VolumeStorageClassName(&newCache), helper.VolumeStorageClassName(&oldCache), cacheFldPath.Child("volume").Child("storageClassName"))...)
 
+			// The hosts.toml file of a strict registry cache is written by the extension, otherwise by gardener-node-agent from
+			// the registry config. gardener-node-agent removes the hosts.toml file written by the respective other mechanism
+			// when a registry cache switches between both.
+			allErrs = append(allErrs, apivalidation.ValidateImmutableField(ptr.Deref(newCache.Strict, false), ptr.Deref(oldCache.Strict, false), cacheFldPath.Child("strict"))...)
+
 			// Mitigation for https://github.com/distribution/distribution/issues/4249
 			if !helper.GarbageCollectionEnabled(&oldCache) && helper.GarbageCollectionEnabled(&newCache) {
 				allErrs = append(allErrs, field.Invalid(cacheFldPath.Child("garbageCollection").Child("ttl"), newCache.GarbageCollection, "garbage collection cannot be enabled (ttl > 0) once it is disabled (ttl = 0)"))
@@ -74,7 +146,7 @@ func validateRegistryCache(cache registry.RegistryCache, fldPath *field.Path) fi
 
 	allErrs = append(allErrs, ValidateUpstream(fldPath.Child("upstream"), cache.Upstream)...)
 	if cache.RemoteURL != nil {
-		allErrs = append(allErrs, ValidateURL(fldPath.Child("remoteURL"), *cache.RemoteURL)...)
+		allErrs = append(allErrs, ValidateRemoteURL(fldPath.Child("remoteURL"), *cache.RemoteURL)...)
 	}
 	if cache.Volume != nil {
 		if cache.Volume.Size != nil {
@@ -94,6 +166,261 @@ func validateRegistryCache(cache registry.RegistryCache, fldPath *field.Path) fi
 			allErrs = append(allErrs, ValidateURL(fldPath.Child("proxy").Child("httpsProxy"), *cache.Proxy.HTTPSProxy)...)
 		}
 	}
+	if cache.UpstreamRateLimits != nil {
+		allErrs = append(allErrs, validateUpstreamRateLimits(fldPath.Child("upstreamRateLimits"), cache.UpstreamRateLimits)...)
+	}
+	if cache.HTTP != nil {
+		httpFldPath := fldPath.Child("http")
+		if cache.HTTP.TLSSecretReferenceName != nil && !cache.HTTP.TLS {
+			allErrs = append(allErrs, field.Forbidden(httpFldPath.Child("tlsSecretReferenceName"), "tls secret reference can only be set when TLS is enabled"))
+		}
+		if cache.HTTP.CABundleSecretReferenceName != nil && cache.HTTP.TLSSecretReferenceName == nil {
+			allErrs = append(allErrs, field.Forbidden(httpFldPath.Child("caBundleSecretReferenceName"), "CA bundle secret reference can only be set together with a tls secret reference"))
+		}
+		if cache.HTTP.Port != nil {
+			for _, msg := range validation.IsValidPortNum(int(*cache.HTTP.Port)) {
+				allErrs = append(allErrs, field.Invalid(httpFldPath.Child("port"), *cache.HTTP.Port, msg))
+			}
+		}
+		if cache.HTTP.DebugPort != nil {
+			for _, msg := range validation.IsValidPortNum(int(*cache.HTTP.DebugPort)) {
+				allErrs = append(allErrs, field.Invalid(httpFldPath.Child("debugPort"), *cache.HTTP.DebugPort, msg))
+			}
+		}
+		if helper.Port(&cache) == helper.DebugPort(&cache) {
+			allErrs = append(allErrs, field.Invalid(httpFldPath.Child("debugPort"), helper.DebugPort(&cache), "debug port must differ from the port of the registry cache"))
+		}
+		for _, port := range []struct {
+			fldPath *field.Path
+			value   int32
+		}{
+			{httpFldPath.Child("port"), helper.Port(&cache)},
+			{httpFldPath.Child("debugPort"), helper.DebugPort(&cache)},
+		} {
+			if port.value == constants.UpstreamLimiterPort || port.value == constants.UpstreamLimiterMetricsPort {
+				allErrs = append(allErrs, field.Invalid(port.fldPath, port.value, fmt.Sprintf("ports %d and %d are reserved for the upstream limiter", constants.UpstreamLimiterPort, constants.UpstreamLimiterMetricsPort)))
+			}
+		}
+	}
+	if cache.Log != nil {
+		if cache.Log.Level != nil && !supportedLogLevels.Has(string(*cache.Log.Level)) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("log", "level"), *cache.Log.Level, sets.List(supportedLogLevels)))
+		}
+		if cache.Log.Format != nil && !supportedLogFormats.Has(string(*cache.Log.Format)) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("log", "format"), *cache.Log.Format, sets.List(supportedLogFormats)))
+		}
+	}
+	if cache.Tracing != nil {
+		allErrs = append(allErrs, validateTracing(fldPath.Child("tracing"), cache.Tracing)...)
+	}
+	if cache.Notifications != nil {
+		allErrs = append(allErrs, validateNotifications(fldPath.Child("notifications"), cache.Notifications)...)
+	}
+	if cache.BlobDescriptorCache != nil {
+		blobDescriptorCacheFldPath := fldPath.Child("blobDescriptorCache")
+		if !supportedBlobDescriptorCacheTypes.Has(string(cache.BlobDescriptorCache.Type)) {
+			allErrs = append(allErrs, field.NotSupported(blobDescriptorCacheFldPath.Child("type"), cache.BlobDescriptorCache.Type, sets.List(supportedBlobDescriptorCacheTypes)))
+		}
+		if cache.BlobDescriptorCache.MaxMemory != nil {
+			allErrs = append(allErrs, validatePositiveQuantity(*cache.BlobDescriptorCache.MaxMemory, blobDescriptorCacheFldPath.Child("maxMemory"))...)
+		}
+	}
+	if cache.ConfigOverrides != nil {
+		allErrs = append(allErrs, validateConfigOverrides(fldPath.Child("configOverrides"), cache.ConfigOverrides)...)
+	}
+	if cache.Exposure != nil {
+		allErrs = append(allErrs, validateExposure(fldPath.Child("exposure"), cache.Exposure)...)
+	}
+	allErrs = append(allErrs, validateCapabilities(fldPath.Child("capabilities"), cache.Capabilities)...)
+	if ptr.Deref(cache.Strict, false) && !sets.New(cache.Capabilities...).HasAll(registry.RegistryCacheCapabilityPull, registry.RegistryCacheCapabilityResolve) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("capabilities"), "a strict registry cache must have all capabilities as containerd does not fall back to the upstream registry"))
+	}
+
+	return allErrs
+}
+
+func validateCapabilities(fldPath *field.Path, capabilities []registry.RegistryCacheCapability) field.ErrorList {
+	var allErrs field.ErrorList
+
+	capabilitiesFound := sets.New[string]()
+	for i, capability := range capabilities {
+		capabilityAsString := string(capability)
+
+		if !supportedCapabilities.Has(capabilityAsString) {
+			allErrs = append(allErrs, field.NotSupported(fldPath, capabilityAsString, sets.List(supportedCapabilities)))
+		}
+
+		if capabilitiesFound.Has(capabilityAsString) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), capabilityAsString))
+		} else {
+			capabilitiesFound.Insert(capabilityAsString)
+		}
+	}
+
+	return allErrs
+}
+
+func validateTracing(fldPath *field.Path, tracing *registry.Tracing) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, ValidateURL(fldPath.Child("endpoint"), tracing.Endpoint)...)
+	if tracing.Protocol != nil && !supportedTracingProtocols.Has(string(*tracing.Protocol)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("protocol"), *tracing.Protocol, sets.List(supportedTracingProtocols)))
+	}
+	if tracing.SamplingRatio != nil {
+		if ratio, err := strconv.ParseFloat(*tracing.SamplingRatio, 64); err != nil || ratio < 0 || ratio > 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("samplingRatio"), *tracing.SamplingRatio, "sampling ratio must be a number in range [0, 1]"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateUpstreamRateLimits(fldPath *field.Path, rateLimits *registry.UpstreamRateLimits) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if rateLimits.RequestsPerSecond == nil && rateLimits.MaxConcurrentRequests == nil && rateLimits.MaxBandwidth == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "at least one of requestsPerSecond, maxConcurrentRequests or maxBandwidth must be specified"))
+	}
+	if rateLimits.RequestsPerSecond != nil && *rateLimits.RequestsPerSecond <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("requestsPerSecond"), *rateLimits.RequestsPerSecond, "must be greater than 0"))
+	}
+	if rateLimits.MaxConcurrentRequests != nil && *rateLimits.MaxConcurrentRequests <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxConcurrentRequests"), *rateLimits.MaxConcurrentRequests, "must be greater than 0"))
+	}
+	if rateLimits.MaxBandwidth != nil {
+		allErrs = append(allErrs, validatePositiveQuantity(*rateLimits.MaxBandwidth, fldPath.Child("maxBandwidth"))...)
+	}
+
+	return allErrs
+}
+
+func validateNotifications(fldPath *field.Path, notifications *registry.Notifications) field.ErrorList {
+	var allErrs field.ErrorList
+
+	endpointsFldPath := fldPath.Child("endpoints")
+	if len(notifications.Endpoints) == 0 {
+		allErrs = append(allErrs, field.Required(endpointsFldPath, "at least one endpoint must be specified"))
+	}
+
+	names := sets.New[string]()
+	for i, endpoint := range notifications.Endpoints {
+		endpointFldPath := endpointsFldPath.Index(i)
+
+		for _, msg := range validation.IsDNS1123Label(endpoint.Name) {
+			allErrs = append(allErrs, field.Invalid(endpointFldPath.Child("name"), endpoint.Name, msg))
+		}
+		if names.Has(endpoint.Name) {
+			allErrs = append(allErrs, field.Duplicate(endpointFldPath.Child("name"), endpoint.Name))
+		} else {
+			names.Insert(endpoint.Name)
+		}
+
+		allErrs = append(allErrs, ValidateURL(endpointFldPath.Child("url"), endpoint.URL)...)
+
+		actions := sets.New[string]()
+		for j, action := range endpoint.Actions {
+			actionAsString := string(action)
+
+			if !supportedNotificationActions.Has(actionAsString) {
+				allErrs = append(allErrs, field.NotSupported(endpointFldPath.Child("actions").Index(j), actionAsString, sets.List(supportedNotificationActions)))
+			}
+
+			if actions.Has(actionAsString) {
+				allErrs = append(allErrs, field.Duplicate(endpointFldPath.Child("actions").Index(j), actionAsString))
+			} else {
+				actions.Insert(actionAsString)
+			}
+		}
+
+		for j, mediaType := range endpoint.IgnoredMediaTypes {
+			if mediaType == "" {
+				allErrs = append(allErrs, field.Required(endpointFldPath.Child("ignoredMediaTypes").Index(j), "media type must not be empty"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateConfigOverrides(fldPath *field.Path, configOverrides *runtime.RawExtension) field.ErrorList {
+	var allErrs field.ErrorList
+
+	var overrides map[string]any
+	if err := yaml.Unmarshal(configOverrides.Raw, &overrides); err != nil {
+		return append(allErrs, field.Invalid(fldPath, string(configOverrides.Raw), fmt.Sprintf("config overrides must be an object: %v", err)))
+	}
+
+	for _, option := range forbiddenConfigOverrides {
+		if overridesOption(overrides, option) {
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("option %q is managed by the extension and cannot be overridden", strings.Join(option, "."))))
+		}
+	}
+
+	return allErrs
+}
+
+// overridesOption returns whether the given overrides override the option with the given path. An option is also
+// overridden when one of its parents is replaced or removed by a value which is not an object.
+func overridesOption(overrides map[string]any, path []string) bool {
+	value, ok := overrides[path[0]]
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		return true
+	}
+
+	nested, ok := value.(map[string]any)
+	if !ok {
+		return true
+	}
+
+	return overridesOption(nested, path[1:])
+}
+
+func validateExposure(fldPath *field.Path, exposure *registry.Exposure) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !supportedExposureTypes.Has(string(exposure.Type)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), exposure.Type, sets.List(supportedExposureTypes)))
+	}
+
+	if exposure.Type == registry.ExposureTypeIngress {
+		if exposure.Hostname == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("hostname"), "hostname must be provided for exposure type 'Ingress'"))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(*exposure.Hostname) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("hostname"), *exposure.Hostname, msg))
+			}
+		}
+		if exposure.IngressClassName != nil {
+			for _, msg := range validation.IsDNS1123Subdomain(*exposure.IngressClassName) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("ingressClassName"), *exposure.IngressClassName, msg))
+			}
+		}
+	} else {
+		if exposure.Hostname != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostname"), "hostname can only be set for exposure type 'Ingress'"))
+		}
+		if exposure.IngressClassName != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ingressClassName"), "ingress class name can only be set for exposure type 'Ingress'"))
+		}
+	}
+
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(exposure.Annotations, fldPath.Child("annotations"))...)
+
+	return allErrs
+}
+
+// ValidateRemoteURL validates the remote URL of a registry cache. Additionally to ValidateURL, it validates that the
+// path of the URL is a prefix of the registry API root '/v2' as the registry cache always appends '/v2' to the remote URL.
+func ValidateRemoteURL(fldPath *field.Path, url string) field.ErrorList {
+	allErrs := ValidateURL(fldPath, url)
+
+	if registryutils.RequiresOverridePath(url) {
+		allErrs = append(allErrs, field.Invalid(fldPath, url, "path must not contain a 'v2' segment other than the last one, the registry cache appends the API root '/v2' to the remote URL"))
+	}
 
 	return allErrs
 }
@@ -109,6 +436,7 @@ func ValidateUpstream(fldPath *field.Path, upstream string) field.ErrorList {
 }
 
 var digitsRegex = regexp.MustCompile(`^\d+$`)
+var pathRegexp = regexp.MustCompile(`^(/[a-zA-Z0-9._~-]+)+$`)
 var portRegexp = regexp.MustCompile(`^([1-9][0-9]{0,3}|[1-5][0-9]{4}|6[0-4][0-9]{3}|65[0-4][0-9]{2}|655[0-2][0-9]|6553[0-5])$`)
 
 // validateHostPort check that host and optional port format is `<host>[:<port>]`
@@ -163,23 +491,102 @@ func ValidateUpstreamRegistrySecret(secret *corev1.Secret, fldPath *field.Path,
 	return allErrors
 }
 
-// ValidateURL validates that URL format is `<scheme><host>[:<port>]` where `<scheme>` is 'https://' or 'http://',
-// `<host>` is valid DNS subdomain (RFC 1123) and optional `<port>` is in range [1,65535].
+// ValidateHeadersSecret checks whether the given Secret is immutable and contains at least one data entry and
+// whether the keys of all data entries are valid HTTP header names.
+func ValidateHeadersSecret(secret *corev1.Secret, fldPath *field.Path, secretReference string) field.ErrorList {
+	var allErrors field.ErrorList
+
+	secretRef := fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+
+	if secret.Immutable == nil || !*secret.Immutable {
+		allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("referenced secret %q should be immutable", secretRef)))
+	}
+	if len(secret.Data) == 0 {
+		allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("referenced secret %q should have at least one data entry", secretRef)))
+	}
+	for _, key := range sets.List(sets.KeySet(secret.Data)) {
+		for _, msg := range validation.IsHTTPHeaderName(key) {
+			allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q is not a valid header name: %s", key, secretRef, msg)))
+		}
+	}
+
+	return allErrors
+}
+
+// ValidateTLSSecret checks whether the given Secret is immutable and contains a valid certificate and key pair in the
+// `data.tls.crt` and `data.tls.key` fields.
+func ValidateTLSSecret(secret *corev1.Secret, fldPath *field.Path, secretReference string) field.ErrorList {
+	var allErrors field.ErrorList
+
+	secretRef := fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+
+	if secret.Immutable == nil || !*secret.Immutable {
+		allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("referenced secret %q should be immutable", secretRef)))
+	}
+
+	cert, certOK := secret.Data[corev1.TLSCertKey]
+	if !certOK {
+		allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("missing %q data entry in referenced secret %q", corev1.TLSCertKey, secretRef)))
+	}
+	key, keyOK := secret.Data[corev1.TLSPrivateKeyKey]
+	if !keyOK {
+		allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("missing %q data entry in referenced secret %q", corev1.TLSPrivateKeyKey, secretRef)))
+	}
+	if certOK && keyOK {
+		if _, err := tls.X509KeyPair(cert, key); err != nil {
+			allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("referenced secret %q does not contain a valid certificate and key pair: %s", secretRef, err)))
+		}
+	}
+
+	return allErrors
+}
+
+// ValidateCABundleSecret checks whether the given Secret is immutable and contains a PEM encoded CA bundle in the
+// `data.ca.crt` field.
+func ValidateCABundleSecret(secret *corev1.Secret, fldPath *field.Path, secretReference string) field.ErrorList {
+	var allErrors field.ErrorList
+
+	secretRef := fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+
+	if secret.Immutable == nil || !*secret.Immutable {
+		allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("referenced secret %q should be immutable", secretRef)))
+	}
+
+	caBundle, ok := secret.Data["ca.crt"]
+	if !ok {
+		allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("missing %q data entry in referenced secret %q", "ca.crt", secretRef)))
+	} else if !x509.NewCertPool().AppendCertsFromPEM(caBundle) {
+		allErrors = append(allErrors, field.Invalid(fldPath, secretReference, fmt.Sprintf("data entry %q in referenced secret %q does not contain a valid PEM encoded certificate", "ca.crt", secretRef)))
+	}
+
+	return allErrors
+}
+
+// ValidateURL validates that URL format is `<scheme><host>[:<port>][<path>]` where `<scheme>` is 'https://' or 'http://',
+// `<host>` is valid DNS subdomain (RFC 1123), optional `<port>` is in range [1,65535] and optional `<path>` consists of
+// '/' separated non-empty segments.
 func ValidateURL(fldPath *field.Path, url string) field.ErrorList {
 	var allErrs field.ErrorList
-	var scheme string
+	var scheme, path string
 	host := url
 	index := strings.Index(url, "://")
 	if index != -1 {
 		scheme = url[:index]
 		host = url[index+len("://"):]
 	}
+	if index := strings.IndexByte(host, '/'); index != -1 {
+		path = host[index:]
+		host = host[:index]
+	}
 	if scheme != "https" && scheme != "http" {
 		allErrs = append(allErrs, field.Invalid(fldPath, url, "url must start with 'http://' or 'https://' scheme"))
 	}
 	for _, msg := range validateHostPort(host) {
 		allErrs = append(allErrs, field.Invalid(fldPath, url, msg))
 	}
+	if len(path) > 0 && !pathRegexp.MatchString(path) {
+		allErrs = append(allErrs, field.Invalid(fldPath, url, fmt.Sprintf("path '%s' is not valid, valid path must consist of '/' separated non-empty segments of alphanumeric characters, '-', '.', '_' or '~' and must not end with '/'", path)))
+	}
 
 	return allErrs
 }
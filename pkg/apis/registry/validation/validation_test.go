@@ -5,15 +5,18 @@
 package validation_test
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 
@@ -66,6 +69,14 @@ var _ = Describe("Validation", func() {
 					Upstream:  "quay.io",
 					RemoteURL: ptr.To("https://mirror-host.io:8443"),
 				},
+				api.RegistryCache{
+					Upstream:  "my-registry.io:5001",
+					RemoteURL: ptr.To("http://my-registry.io:5001/repository/docker"),
+				},
+				api.RegistryCache{
+					Upstream:  "my-registry.io:8443",
+					RemoteURL: ptr.To("https://my-registry.io:8443/repository/docker/v2"),
+				},
 			)
 			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
 		})
@@ -194,11 +205,11 @@ var _ = Describe("Validation", func() {
 			registryConfig.Caches = append(registryConfig.Caches,
 				api.RegistryCache{
 					Upstream:  "my-registry.io:5000",
-					RemoteURL: ptr.To("http://my-registry.io:5000/repository"),
+					RemoteURL: ptr.To("http://my-registry.io:5000/repository/"),
 				},
 				api.RegistryCache{
 					Upstream:  "my-registry.io:8443",
-					RemoteURL: ptr.To("https://my-registry.io:8443/repository"),
+					RemoteURL: ptr.To("https://my-registry.io:8443/v2/repository"),
 				},
 				api.RegistryCache{
 					Upstream:  "quay.io",
@@ -214,12 +225,13 @@ var _ = Describe("Validation", func() {
 				PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":     Equal(field.ErrorTypeInvalid),
 					"Field":    Equal("providerConfig.caches[1].remoteURL"),
-					"BadValue": Equal("http://my-registry.io:5000/repository"),
+					"BadValue": Equal("http://my-registry.io:5000/repository/"),
 				})),
 				PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":     Equal(field.ErrorTypeInvalid),
 					"Field":    Equal("providerConfig.caches[2].remoteURL"),
-					"BadValue": Equal("https://my-registry.io:8443/repository"),
+					"BadValue": Equal("https://my-registry.io:8443/v2/repository"),
+					"Detail":   Equal("path must not contain a 'v2' segment other than the last one, the registry cache appends the API root '/v2' to the remote URL"),
 				})),
 				PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":     Equal(field.ErrorTypeInvalid),
@@ -263,6 +275,534 @@ var _ = Describe("Validation", func() {
 				})),
 			))
 		})
+		It("should deny invalid capabilities", func() {
+			registryConfig.Caches[0].Capabilities = []api.RegistryCacheCapability{"pull", "foo", "pull"}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeNotSupported),
+					"Field":    Equal("providerConfig.caches[0].capabilities"),
+					"BadValue": Equal("foo"),
+					"Detail":   Equal(`supported values: "pull", "resolve"`),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeDuplicate),
+					"Field":    Equal("providerConfig.caches[0].capabilities[2]"),
+					"BadValue": Equal("pull"),
+				})),
+			))
+		})
+
+		It("should allow a pull-only registry cache", func() {
+			registryConfig.Caches[0].Capabilities = []api.RegistryCacheCapability{"pull"}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny a strict registry cache without all capabilities", func() {
+			registryConfig.Caches[0].Strict = ptr.To(true)
+			registryConfig.Caches[0].Capabilities = []api.RegistryCacheCapability{"pull"}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.caches[0].capabilities"),
+				})),
+			))
+
+			registryConfig.Caches[0].Capabilities = []api.RegistryCacheCapability{"resolve", "pull"}
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow TLS and CA bundle secret references when TLS is enabled", func() {
+			registryConfig.Caches[0].HTTP = &api.HTTP{
+				TLS:                         true,
+				TLSSecretReferenceName:      ptr.To("docker-tls"),
+				CABundleSecretReferenceName: ptr.To("docker-ca"),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny a TLS secret reference when TLS is disabled", func() {
+			registryConfig.Caches[0].HTTP = &api.HTTP{
+				TLS:                    false,
+				TLSSecretReferenceName: ptr.To("docker-tls"),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.caches[0].http.tlsSecretReferenceName"),
+					"Detail": Equal("tls secret reference can only be set when TLS is enabled"),
+				})),
+			))
+		})
+
+		It("should deny a CA bundle secret reference without a TLS secret reference", func() {
+			registryConfig.Caches[0].HTTP = &api.HTTP{
+				TLS:                         true,
+				CABundleSecretReferenceName: ptr.To("docker-ca"),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.caches[0].http.caBundleSecretReferenceName"),
+					"Detail": Equal("CA bundle secret reference can only be set together with a tls secret reference"),
+				})),
+			))
+		})
+
+		It("should allow valid ports and log settings", func() {
+			registryConfig.Caches[0].HTTP = &api.HTTP{
+				TLS:       true,
+				Port:      ptr.To[int32](8443),
+				DebugPort: ptr.To[int32](5000),
+			}
+			registryConfig.Caches[0].Log = &api.Log{
+				Level:     ptr.To(api.LogLevelDebug),
+				Format:    ptr.To(api.LogFormatJSON),
+				AccessLog: ptr.To(false),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny invalid ports", func() {
+			registryConfig.Caches[0].HTTP = &api.HTTP{
+				TLS:       true,
+				Port:      ptr.To[int32](70000),
+				DebugPort: ptr.To[int32](0),
+			}
+			registryConfig.Caches = append(registryConfig.Caches, api.RegistryCache{
+				Upstream: "ghcr.io",
+				HTTP: &api.HTTP{
+					TLS:  true,
+					Port: ptr.To[int32](5001),
+				},
+			})
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].http.port"),
+					"BadValue": Equal(int32(70000)),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].http.debugPort"),
+					"BadValue": Equal(int32(0)),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[1].http.debugPort"),
+					"BadValue": Equal(int32(5001)),
+					"Detail":   Equal("debug port must differ from the port of the registry cache"),
+				})),
+			))
+		})
+
+		It("should deny unsupported log settings", func() {
+			registryConfig.Caches[0].Log = &api.Log{
+				Level:  ptr.To(api.LogLevel("trace")),
+				Format: ptr.To(api.LogFormat("yaml")),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeNotSupported),
+					"Field":    Equal("providerConfig.caches[0].log.level"),
+					"BadValue": Equal(api.LogLevel("trace")),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeNotSupported),
+					"Field":    Equal("providerConfig.caches[0].log.format"),
+					"BadValue": Equal(api.LogFormat("yaml")),
+				})),
+			))
+		})
+
+		It("should allow valid tracing settings", func() {
+			registryConfig.Caches[0].Tracing = &api.Tracing{
+				Endpoint:                   "http://otel-collector.monitoring.svc:4317",
+				Protocol:                   ptr.To(api.TracingProtocolGRPC),
+				SamplingRatio:              ptr.To("0.1"),
+				HeadersSecretReferenceName: ptr.To("otel-headers"),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny invalid tracing settings", func() {
+			registryConfig.Caches[0].Tracing = &api.Tracing{
+				Endpoint:      "otel-collector:4318",
+				Protocol:      ptr.To(api.TracingProtocol("http/json")),
+				SamplingRatio: ptr.To("1.5"),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal("providerConfig.caches[0].tracing.endpoint"),
+					"Detail": Equal("url must start with 'http://' or 'https://' scheme"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeNotSupported),
+					"Field":    Equal("providerConfig.caches[0].tracing.protocol"),
+					"BadValue": Equal(api.TracingProtocol("http/json")),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].tracing.samplingRatio"),
+					"BadValue": Equal("1.5"),
+				})),
+			))
+
+			registryConfig.Caches[0].Tracing.Endpoint = "http://otel-collector:4318"
+			registryConfig.Caches[0].Tracing.Protocol = nil
+			registryConfig.Caches[0].Tracing.SamplingRatio = ptr.To("all")
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].tracing.samplingRatio"),
+					"BadValue": Equal("all"),
+				})),
+			))
+		})
+
+		It("should allow valid upstream rate limits", func() {
+			registryConfig.Caches[0].UpstreamRateLimits = &api.UpstreamRateLimits{
+				RequestsPerSecond:     ptr.To[int32](20),
+				MaxConcurrentRequests: ptr.To[int32](10),
+				MaxBandwidth:          ptr.To(resource.MustParse("50Mi")),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny empty upstream rate limits", func() {
+			registryConfig.Caches[0].UpstreamRateLimits = &api.UpstreamRateLimits{}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.caches[0].upstreamRateLimits"),
+				})),
+			))
+		})
+
+		It("should deny non-positive upstream rate limits", func() {
+			registryConfig.Caches[0].UpstreamRateLimits = &api.UpstreamRateLimits{
+				RequestsPerSecond:     ptr.To[int32](0),
+				MaxConcurrentRequests: ptr.To[int32](-1),
+				MaxBandwidth:          ptr.To(resource.MustParse("0")),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].upstreamRateLimits.requestsPerSecond"),
+					"BadValue": Equal(int32(0)),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].upstreamRateLimits.maxConcurrentRequests"),
+					"BadValue": Equal(int32(-1)),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].upstreamRateLimits.maxBandwidth"),
+				})),
+			))
+		})
+
+		It("should deny ports reserved for the upstream limiter", func() {
+			registryConfig.Caches[0].HTTP = &api.HTTP{
+				TLS:       true,
+				Port:      ptr.To[int32](5002),
+				DebugPort: ptr.To[int32](5003),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].http.port"),
+					"BadValue": Equal(int32(5002)),
+					"Detail":   Equal("ports 5002 and 5003 are reserved for the upstream limiter"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].http.debugPort"),
+					"BadValue": Equal(int32(5003)),
+					"Detail":   Equal("ports 5002 and 5003 are reserved for the upstream limiter"),
+				})),
+			))
+		})
+
+		It("should allow valid notification settings", func() {
+			registryConfig.Caches[0].Notifications = &api.Notifications{
+				Endpoints: []api.NotificationEndpoint{
+					{
+						Name:                       "audit",
+						URL:                        "https://audit.example.com/events",
+						HeadersSecretReferenceName: ptr.To("audit-headers"),
+						Actions:                    []api.NotificationAction{api.NotificationActionPull},
+						IgnoredMediaTypes:          []string{"application/octet-stream"},
+					},
+					{
+						Name: "receiver",
+						URL:  "http://receiver.monitoring.svc:8080",
+					},
+				},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny notifications without endpoints", func() {
+			registryConfig.Caches[0].Notifications = &api.Notifications{}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.caches[0].notifications.endpoints"),
+				})),
+			))
+		})
+
+		It("should deny invalid notification endpoints", func() {
+			registryConfig.Caches[0].Notifications = &api.Notifications{
+				Endpoints: []api.NotificationEndpoint{
+					{
+						Name:              "audit",
+						URL:               "audit.example.com",
+						Actions:           []api.NotificationAction{api.NotificationActionPull, "get", api.NotificationActionPull},
+						IgnoredMediaTypes: []string{""},
+					},
+					{
+						Name: "audit",
+						URL:  "https://audit.example.com",
+					},
+					{
+						Name: "Audit_2",
+						URL:  "https://audit.example.com",
+					},
+				},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal("providerConfig.caches[0].notifications.endpoints[0].url"),
+					"Detail": Equal("url must start with 'http://' or 'https://' scheme"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeNotSupported),
+					"Field":    Equal("providerConfig.caches[0].notifications.endpoints[0].actions[1]"),
+					"BadValue": Equal("get"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeDuplicate),
+					"Field":    Equal("providerConfig.caches[0].notifications.endpoints[0].actions[2]"),
+					"BadValue": Equal("pull"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.caches[0].notifications.endpoints[0].ignoredMediaTypes[0]"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeDuplicate),
+					"Field":    Equal("providerConfig.caches[0].notifications.endpoints[1].name"),
+					"BadValue": Equal("audit"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].notifications.endpoints[2].name"),
+					"BadValue": Equal("Audit_2"),
+				})),
+			))
+		})
+
+		It("should allow a valid blob descriptor cache", func() {
+			registryConfig.Caches[0].BlobDescriptorCache = &api.BlobDescriptorCache{
+				Type:      api.BlobDescriptorCacheTypeRedis,
+				MaxMemory: ptr.To(resource.MustParse("128Mi")),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an invalid blob descriptor cache", func() {
+			registryConfig.Caches[0].BlobDescriptorCache = &api.BlobDescriptorCache{
+				Type:      api.BlobDescriptorCacheType("inmemory"),
+				MaxMemory: ptr.To(resource.MustParse("0")),
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeNotSupported),
+					"Field":    Equal("providerConfig.caches[0].blobDescriptorCache.type"),
+					"BadValue": Equal(api.BlobDescriptorCacheType("inmemory")),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].blobDescriptorCache.maxMemory"),
+				})),
+			))
+		})
+
+		It("should allow config overrides of options which are not managed by the extension", func() {
+			registryConfig.Caches[0].ConfigOverrides = &runtime.RawExtension{Raw: []byte(`{"storage":{"tag":{"concurrencylimit":10}},"http":{"draintimeout":"60s","headers":null}}`)}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny config overrides which are not an object", func() {
+			registryConfig.Caches[0].ConfigOverrides = &runtime.RawExtension{Raw: []byte(`["foo"]`)}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal("providerConfig.caches[0].configOverrides"),
+					"Detail": ContainSubstring("config overrides must be an object"),
+				})),
+			))
+		})
+
+		DescribeTable("should deny config overrides of options which are managed by the extension",
+			func(overrides string, options ...string) {
+				registryConfig.Caches[0].ConfigOverrides = &runtime.RawExtension{Raw: []byte(overrides)}
+
+				var matchers []any
+				for _, option := range options {
+					matchers = append(matchers, PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeForbidden),
+						"Field":  Equal("providerConfig.caches[0].configOverrides"),
+						"Detail": Equal(fmt.Sprintf("option %q is managed by the extension and cannot be overridden", option)),
+					})))
+				}
+				Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(matchers...))
+			},
+			Entry("version", `{"version":"0.2"}`, "version"),
+			Entry("root directory", `{"storage":{"filesystem":{"rootdirectory":"/tmp"}}}`, "storage.filesystem.rootdirectory"),
+			Entry("removed filesystem storage driver", `{"storage":{"filesystem":null}}`, "storage.filesystem.rootdirectory"),
+			Entry("removed storage delete", `{"storage":{"delete":null}}`, "storage.delete"),
+			Entry("addresses", `{"http":{"addr":":8080","debug":{"addr":":8081"}}}`, "http.addr", "http.debug.addr"),
+			Entry("removed prometheus metrics", `{"http":{"debug":{"prometheus":null}}}`, "http.debug.prometheus"),
+			Entry("TLS", `{"http":{"tls":{"certificate":"/tmp/tls.crt"}}}`, "http.tls"),
+			Entry("proxy", `{"proxy":{"remoteurl":"https://ghcr.io","username":"foo","password":"bar"}}`, "proxy.remoteurl", "proxy.username", "proxy.password"),
+			Entry("proxy TTL", `{"proxy":{"ttl":"24h"}}`, "proxy.ttl"),
+			Entry("removed proxy", `{"proxy":null}`, "proxy.remoteurl", "proxy.username", "proxy.password", "proxy.ttl"),
+			Entry("replaced http", `{"http":"foo"}`, "http.addr", "http.debug.addr", "http.debug.prometheus", "http.tls"),
+			Entry("health", `{"health":{"storagedriver":{"threshold":5}}}`, "health"),
+			Entry("removed health", `{"health":null}`, "health"),
+		)
+
+		It("should allow valid endpoint types", func() {
+			registryConfig.EndpointType = ptr.To(api.EndpointTypeHostname)
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+
+			registryConfig.EndpointType = ptr.To(api.EndpointTypeClusterIP)
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an unsupported endpoint type", func() {
+			registryConfig.EndpointType = ptr.To(api.EndpointType("NodeLocal"))
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeNotSupported),
+					"Field":    Equal("providerConfig.endpointType"),
+					"BadValue": Equal(api.EndpointType("NodeLocal")),
+				})),
+			))
+		})
+
+		It("should allow valid exposures", func() {
+			registryConfig.Caches[0].Exposure = &api.Exposure{
+				Type:        api.ExposureTypeInternalLoadBalancer,
+				Annotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+			}
+			registryConfig.Caches = append(registryConfig.Caches, api.RegistryCache{
+				Upstream: "ghcr.io",
+				Exposure: &api.Exposure{
+					Type:             api.ExposureTypeIngress,
+					Hostname:         ptr.To("registry-cache.example.com"),
+					IngressClassName: ptr.To("nginx"),
+				},
+			})
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should deny an unsupported exposure type", func() {
+			registryConfig.Caches[0].Exposure = &api.Exposure{Type: "NodePort"}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeNotSupported),
+					"Field":    Equal("providerConfig.caches[0].exposure.type"),
+					"BadValue": Equal(api.ExposureType("NodePort")),
+				})),
+			))
+		})
+
+		It("should deny an Ingress exposure without a valid hostname", func() {
+			registryConfig.Caches[0].Exposure = &api.Exposure{Type: api.ExposureTypeIngress}
+			registryConfig.Caches = append(registryConfig.Caches, api.RegistryCache{
+				Upstream: "ghcr.io",
+				Exposure: &api.Exposure{
+					Type:             api.ExposureTypeIngress,
+					Hostname:         ptr.To("Registry_Cache"),
+					IngressClassName: ptr.To("Nginx!"),
+				},
+			})
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.caches[0].exposure.hostname"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[1].exposure.hostname"),
+					"BadValue": Equal("Registry_Cache"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[1].exposure.ingressClassName"),
+					"BadValue": Equal("Nginx!"),
+				})),
+			))
+		})
+
+		It("should deny Ingress settings and invalid annotations for a LoadBalancer exposure", func() {
+			registryConfig.Caches[0].Exposure = &api.Exposure{
+				Type:             api.ExposureTypeLoadBalancer,
+				Hostname:         ptr.To("registry-cache.example.com"),
+				IngressClassName: ptr.To("nginx"),
+				Annotations:      map[string]string{"foo/bar/baz": "true"},
+			}
+
+			Expect(ValidateRegistryConfig(registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.caches[0].exposure.hostname"),
+					"Detail": Equal("hostname can only be set for exposure type 'Ingress'"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.caches[0].exposure.ingressClassName"),
+					"Detail": Equal("ingress class name can only be set for exposure type 'Ingress'"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.caches[0].exposure.annotations"),
+				})),
+			))
+		})
 	})
 
 	Describe("#ValidateRegistryConfigUpdate", func() {
@@ -315,6 +855,43 @@ var _ = Describe("Validation", func() {
 			))
 		})
 
+		It("should deny enabling the strict mode of an existing cache", func() {
+			registryConfig.Caches[0].Strict = ptr.To(true)
+
+			Expect(ValidateRegistryConfigUpdate(oldRegistryConfig, registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].strict"),
+					"BadValue": Equal(true),
+					"Detail":   Equal("field is immutable"),
+				})),
+			))
+		})
+
+		It("should deny disabling the strict mode of an existing cache", func() {
+			oldRegistryConfig.Caches[0].Strict = ptr.To(true)
+
+			Expect(ValidateRegistryConfigUpdate(oldRegistryConfig, registryConfig, fldPath)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeInvalid),
+					"Field":    Equal("providerConfig.caches[0].strict"),
+					"BadValue": Equal(false),
+					"Detail":   Equal("field is immutable"),
+				})),
+			))
+		})
+
+		It("should allow adding a strict cache", func() {
+			size := resource.MustParse("5Gi")
+			registryConfig.Caches = append(registryConfig.Caches, api.RegistryCache{
+				Upstream: "quay.io",
+				Volume:   &api.Volume{Size: &size},
+				Strict:   ptr.To(true),
+			})
+
+			Expect(ValidateRegistryConfigUpdate(oldRegistryConfig, registryConfig, fldPath)).To(BeEmpty())
+		})
+
 		It("should deny garbage collection enablement (ttl > 0) once it is disabled (ttl = 0)", func() {
 			oldRegistryConfig.Caches[0].GarbageCollection = &api.GarbageCollection{
 				TTL: metav1.Duration{Duration: 0},
@@ -416,6 +993,169 @@ var _ = Describe("Validation", func() {
 		})
 	})
 
+	Describe("#ValidateHeadersSecret", func() {
+		var secret *corev1.Secret
+
+		BeforeEach(func() {
+			fldPath = fldPath.Child("caches").Index(0).Child("tracing", "headersSecretReferenceName")
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Data: map[string][]byte{
+					"Authorization": []byte("Bearer token"),
+					"X-Scope-OrgID": []byte("tenant"),
+				},
+				Immutable: ptr.To(true),
+			}
+		})
+
+		It("should allow a valid headers secret", func() {
+			Expect(ValidateHeadersSecret(secret, fldPath, "otel-headers")).To(BeEmpty())
+		})
+
+		It("should deny a mutable secret without data entries", func() {
+			secret.Immutable = nil
+			secret.Data = nil
+
+			Expect(ValidateHeadersSecret(secret, fldPath, "otel-headers")).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal("providerConfig.caches[0].tracing.headersSecretReferenceName"),
+					"Detail": Equal("referenced secret \"foo/bar\" should be immutable"),
+				})),
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal("providerConfig.caches[0].tracing.headersSecretReferenceName"),
+					"Detail": Equal("referenced secret \"foo/bar\" should have at least one data entry"),
+				})),
+			))
+		})
+
+		It("should deny a secret with an invalid header name", func() {
+			secret.Data["X Scope"] = []byte("tenant")
+
+			Expect(ValidateHeadersSecret(secret, fldPath, "otel-headers")).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeInvalid),
+					"Field":  Equal("providerConfig.caches[0].tracing.headersSecretReferenceName"),
+					"Detail": ContainSubstring("data entry \"X Scope\" in referenced secret \"foo/bar\" is not a valid header name"),
+				})),
+			))
+		})
+	})
+
+	Context("TLS secrets", func() {
+		var (
+			certificate *secretsutils.Certificate
+			secret      *corev1.Secret
+		)
+
+		BeforeEach(func() {
+			var err error
+			certificate, err = (&secretsutils.CertificateSecretConfig{
+				Name:       "registry-docker-io",
+				CommonName: "registry-docker-io",
+				CertType:   secretsutils.CACert,
+			}).GenerateCertificate()
+			Expect(err).NotTo(HaveOccurred())
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Immutable: ptr.To(true),
+			}
+		})
+
+		Describe("#ValidateTLSSecret", func() {
+			BeforeEach(func() {
+				fldPath = fldPath.Child("caches").Index(0).Child("http", "tlsSecretReferenceName")
+			})
+
+			It("should allow a valid TLS secret", func() {
+				secret.Data = map[string][]byte{
+					"tls.crt": certificate.CertificatePEM,
+					"tls.key": certificate.PrivateKeyPEM,
+				}
+
+				Expect(ValidateTLSSecret(secret, fldPath, "docker-tls")).To(BeEmpty())
+			})
+
+			It("should deny a mutable secret without certificate and key", func() {
+				secret.Immutable = nil
+
+				Expect(ValidateTLSSecret(secret, fldPath, "docker-tls")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.caches[0].http.tlsSecretReferenceName"),
+						"Detail": Equal(`referenced secret "foo/bar" should be immutable`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.caches[0].http.tlsSecretReferenceName"),
+						"Detail": Equal(`missing "tls.crt" data entry in referenced secret "foo/bar"`),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.caches[0].http.tlsSecretReferenceName"),
+						"Detail": Equal(`missing "tls.key" data entry in referenced secret "foo/bar"`),
+					})),
+				))
+			})
+
+			It("should deny a secret with an invalid key pair", func() {
+				secret.Data = map[string][]byte{
+					"tls.crt": certificate.CertificatePEM,
+					"tls.key": []byte("foo"),
+				}
+
+				Expect(ValidateTLSSecret(secret, fldPath, "docker-tls")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Detail": ContainSubstring(`referenced secret "foo/bar" does not contain a valid certificate and key pair`),
+					})),
+				))
+			})
+		})
+
+		Describe("#ValidateCABundleSecret", func() {
+			BeforeEach(func() {
+				fldPath = fldPath.Child("caches").Index(0).Child("http", "caBundleSecretReferenceName")
+			})
+
+			It("should allow a valid CA bundle secret", func() {
+				secret.Data = map[string][]byte{"ca.crt": certificate.CertificatePEM}
+
+				Expect(ValidateCABundleSecret(secret, fldPath, "docker-ca")).To(BeEmpty())
+			})
+
+			It("should deny a secret without CA bundle", func() {
+				Expect(ValidateCABundleSecret(secret, fldPath, "docker-ca")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.caches[0].http.caBundleSecretReferenceName"),
+						"Detail": Equal(`missing "ca.crt" data entry in referenced secret "foo/bar"`),
+					})),
+				))
+			})
+
+			It("should deny a secret with an invalid CA bundle", func() {
+				secret.Data = map[string][]byte{"ca.crt": []byte("foo")}
+
+				Expect(ValidateCABundleSecret(secret, fldPath, "docker-ca")).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(field.ErrorTypeInvalid),
+						"Field":  Equal("providerConfig.caches[0].http.caBundleSecretReferenceName"),
+						"Detail": Equal(`data entry "ca.crt" in referenced secret "foo/bar" does not contain a valid PEM encoded certificate`),
+					})),
+				))
+			})
+		})
+	})
+
 	Describe("#ValidateUpstream", func() {
 		BeforeEach(func() {
 			fldPath = fldPath.Child("caches").Index(0).Child("upstream")
@@ -461,6 +1201,8 @@ var _ = Describe("Validation", func() {
 			},
 			Entry("when url consists of valid scheme and host", "https://example.com"),
 			Entry("when url consists of valid scheme, host and port", "http://example.com:5000"),
+			Entry("when url consists of valid scheme, host and path", "https://example.com/repository/docker"),
+			Entry("when url consists of valid scheme, host, port and path", "https://example.com:8443/v2/docker-remote"),
 		)
 
 		DescribeTable("should deny invalid urls",
@@ -475,7 +1217,9 @@ var _ = Describe("Validation", func() {
 			Entry("when scheme is missing", "example.com"),
 			Entry("when scheme is not supported", "ftp://example.com"),
 			Entry("when port is invalid", "https://example.com:80443"),
-			Entry("when path is set", "https://example.com/myrepository"),
+			Entry("when path ends with '/'", "https://example.com/myrepository/"),
+			Entry("when path contains empty segment", "https://example.com/my//repository"),
+			Entry("when path contains query", "https://example.com/myrepository?foo=bar"),
 		)
 	})
 })
@@ -21,6 +21,7 @@ import (
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config/validation"
 	cachecontroller "github.com/gardener/gardener-extension-registry-cache/pkg/controller/cache"
 	mirrorcontroller "github.com/gardener/gardener-extension-registry-cache/pkg/controller/mirror"
+	sharedcachecontroller "github.com/gardener/gardener-extension-registry-cache/pkg/controller/sharedcache"
 	cachewebhook "github.com/gardener/gardener-extension-registry-cache/pkg/webhook/cache"
 	mirrorwebhook "github.com/gardener/gardener-extension-registry-cache/pkg/webhook/mirror"
 )
@@ -95,6 +96,7 @@ func ControllerSwitches() *cmd.SwitchOptions {
 	return cmd.NewSwitchOptions(
 		cmd.Switch(cachecontroller.ControllerName, cachecontroller.AddToManager),
 		cmd.Switch(mirrorcontroller.ControllerName, mirrorcontroller.AddToManager),
+		cmd.Switch(sharedcachecontroller.ControllerName, sharedcachecontroller.AddToManager),
 		cmd.Switch(extensionsheartbeatcontroller.ControllerName, extensionsheartbeatcontroller.AddToManager),
 	)
 }
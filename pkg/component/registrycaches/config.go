@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registrycaches
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/helper"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/component/registrycaches/distribution"
+)
+
+const (
+	repositoryMountPath = "/var/lib/registry"
+	certsMountPath      = "/etc/distribution/certs"
+	// redisAddr is the address of the Redis sidecar container which serves as blob descriptor cache.
+	redisAddr = "127.0.0.1:6379"
+)
+
+// NewConfig returns the registry configuration of a registry cache which serves on the given ports and caches the
+// content of the given remote URL for the given TTL.
+// Maintain the configuration with the default config file (/etc/distribution/config.yml) from the registry image
+// (europe-docker.pkg.dev/gardener-project/releases/3rd/registry:3.0.0-rc.3).
+func NewConfig(remoteURL string, ttl metav1.Duration, port, debugPort int32, tlsEnabled bool) *distribution.Configuration {
+	config := &distribution.Configuration{
+		Version: distribution.Version,
+		Log: distribution.Log{
+			Fields: map[string]string{"service": "registry"},
+		},
+		// The in-memory blob descriptor cache is not configured to mitigate https://github.com/distribution/distribution/issues/2367.
+		// For more details, see https://github.com/distribution/distribution/issues/2367#issuecomment-1874449361.
+		Storage: distribution.Storage{
+			Delete:     &distribution.Delete{Enabled: true},
+			Filesystem: &distribution.Filesystem{RootDirectory: repositoryMountPath},
+			Tag:        &distribution.Tag{ConcurrencyLimit: 5},
+		},
+		HTTP: distribution.HTTP{
+			Addr: fmt.Sprintf(":%d", port),
+			Debug: &distribution.Debug{
+				Addr: fmt.Sprintf(":%d", debugPort),
+				Prometheus: &distribution.Prometheus{
+					Enabled: true,
+					Path:    "/metrics",
+				},
+			},
+			DrainTimeout: &metav1.Duration{Duration: 25 * time.Second},
+			Headers: map[string][]string{
+				"X-Content-Type-Options": {"nosniff"},
+			},
+		},
+		Health: &distribution.Health{
+			StorageDriver: &distribution.StorageDriverHealth{
+				Enabled:   true,
+				Interval:  &metav1.Duration{Duration: 10 * time.Second},
+				Threshold: 3,
+			},
+		},
+		Proxy: &distribution.Proxy{
+			RemoteURL: remoteURL,
+			TTL:       &ttl,
+		},
+	}
+
+	if tlsEnabled {
+		config.HTTP.TLS = &distribution.TLS{
+			Certificate: certsMountPath + "/tls.crt",
+			Key:         certsMountPath + "/tls.key",
+		}
+	}
+
+	return config
+}
+
+// notificationActions are all actions of the registry for which events are sent.
+var notificationActions = []api.NotificationAction{
+	api.NotificationActionPull,
+	api.NotificationActionPush,
+	api.NotificationActionMount,
+	api.NotificationActionDelete,
+}
+
+// ComputeConfig returns the registry configuration of the given registry cache which caches the content of the given
+// remote URL. The given username and password are used to authenticate against the remote registry if both are set.
+// The given notification headers contain the data of the headers Secrets by notification endpoint name.
+func ComputeConfig(cache *api.RegistryCache, remoteURL, username, password string, notificationHeaders map[string]map[string][]byte) *distribution.Configuration {
+	config := NewConfig(remoteURL, helper.GarbageCollectionTTL(cache), helper.Port(cache), helper.DebugPort(cache), helper.TLSEnabled(cache))
+
+	if cache.Log != nil {
+		config.Log.Level = string(ptr.Deref(cache.Log.Level, ""))
+		config.Log.Formatter = string(ptr.Deref(cache.Log.Format, ""))
+		if !ptr.Deref(cache.Log.AccessLog, true) {
+			config.Log.AccessLog = &distribution.AccessLog{Disabled: true}
+		}
+	}
+
+	if cache.Notifications != nil {
+		config.Notifications = &distribution.Notifications{}
+		for _, endpoint := range cache.Notifications.Endpoints {
+			config.Notifications.Endpoints = append(config.Notifications.Endpoints, notificationEndpoint(endpoint, notificationHeaders[endpoint.Name]))
+		}
+	}
+
+	if helper.BlobDescriptorCacheEnabled(cache) {
+		config.Storage.Cache = &distribution.Cache{BlobDescriptor: "redis"}
+		config.Redis = &distribution.Redis{
+			Addrs:        []string{redisAddr},
+			DialTimeout:  &metav1.Duration{Duration: time.Second},
+			ReadTimeout:  &metav1.Duration{Duration: time.Second},
+			WriteTimeout: &metav1.Duration{Duration: time.Second},
+			PoolSize:     10,
+		}
+	}
+
+	if username != "" && password != "" {
+		config.Proxy.Username = username
+		config.Proxy.Password = password
+	}
+
+	return config
+}
+
+// notificationEndpoint returns the configuration of the given notification endpoint with the given headers.
+// Distribution only supports ignoring actions, hence the actions which are not selected are ignored.
+func notificationEndpoint(endpoint api.NotificationEndpoint, headers map[string][]byte) distribution.Endpoint {
+	result := distribution.Endpoint{
+		Name: endpoint.Name,
+		URL:  endpoint.URL,
+	}
+
+	for name, value := range headers {
+		if result.Headers == nil {
+			result.Headers = make(map[string][]string, len(headers))
+		}
+		result.Headers[name] = []string{string(value)}
+	}
+
+	var ignoredActions []string
+	if len(endpoint.Actions) > 0 {
+		for _, action := range notificationActions {
+			if !slices.Contains(endpoint.Actions, action) {
+				ignoredActions = append(ignoredActions, string(action))
+			}
+		}
+	}
+
+	if len(ignoredActions) > 0 || len(endpoint.IgnoredMediaTypes) > 0 {
+		result.Ignore = &distribution.Ignore{
+			MediaTypes: endpoint.IgnoredMediaTypes,
+			Actions:    ignoredActions,
+		}
+	}
+
+	return result
+}
+
+// RenderConfig renders the registry configuration file (config.yml) of the given registry cache, see ComputeConfig.
+// The configuration overrides of the registry cache are merged into the rendered configuration.
+func RenderConfig(cache *api.RegistryCache, remoteURL, username, password string, notificationHeaders map[string]map[string][]byte) ([]byte, error) {
+	config, err := distribution.Marshal(ComputeConfig(cache, remoteURL, username, password, notificationHeaders))
+	if err != nil {
+		return nil, err
+	}
+
+	if cache.ConfigOverrides == nil {
+		return config, nil
+	}
+
+	return distribution.ApplyOverrides(config, cache.ConfigOverrides.Raw)
+}
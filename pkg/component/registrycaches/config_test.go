@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registrycaches_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/component/registrycaches"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/component/registrycaches/distribution"
+)
+
+// Run the tests with `-update-golden-files` to update the golden files after an intended change of the configuration.
+var updateGoldenFiles = flag.Bool("update-golden-files", false, "update the golden files in the testdata directory")
+
+var _ = Describe("Config", func() {
+	const remoteURL = "https://registry-1.docker.io"
+
+	DescribeTable("#RenderConfig",
+		func(cache *api.RegistryCache, username, password, goldenFile string) {
+			config, err := RenderConfig(cache, remoteURL, username, password, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			goldenFilePath := filepath.Join("testdata", "config", goldenFile)
+			if *updateGoldenFiles {
+				Expect(os.WriteFile(goldenFilePath, config, 0600)).To(Succeed())
+			}
+
+			expected, err := os.ReadFile(goldenFilePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(config)).To(Equal(string(expected)))
+		},
+		Entry("default settings",
+			&api.RegistryCache{Upstream: "docker.io"}, "", "", "default.yaml"),
+		Entry("TLS disabled",
+			&api.RegistryCache{Upstream: "docker.io", HTTP: &api.HTTP{TLS: false}}, "", "", "tls-disabled.yaml"),
+		Entry("garbage collection disabled",
+			&api.RegistryCache{Upstream: "docker.io", GarbageCollection: &api.GarbageCollection{TTL: metav1.Duration{Duration: 0}}}, "", "", "garbage-collection-disabled.yaml"),
+		Entry("custom garbage collection ttl",
+			&api.RegistryCache{Upstream: "docker.io", GarbageCollection: &api.GarbageCollection{TTL: metav1.Duration{Duration: 14 * 24 * time.Hour}}}, "", "", "garbage-collection-ttl.yaml"),
+		Entry("upstream credentials",
+			&api.RegistryCache{Upstream: "docker.io"}, "docker-user", "s3cret", "credentials.yaml"),
+		Entry("upstream credentials with special characters",
+			&api.RegistryCache{Upstream: "docker.io"}, "docker-user", "it's a\n'secret': {\"foo\": [bar]} # comment", "credentials-special-characters.yaml"),
+		Entry("upstream credentials without password",
+			&api.RegistryCache{Upstream: "docker.io"}, "docker-user", "", "default.yaml"),
+		Entry("custom ports",
+			&api.RegistryCache{Upstream: "docker.io", HTTP: &api.HTTP{TLS: true, Port: ptr.To[int32](8443), DebugPort: ptr.To[int32](9090)}}, "", "", "ports.yaml"),
+		Entry("log level and format",
+			&api.RegistryCache{Upstream: "docker.io", Log: &api.Log{Level: ptr.To(api.LogLevelDebug), Format: ptr.To(api.LogFormatJSON)}}, "", "", "log.yaml"),
+		Entry("access log enabled",
+			&api.RegistryCache{Upstream: "docker.io", Log: &api.Log{AccessLog: ptr.To(true)}}, "", "", "default.yaml"),
+		Entry("access log disabled",
+			&api.RegistryCache{Upstream: "docker.io", Log: &api.Log{AccessLog: ptr.To(false)}}, "", "", "access-log-disabled.yaml"),
+		Entry("blob descriptor cache",
+			&api.RegistryCache{Upstream: "docker.io", BlobDescriptorCache: &api.BlobDescriptorCache{Type: api.BlobDescriptorCacheTypeRedis}}, "", "", "blob-descriptor-cache.yaml"),
+		Entry("notifications",
+			&api.RegistryCache{
+				Upstream: "docker.io",
+				Notifications: &api.Notifications{
+					Endpoints: []api.NotificationEndpoint{
+						{
+							Name: "receiver",
+							URL:  "http://receiver.monitoring.svc:8080/events",
+						},
+						{
+							Name:              "audit",
+							URL:               "https://audit.example.com",
+							Actions:           []api.NotificationAction{api.NotificationActionPull, api.NotificationActionDelete},
+							IgnoredMediaTypes: []string{"application/octet-stream"},
+						},
+					},
+				},
+			}, "", "", "notifications.yaml"),
+		Entry("all settings",
+			&api.RegistryCache{
+				Upstream:          "docker.io",
+				GarbageCollection: &api.GarbageCollection{TTL: metav1.Duration{Duration: 0}},
+				HTTP:              &api.HTTP{TLS: false, Port: ptr.To[int32](8080), DebugPort: ptr.To[int32](9090)},
+				Log:               &api.Log{Level: ptr.To(api.LogLevelWarn), Format: ptr.To(api.LogFormatLogstash), AccessLog: ptr.To(false)},
+			}, "docker-user", "s3cret", "all.yaml"),
+		Entry("config overrides",
+			&api.RegistryCache{
+				Upstream: "docker.io",
+				Log:      &api.Log{Level: ptr.To(api.LogLevelDebug)},
+				ConfigOverrides: &runtime.RawExtension{Raw: []byte(`{
+  "log": {"level": "error"},
+  "storage": {"tag": {"concurrencylimit": 10}, "maintenance": {"uploadpurging": {"enabled": false}}},
+  "http": {"draintimeout": "60s", "headers": null}
+}`)},
+			}, "", "", "config-overrides.yaml"),
+	)
+
+	It("should fail to render the configuration with config overrides which are null", func() {
+		cache := &api.RegistryCache{
+			Upstream:        "docker.io",
+			ConfigOverrides: &runtime.RawExtension{Raw: []byte(`null`)},
+		}
+
+		_, err := RenderConfig(cache, remoteURL, "", "", nil)
+		Expect(err).To(MatchError("configuration overrides must be an object"))
+	})
+
+	It("should fail to render the configuration with invalid config overrides", func() {
+		cache := &api.RegistryCache{
+			Upstream:        "docker.io",
+			ConfigOverrides: &runtime.RawExtension{Raw: []byte(`["foo"]`)},
+		}
+
+		_, err := RenderConfig(cache, remoteURL, "", "", nil)
+		Expect(err).To(MatchError(ContainSubstring("failed to unmarshal configuration overrides")))
+	})
+
+	It("should preserve a password with special characters", func() {
+		password := "it's a\n'secret': {\"foo\": [bar]} # comment"
+
+		data, err := distribution.Marshal(ComputeConfig(&api.RegistryCache{Upstream: "docker.io"}, remoteURL, "docker-user", password, nil))
+		Expect(err).NotTo(HaveOccurred())
+
+		config := &distribution.Configuration{}
+		Expect(yaml.UnmarshalStrict(data, config)).To(Succeed())
+		Expect(config.Proxy.Password).To(Equal(password))
+	})
+
+	It("should add the headers to the notification endpoints", func() {
+		cache := &api.RegistryCache{
+			Upstream: "docker.io",
+			Notifications: &api.Notifications{
+				Endpoints: []api.NotificationEndpoint{
+					{Name: "receiver", URL: "http://receiver.monitoring.svc:8080/events"},
+					{Name: "audit", URL: "https://audit.example.com", HeadersSecretReferenceName: ptr.To("audit-headers")},
+				},
+			},
+		}
+		notificationHeaders := map[string]map[string][]byte{
+			"audit": {
+				"Authorization": []byte("Bearer token"),
+				"X-Tenant":      []byte("foo"),
+			},
+		}
+
+		config := ComputeConfig(cache, remoteURL, "", "", notificationHeaders)
+		Expect(config.Notifications.Endpoints).To(ConsistOf(
+			distribution.Endpoint{
+				Name: "receiver",
+				URL:  "http://receiver.monitoring.svc:8080/events",
+			},
+			distribution.Endpoint{
+				Name: "audit",
+				URL:  "https://audit.example.com",
+				Headers: map[string][]string{
+					"Authorization": {"Bearer token"},
+					"X-Tenant":      {"foo"},
+				},
+			},
+		))
+	})
+
+	Describe("#NewConfig", func() {
+		It("should return the configuration without the settings of a registry cache", func() {
+			config, err := distribution.Marshal(NewConfig(remoteURL, metav1.Duration{Duration: 7 * 24 * time.Hour}, 5000, 5001, true))
+			Expect(err).NotTo(HaveOccurred())
+
+			expected, err := os.ReadFile(filepath.Join("testdata", "config", "default.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(config)).To(Equal(string(expected)))
+		})
+	})
+})
@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registrycaches
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/gardener/gardener/pkg/utils"
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/helper"
+)
+
+// podTemplateChecksumAnnotation is an annotation on the registry cache StatefulSet which denotes the checksum of its
+// desired Pod template. The deployed Pod template cannot be compared with the desired one as it is defaulted.
+const podTemplateChecksumAnnotation = "registry-cache.extensions.gardener.cloud/pod-template-checksum"
+
+// deferDisruptiveChanges returns whether the changes of the Pod template of the given desired StatefulSet of the given
+// registry cache are deferred. It returns the deployed StatefulSet if so.
+func (r *registryCaches) deferDisruptiveChanges(ctx context.Context, cache *api.RegistryCache, desired *appsv1.StatefulSet) (*appsv1.StatefulSet, bool, error) {
+	if !r.values.DeferDisruptiveChanges {
+		return nil, false, nil
+	}
+
+	deployed := &appsv1.StatefulSet{}
+	if err := r.shootClient.Get(ctx, client.ObjectKeyFromObject(desired), deployed); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get the deployed StatefulSet %s: %w", client.ObjectKeyFromObject(desired), err)
+	}
+	if deployed.Annotations[podTemplateChecksumAnnotation] == desired.Annotations[podTemplateChecksumAnnotation] {
+		return nil, false, nil
+	}
+
+	endpointChanged, err := r.endpointChanged(ctx, cache, deployed, desired)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return deployed, !endpointChanged, nil
+}
+
+// endpointChanged returns whether the given desired StatefulSet changes the endpoint of the given deployed
+// StatefulSet, i.e. the port, whether TLS is enabled or the served certificate. Such changes are not deferred because
+// the Service of the registry cache and the containerd configuration of the Nodes are updated immediately.
+// A renewed certificate issued by the CA of the extension is deferred as long as the served certificate is issued by
+// the current CA.
+func (r *registryCaches) endpointChanged(ctx context.Context, cache *api.RegistryCache, deployed, desired *appsv1.StatefulSet) (bool, error) {
+	deployedPort, deployedTLSSecretName := endpointOf(deployed)
+	desiredPort, desiredTLSSecretName := endpointOf(desired)
+
+	if deployedPort != desiredPort || (deployedTLSSecretName == "") != (desiredTLSSecretName == "") {
+		return true, nil
+	}
+	if deployedTLSSecretName == desiredTLSSecretName {
+		return false, nil
+	}
+	if helper.TLSCertificateProvided(cache) {
+		return true, nil
+	}
+
+	deployedTLSSecret := &corev1.Secret{}
+	if err := r.shootClient.Get(ctx, client.ObjectKey{Namespace: deployed.Namespace, Name: deployedTLSSecretName}, deployedTLSSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get the deployed Secret %s: %w", deployedTLSSecretName, err)
+	}
+
+	return !r.issuedByCA(deployedTLSSecret), nil
+}
+
+// endpointOf returns the port of the registry cache and the name of the Secret containing the served certificate of
+// the given StatefulSet. The Secret name is empty when TLS is disabled.
+func endpointOf(statefulSet *appsv1.StatefulSet) (int32, string) {
+	var (
+		port          int32
+		tlsVolumeName string
+		tlsSecretName string
+	)
+
+	for _, container := range statefulSet.Spec.Template.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == "registry-cache" {
+				port = containerPort.ContainerPort
+			}
+		}
+		for _, volumeMount := range container.VolumeMounts {
+			if volumeMount.MountPath == certsMountPath {
+				tlsVolumeName = volumeMount.Name
+			}
+		}
+	}
+
+	for _, volume := range statefulSet.Spec.Template.Spec.Volumes {
+		if volume.Name == tlsVolumeName && volume.Secret != nil {
+			tlsSecretName = volume.Secret.SecretName
+		}
+	}
+
+	return port, tlsSecretName
+}
+
+// issuedByCA returns whether the certificate of the given Secret is issued by the current CA of the extension.
+func (r *registryCaches) issuedByCA(secret *corev1.Secret) bool {
+	if secret == nil || r.caCertificate == nil {
+		return false
+	}
+
+	certificate, err := utils.DecodeCertificate(secret.Data[secretsutils.DataKeyCertificate])
+	if err != nil {
+		return false
+	}
+
+	return certificate.CheckSignatureFrom(r.caCertificate) == nil
+}
+
+// deployedReferencedSecrets returns the deployed Secrets which are referenced by the Pod template of the given deployed
+// StatefulSet. Only the fields which are managed by the ManagedResource are kept.
+func (r *registryCaches) deployedReferencedSecrets(ctx context.Context, deployed *appsv1.StatefulSet) ([]client.Object, error) {
+	podSpec := deployed.Spec.Template.Spec
+
+	names := sets.New[string]()
+	for _, volume := range podSpec.Volumes {
+		if volume.Secret != nil {
+			names.Insert(volume.Secret.SecretName)
+		}
+	}
+	for _, container := range podSpec.Containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names.Insert(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	var secrets []client.Object
+	for _, name := range sets.List(names) {
+		secret := &corev1.Secret{}
+		if err := r.shootClient.Get(ctx, client.ObjectKey{Namespace: deployed.Namespace, Name: name}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get the deployed Secret %s: %w", name, err)
+		}
+
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secret.Name,
+				Namespace: secret.Namespace,
+				Labels:    secret.Labels,
+			},
+			Type:      secret.Type,
+			Immutable: secret.Immutable,
+			Data:      secret.Data,
+		})
+	}
+
+	return secrets, nil
+}
+
+// parseCACertificate returns the certificate of the given CA Secret. It returns nil if the Secret is nil.
+func parseCACertificate(caSecret *corev1.Secret) (*x509.Certificate, error) {
+	if caSecret == nil {
+		return nil, nil
+	}
+
+	return utils.DecodeCertificate(caSecret.Data[secretsutils.DataKeyCertificateCA])
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registrycaches_test
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils"
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/component/registrycaches"
+)
+
+var _ = Describe("DisruptiveChanges", func() {
+	const namespace = "kube-system"
+
+	var (
+		ctx = context.Background()
+
+		c client.Client
+
+		oldCA, currentCA     *secretsutils.Certificate
+		currentCACertificate *x509.Certificate
+		cache                *api.RegistryCache
+
+		generateCA = func(name string) *secretsutils.Certificate {
+			ca, err := (&secretsutils.CertificateSecretConfig{
+				Name:       name,
+				CommonName: name,
+				CertType:   secretsutils.CACert,
+			}).GenerateCertificate()
+			Expect(err).NotTo(HaveOccurred())
+			return ca
+		}
+		serverCertificateSecret = func(name string, ca *secretsutils.Certificate) *corev1.Secret {
+			certificate, err := (&secretsutils.CertificateSecretConfig{
+				Name:       name,
+				CommonName: name,
+				CertType:   secretsutils.ServerCert,
+				SigningCA:  ca,
+			}).GenerateCertificate()
+			Expect(err).NotTo(HaveOccurred())
+
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Data: map[string][]byte{
+					secretsutils.DataKeyCertificate: certificate.CertificatePEM,
+					secretsutils.DataKeyPrivateKey:  certificate.PrivateKeyPEM,
+				},
+			}
+		}
+		statefulSet = func(port int32, tlsSecretName string) *appsv1.StatefulSet {
+			container := corev1.Container{
+				Name:  "registry-cache",
+				Ports: []corev1.ContainerPort{{Name: "registry-cache", ContainerPort: port}},
+			}
+			statefulSet := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io", Namespace: namespace},
+			}
+			if tlsSecretName != "" {
+				container.VolumeMounts = []corev1.VolumeMount{{Name: "certs", MountPath: "/etc/distribution/certs"}}
+				statefulSet.Spec.Template.Spec.Volumes = []corev1.Volume{{
+					Name:         "certs",
+					VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: tlsSecretName}},
+				}}
+			}
+			statefulSet.Spec.Template.Spec.Containers = []corev1.Container{container}
+			return statefulSet
+		}
+	)
+
+	BeforeEach(func() {
+		c = fakeclient.NewClientBuilder().WithScheme(kubernetes.ShootScheme).Build()
+
+		oldCA = generateCA("ca-old")
+		currentCA = generateCA("ca-current")
+		var err error
+		currentCACertificate, err = utils.DecodeCertificate(currentCA.CertificatePEM)
+		Expect(err).NotTo(HaveOccurred())
+		cache = &api.RegistryCache{Upstream: "docker.io", HTTP: &api.HTTP{TLS: true}}
+	})
+
+	Describe("#IssuedByCA", func() {
+		It("should return true for a certificate issued by the current CA", func() {
+			Expect(IssuedByCA(currentCACertificate, serverCertificateSecret("registry-docker-io-tls", currentCA))).To(BeTrue())
+		})
+
+		It("should return false for a certificate issued by the old CA", func() {
+			Expect(IssuedByCA(currentCACertificate, serverCertificateSecret("registry-docker-io-tls", oldCA))).To(BeFalse())
+		})
+
+		It("should return false without a current CA", func() {
+			Expect(IssuedByCA(nil, serverCertificateSecret("registry-docker-io-tls", currentCA))).To(BeFalse())
+		})
+
+		It("should return false without a Secret", func() {
+			Expect(IssuedByCA(currentCACertificate, nil)).To(BeFalse())
+		})
+
+		It("should return false for a Secret without a valid certificate", func() {
+			Expect(IssuedByCA(currentCACertificate, &corev1.Secret{Data: map[string][]byte{secretsutils.DataKeyCertificate: []byte("foo")}})).To(BeFalse())
+		})
+	})
+
+	Describe("#EndpointChanged", func() {
+		It("should return false when the endpoint is unchanged", func() {
+			Expect(EndpointChanged(ctx, c, currentCACertificate, cache, statefulSet(5000, "registry-docker-io-tls-a"), statefulSet(5000, "registry-docker-io-tls-a"))).To(BeFalse())
+		})
+
+		It("should return true when the port changes", func() {
+			Expect(EndpointChanged(ctx, c, currentCACertificate, cache, statefulSet(5000, "registry-docker-io-tls-a"), statefulSet(6000, "registry-docker-io-tls-a"))).To(BeTrue())
+		})
+
+		It("should return true when TLS is disabled", func() {
+			Expect(EndpointChanged(ctx, c, currentCACertificate, cache, statefulSet(5000, "registry-docker-io-tls-a"), statefulSet(5000, ""))).To(BeTrue())
+		})
+
+		It("should return true when TLS is enabled", func() {
+			Expect(EndpointChanged(ctx, c, currentCACertificate, cache, statefulSet(5000, ""), statefulSet(5000, "registry-docker-io-tls-a"))).To(BeTrue())
+		})
+
+		It("should return false when the renewed certificate replaces a certificate issued by the current CA", func() {
+			Expect(c.Create(ctx, serverCertificateSecret("registry-docker-io-tls-a", currentCA))).To(Succeed())
+
+			Expect(EndpointChanged(ctx, c, currentCACertificate, cache, statefulSet(5000, "registry-docker-io-tls-a"), statefulSet(5000, "registry-docker-io-tls-b"))).To(BeFalse())
+		})
+
+		It("should return true when the renewed certificate replaces a certificate issued by the old CA", func() {
+			Expect(c.Create(ctx, serverCertificateSecret("registry-docker-io-tls-a", oldCA))).To(Succeed())
+
+			Expect(EndpointChanged(ctx, c, currentCACertificate, cache, statefulSet(5000, "registry-docker-io-tls-a"), statefulSet(5000, "registry-docker-io-tls-b"))).To(BeTrue())
+		})
+
+		It("should return true when the deployed certificate Secret does not exist", func() {
+			Expect(EndpointChanged(ctx, c, currentCACertificate, cache, statefulSet(5000, "registry-docker-io-tls-a"), statefulSet(5000, "registry-docker-io-tls-b"))).To(BeTrue())
+		})
+
+		It("should return true when the provided certificate changes", func() {
+			cache.HTTP.TLSSecretReferenceName = ptr.To("ref-docker-tls")
+			Expect(c.Create(ctx, serverCertificateSecret("registry-docker-io-tls-a", currentCA))).To(Succeed())
+
+			Expect(EndpointChanged(ctx, c, currentCACertificate, cache, statefulSet(5000, "registry-docker-io-tls-a"), statefulSet(5000, "registry-docker-io-tls-b"))).To(BeTrue())
+		})
+	})
+
+	Describe("#DeployedReferencedSecrets", func() {
+		It("should return the referenced Secrets with the managed fields only", func() {
+			tlsSecret := serverCertificateSecret("registry-docker-io-tls-a", currentCA)
+			tlsSecret.Labels = map[string]string{"foo": "bar"}
+			tlsSecret.Annotations = map[string]string{"baz": "qux"}
+			Expect(c.Create(ctx, tlsSecret)).To(Succeed())
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io-credentials", Namespace: namespace},
+				Data:       map[string][]byte{"password": []byte("s3cret")},
+			})).To(Succeed())
+
+			deployed := statefulSet(5000, "registry-docker-io-tls-a")
+			deployed.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{
+				Name: "REGISTRY_PROXY_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "registry-docker-io-credentials"},
+					Key:                  "password",
+				}},
+			}}
+
+			Expect(DeployedReferencedSecrets(ctx, c, deployed)).To(ConsistOf(
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io-credentials", Namespace: namespace},
+					Data:       map[string][]byte{"password": []byte("s3cret")},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io-tls-a", Namespace: namespace, Labels: map[string]string{"foo": "bar"}},
+					Data:       tlsSecret.Data,
+				},
+			))
+		})
+
+		It("should skip referenced Secrets which do not exist", func() {
+			Expect(DeployedReferencedSecrets(ctx, c, statefulSet(5000, "registry-docker-io-tls-a"))).To(BeEmpty())
+		})
+	})
+})
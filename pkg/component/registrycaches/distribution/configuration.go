@@ -0,0 +1,264 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package distribution
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Version is the version of the registry configuration.
+const Version = "0.1"
+
+// Configuration is the configuration file (config.yml) of the registry (distribution/distribution).
+// It contains the subset of the configuration options used by the registry caches.
+// See https://distribution.github.io/distribution/about/configuration/ for the documentation of all options.
+type Configuration struct {
+	// Version is the version of the configuration.
+	Version string `json:"version"`
+	// Log configures the logging of the registry.
+	Log Log `json:"log"`
+	// Storage configures the storage backend of the registry.
+	Storage Storage `json:"storage"`
+	// Auth configures the authentication of the clients of the registry.
+	Auth *Auth `json:"auth,omitempty"`
+	// Middleware configures the middlewares of the registry, storage and repository layers by layer name.
+	Middleware map[string][]Middleware `json:"middleware,omitempty"`
+	// HTTP configures the HTTP server of the registry.
+	HTTP HTTP `json:"http"`
+	// Notifications configures the notifications sent by the registry for events.
+	Notifications *Notifications `json:"notifications,omitempty"`
+	// Redis configures the Redis instance used by the registry, e.g. for caching blob descriptors.
+	Redis *Redis `json:"redis,omitempty"`
+	// Health configures the health checks of the registry.
+	Health *Health `json:"health,omitempty"`
+	// Proxy configures the registry as pull-through cache.
+	Proxy *Proxy `json:"proxy,omitempty"`
+}
+
+// Log configures the logging of the registry.
+type Log struct {
+	// AccessLog configures the access log.
+	AccessLog *AccessLog `json:"accesslog,omitempty"`
+	// Level is the log level.
+	Level string `json:"level,omitempty"`
+	// Formatter is the log format.
+	Formatter string `json:"formatter,omitempty"`
+	// Fields are static fields added to each log entry.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// AccessLog configures the access log.
+type AccessLog struct {
+	// Disabled indicates whether the access log is disabled.
+	Disabled bool `json:"disabled"`
+}
+
+// Storage configures the storage backend of the registry.
+type Storage struct {
+	// Filesystem configures the filesystem storage driver.
+	Filesystem *Filesystem `json:"filesystem,omitempty"`
+	// Delete configures the deletion of blobs and manifests.
+	Delete *Delete `json:"delete,omitempty"`
+	// Cache configures the cache of the storage backend.
+	Cache *Cache `json:"cache,omitempty"`
+	// Tag configures the tag lookups.
+	Tag *Tag `json:"tag,omitempty"`
+}
+
+// Filesystem configures the filesystem storage driver.
+type Filesystem struct {
+	// RootDirectory is the directory in which the registry stores its content.
+	RootDirectory string `json:"rootdirectory"`
+}
+
+// Delete configures the deletion of blobs and manifests.
+type Delete struct {
+	// Enabled indicates whether the deletion is enabled.
+	Enabled bool `json:"enabled"`
+}
+
+// Cache configures the cache of the storage backend.
+type Cache struct {
+	// BlobDescriptor is the type of the blob descriptor cache, e.g. 'inmemory' or 'redis'.
+	BlobDescriptor string `json:"blobdescriptor,omitempty"`
+}
+
+// Tag configures the tag lookups.
+type Tag struct {
+	// ConcurrencyLimit is the maximum number of concurrent tag lookups.
+	ConcurrencyLimit int `json:"concurrencylimit,omitempty"`
+}
+
+// Auth configures the authentication of the clients of the registry.
+type Auth struct {
+	// Htpasswd configures the authentication with an htpasswd file.
+	Htpasswd *Htpasswd `json:"htpasswd,omitempty"`
+}
+
+// Htpasswd configures the authentication with an htpasswd file.
+type Htpasswd struct {
+	// Realm is the realm in which the registry server authenticates.
+	Realm string `json:"realm"`
+	// Path is the path to the htpasswd file.
+	Path string `json:"path"`
+}
+
+// Middleware configures a middleware.
+type Middleware struct {
+	// Name is the name of the middleware.
+	Name string `json:"name"`
+	// Disabled indicates whether the middleware is disabled.
+	Disabled bool `json:"disabled,omitempty"`
+	// Options are the options of the middleware.
+	Options map[string]any `json:"options,omitempty"`
+}
+
+// HTTP configures the HTTP server of the registry.
+type HTTP struct {
+	// Addr is the address on which the registry serves requests.
+	Addr string `json:"addr"`
+	// Debug configures the debug server.
+	Debug *Debug `json:"debug,omitempty"`
+	// DrainTimeout is the time to wait for the connections to drain before the registry shuts down.
+	DrainTimeout *metav1.Duration `json:"draintimeout,omitempty"`
+	// TLS configures TLS for the HTTP server.
+	TLS *TLS `json:"tls,omitempty"`
+	// Headers are headers added to each response.
+	Headers map[string][]string `json:"headers,omitempty"`
+}
+
+// Debug configures the debug server.
+type Debug struct {
+	// Addr is the address on which the debug server serves requests.
+	Addr string `json:"addr"`
+	// Prometheus configures the Prometheus metrics endpoint.
+	Prometheus *Prometheus `json:"prometheus,omitempty"`
+}
+
+// Prometheus configures the Prometheus metrics endpoint.
+type Prometheus struct {
+	// Enabled indicates whether the metrics endpoint is enabled.
+	Enabled bool `json:"enabled"`
+	// Path is the path of the metrics endpoint.
+	Path string `json:"path,omitempty"`
+}
+
+// TLS configures TLS for the HTTP server.
+type TLS struct {
+	// Certificate is the path to the server certificate.
+	Certificate string `json:"certificate"`
+	// Key is the path to the key of the server certificate.
+	Key string `json:"key"`
+}
+
+// Notifications configures the notifications sent by the registry for events.
+type Notifications struct {
+	// Events configures the content of the events.
+	Events *Events `json:"events,omitempty"`
+	// Endpoints are the endpoints to which the events are sent.
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+}
+
+// Events configures the content of the events.
+type Events struct {
+	// IncludeReferences indicates whether the references of a manifest are included in the events.
+	IncludeReferences bool `json:"includereferences,omitempty"`
+}
+
+// Endpoint is an endpoint to which the events are sent.
+type Endpoint struct {
+	// Name is the name of the endpoint.
+	Name string `json:"name"`
+	// Disabled indicates whether the endpoint is disabled.
+	Disabled bool `json:"disabled,omitempty"`
+	// URL is the URL to which the events are posted.
+	URL string `json:"url"`
+	// Headers are headers added to each request.
+	Headers map[string][]string `json:"headers,omitempty"`
+	// Timeout is the timeout of a request.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Threshold is the number of failed requests after which the endpoint is backed off.
+	Threshold int `json:"threshold,omitempty"`
+	// Backoff is the time to wait before a request is retried after a failure.
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+	// IgnoredMediaTypes are the media types of the targets for which no events are sent.
+	IgnoredMediaTypes []string `json:"ignoredmediatypes,omitempty"`
+	// Ignore configures the events which are not sent.
+	Ignore *Ignore `json:"ignore,omitempty"`
+}
+
+// Ignore configures the events which are not sent.
+type Ignore struct {
+	// MediaTypes are the media types of the targets for which no events are sent.
+	MediaTypes []string `json:"mediatypes,omitempty"`
+	// Actions are the actions for which no events are sent.
+	Actions []string `json:"actions,omitempty"`
+}
+
+// Redis configures the Redis instance used by the registry.
+type Redis struct {
+	// Addrs are the addresses (`<host>:<port>`) of the Redis instance.
+	Addrs []string `json:"addrs"`
+	// Username is the username to authenticate with.
+	Username string `json:"username,omitempty"`
+	// Password is the password to authenticate with.
+	Password string `json:"password,omitempty"`
+	// DB is the number of the database.
+	DB int `json:"db,omitempty"`
+	// DialTimeout is the timeout for establishing a connection.
+	DialTimeout *metav1.Duration `json:"dialtimeout,omitempty"`
+	// ReadTimeout is the timeout for reading from a connection.
+	ReadTimeout *metav1.Duration `json:"readtimeout,omitempty"`
+	// WriteTimeout is the timeout for writing to a connection.
+	WriteTimeout *metav1.Duration `json:"writetimeout,omitempty"`
+	// PoolSize is the maximum number of connections.
+	PoolSize int `json:"poolsize,omitempty"`
+	// TLS configures TLS for the connections.
+	TLS *RedisTLS `json:"tls,omitempty"`
+}
+
+// RedisTLS configures TLS for the connections to the Redis instance.
+type RedisTLS struct {
+	// Certificate is the path to the client certificate.
+	Certificate string `json:"certificate,omitempty"`
+	// Key is the path to the key of the client certificate.
+	Key string `json:"key,omitempty"`
+	// ClientCAs are the paths to the CA bundles used to verify the server certificate.
+	ClientCAs []string `json:"clientcas,omitempty"`
+}
+
+// Health configures the health checks of the registry.
+type Health struct {
+	// StorageDriver configures the health check of the storage driver.
+	StorageDriver *StorageDriverHealth `json:"storagedriver,omitempty"`
+}
+
+// StorageDriverHealth configures the health check of the storage driver.
+type StorageDriverHealth struct {
+	// Enabled indicates whether the health check is enabled.
+	Enabled bool `json:"enabled"`
+	// Interval is the interval of the health check.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Threshold is the number of failed health checks after which the registry is unhealthy.
+	Threshold int `json:"threshold,omitempty"`
+}
+
+// Proxy configures the registry as pull-through cache.
+type Proxy struct {
+	// RemoteURL is the URL of the remote registry.
+	RemoteURL string `json:"remoteurl"`
+	// Username is the username to authenticate against the remote registry.
+	Username string `json:"username,omitempty"`
+	// Password is the password to authenticate against the remote registry.
+	Password string `json:"password,omitempty"`
+	// TTL is the time to live of the cached content. A TTL of 0s disables the expiration.
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// Marshal returns the YAML encoding of the given configuration.
+func Marshal(config *Configuration) ([]byte, error) {
+	return yaml.Marshal(config)
+}
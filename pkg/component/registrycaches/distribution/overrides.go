@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package distribution
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"sigs.k8s.io/yaml"
+)
+
+// ApplyOverrides merges the given overrides (YAML or JSON) into the given configuration file and returns the YAML
+// encoding of the result. Objects are merged recursively, a null value removes an option and any other value replaces
+// the existing one, see JSON Merge Patch (https://datatracker.ietf.org/doc/html/rfc7386).
+func ApplyOverrides(config, overrides []byte) ([]byte, error) {
+	configJSON, err := yaml.YAMLToJSON(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert configuration to JSON: %w", err)
+	}
+
+	overridesJSON, err := yaml.YAMLToJSON(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert configuration overrides to JSON: %w", err)
+	}
+
+	// A merge patch which is not an object replaces the whole configuration.
+	var overridesObject map[string]any
+	if err := json.Unmarshal(overridesJSON, &overridesObject); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configuration overrides: %w", err)
+	}
+	if overridesObject == nil {
+		return nil, fmt.Errorf("configuration overrides must be an object")
+	}
+
+	merged, err := jsonpatch.MergePatch(configJSON, overridesJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply configuration overrides: %w", err)
+	}
+
+	return yaml.JSONToYAML(merged)
+}
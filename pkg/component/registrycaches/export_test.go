@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registrycaches
+
+import (
+	"context"
+	"crypto/x509"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+)
+
+// EndpointChanged exports endpointChanged for testing.
+func EndpointChanged(ctx context.Context, shootClient client.Reader, caCertificate *x509.Certificate, cache *api.RegistryCache, deployed, desired *appsv1.StatefulSet) (bool, error) {
+	return (&registryCaches{shootClient: shootClient, caCertificate: caCertificate}).endpointChanged(ctx, cache, deployed, desired)
+}
+
+// IssuedByCA exports issuedByCA for testing.
+func IssuedByCA(caCertificate *x509.Certificate, secret *corev1.Secret) bool {
+	return (&registryCaches{caCertificate: caCertificate}).issuedByCA(secret)
+}
+
+// DeployedReferencedSecrets exports deployedReferencedSecrets for testing.
+func DeployedReferencedSecrets(ctx context.Context, shootClient client.Reader, deployed *appsv1.StatefulSet) ([]client.Object, error) {
+	return (&registryCaches{shootClient: shootClient}).deployedReferencedSecrets(ctx, deployed)
+}
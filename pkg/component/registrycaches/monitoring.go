@@ -7,19 +7,24 @@ package registrycaches
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"strconv"
 
 	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
 	kubeapiserverconstants "github.com/gardener/gardener/pkg/component/kubernetes/apiserver/constants"
 	monitoringutils "github.com/gardener/gardener/pkg/component/observability/monitoring/utils"
 	"github.com/gardener/gardener/pkg/controllerutils"
+	"github.com/gardener/gardener/pkg/utils"
 	kubernetesutils "github.com/gardener/gardener/pkg/utils/kubernetes"
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	monitoringv1alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
 )
 
 var (
@@ -27,7 +32,7 @@ var (
 	dashboard string
 )
 
-func (r *registryCaches) deployMonitoringConfig(ctx context.Context) error {
+func (r *registryCaches) deployMonitoringConfig(ctx context.Context, certificateExpirationRules []monitoringv1.Rule) error {
 	dashboardsConfigMap := r.emptyDashboardsConfigMap()
 	if _, err := controllerutils.GetAndCreateOrMergePatch(ctx, r.client, dashboardsConfigMap, func() error {
 		metav1.SetMetaDataLabel(&dashboardsConfigMap.ObjectMeta, "component", "registry-cache")
@@ -45,7 +50,7 @@ func (r *registryCaches) deployMonitoringConfig(ctx context.Context) error {
 		prometheusRule.Spec = monitoringv1.PrometheusRuleSpec{
 			Groups: []monitoringv1.RuleGroup{{
 				Name: "registry-cache.rules",
-				Rules: []monitoringv1.Rule{
+				Rules: append([]monitoringv1.Rule{
 					{
 						Alert: "RegistryCachePersistentVolumeUsageCritical",
 						Expr: intstr.FromString(`100 * (
@@ -86,6 +91,111 @@ predict_linear(kubelet_volume_stats_available_bytes{persistentvolumeclaim=~"^cac
 							"summary":     "Registry cache PersistentVolume will be full in four days.",
 						},
 					},
+					{
+						Alert: "RegistryCacheStrictNotAvailable",
+						Expr:  intstr.FromString(r.strictNotAvailableExpr()),
+						For:   ptr.To(monitoringv1.Duration("5m")),
+						Labels: map[string]string{
+							"service":    "registry-cache-extension",
+							"severity":   "critical",
+							"type":       "shoot",
+							"visibility": "owner",
+						},
+						Annotations: map[string]string{
+							"description": "The strict registry cache for upstream {{ $labels.upstream_host }} is not available. containerd does not fall back to the upstream registry for strict registry caches, hence image pulls from the upstream fail on the Nodes.",
+							"summary":     "Strict registry cache not available.",
+						},
+					},
+					{
+						Alert: "RegistryCacheNotReady",
+						Expr: intstr.FromString(`kube_pod_status_ready{condition="true", type="shoot", namespace="kube-system"} == 0
+and on (pod)
+kube_pod_container_info{type="shoot", namespace="kube-system", container="registry-cache"}`),
+						For: ptr.To(monitoringv1.Duration("15m")),
+						Labels: map[string]string{
+							"service":    "registry-cache-extension",
+							"severity":   "warning",
+							"type":       "shoot",
+							"visibility": "owner",
+						},
+						Annotations: map[string]string{
+							"description": "The registry cache Pod {{ $labels.pod }} is not ready for more than 15 minutes. Image pulls fall back to the upstream registry, or fail for a strict registry cache.",
+							"summary":     "Registry cache not ready.",
+						},
+					},
+					{
+						Alert: "RegistryCacheUpstreamAuthenticationFailing",
+						Expr:  intstr.FromString(`sum by (upstream_host) (registry_cache:registry_cache_upstream_limiter_upstream_errors:rate5m{code=~"401|403"}) > 0`),
+						For:   ptr.To(monitoringv1.Duration("15m")),
+						Labels: map[string]string{
+							"service":    "registry-cache-extension",
+							"severity":   "warning",
+							"type":       "shoot",
+							"visibility": "owner",
+						},
+						Annotations: map[string]string{
+							"description": "The upstream {{ $labels.upstream_host }} responds to the requests of the registry cache with 401 (Unauthorized) or 403 (Forbidden). Check the upstream registry credentials referenced by secretReferenceName.",
+							"summary":     "Registry cache authentication against the upstream failing.",
+						},
+					},
+					{
+						Alert: "RegistryCacheUpstreamRateLimited",
+						Expr:  intstr.FromString(`sum by (upstream_host) (registry_cache:registry_cache_upstream_limiter_upstream_errors:rate5m{code="429"}) > 0`),
+						For:   ptr.To(monitoringv1.Duration("15m")),
+						Labels: map[string]string{
+							"service":    "registry-cache-extension",
+							"severity":   "warning",
+							"type":       "shoot",
+							"visibility": "owner",
+						},
+						Annotations: map[string]string{
+							"description": "The upstream {{ $labels.upstream_host }} responds to the requests of the registry cache with 429 (Too Many Requests). Image pulls of content which is not cached yet fail or fall back to the upstream.",
+							"summary":     "Registry cache rate limited by the upstream.",
+						},
+					},
+					{
+						Alert: "RegistryCacheCertificateExpiresSoon",
+						Expr:  intstr.FromString(`registry_cache:certificate_expiration_timestamp_seconds - time() < 7 * 24 * 3600`),
+						For:   ptr.To(monitoringv1.Duration("15m")),
+						Labels: map[string]string{
+							"service":    "registry-cache-extension",
+							"severity":   "warning",
+							"type":       "shoot",
+							"visibility": "owner",
+						},
+						Annotations: map[string]string{
+							"description": "The {{ $labels.certificate }} certificate of the registry caches{{ with $labels.upstream_host }} for upstream {{ . }}{{ end }} expires in {{ $value | humanizeDuration }}. The certificates are renewed with the reconciliation of the Shoot. containerd falls back to the upstream registry when the certificate is expired, image pulls fail for a strict registry cache.",
+							"summary":     "Registry cache certificate expires soon.",
+						},
+					},
+					// The following recording rules provide per-upstream breakdowns. They are intentionally not in format
+					// "shoot:(.+):(.+)" to not federate them.
+					{
+						Record: "registry_cache:registry_proxy_hits:ratio_rate5m",
+						Expr: intstr.FromString(`sum by (upstream_host, type) (rate(registry_proxy_hits_total[5m]))
+/
+sum by (upstream_host, type) (rate(registry_proxy_requests_total[5m]))`),
+					},
+					{
+						Record: "registry_cache:registry_cache_upstream_limiter_upstream_errors:rate5m",
+						Expr:   intstr.FromString(`sum by (upstream_host, code) (rate(registry_cache_upstream_limiter_upstream_responses_total{code=~"401|403|429|5.."}[5m]))`),
+					},
+					{
+						Record: "registry_cache:registry_http_request_duration_seconds:p99_rate5m",
+						Expr:   intstr.FromString(`histogram_quantile(0.99, sum by (upstream_host, le) (rate(registry_http_request_duration_seconds_bucket[5m])))`),
+					},
+					{
+						Record: "registry_cache:registry_cache_upstream_limiter_throttled_requests:rate5m",
+						Expr:   intstr.FromString(`sum by (upstream_host, limit) (rate(registry_cache_upstream_limiter_throttled_requests_total[5m]))`),
+					},
+					{
+						Record: "registry_cache:kube_pod_container_status_restarts:increase1h",
+						Expr: intstr.FromString(`sum by (upstream_host) (
+  increase(kube_pod_container_status_restarts_total{type="shoot", namespace="kube-system", container="registry-cache"}[1h])
+  * on (pod) group_left (upstream_host)
+  group by (pod, upstream_host) (up{job="registry-cache-metrics"})
+)`),
+					},
 					// We rely on the implicit contract that recording rules in format "shoot:(.+):(.+)" will be
 					// automatically federated to the aggregate prometheus and then to the garden-prometheus.
 					// Ref https://github.com/gardener/gardener/blob/v1.90.0/pkg/component/observability/monitoring/prometheus/aggregate/servicemonitors.go#L45
@@ -97,7 +207,7 @@ predict_linear(kubelet_volume_stats_available_bytes{persistentvolumeclaim=~"^cac
 						Record: "shoot:registry_proxy_pulled_bytes_total:sum",
 						Expr:   intstr.FromString("sum by (upstream_host) (rate(registry_proxy_pulled_bytes_total[5m]))"),
 					},
-				},
+				}, certificateExpirationRules...),
 			}},
 		}
 		return nil
@@ -137,15 +247,22 @@ predict_linear(kubelet_volume_stats_available_bytes{persistentvolumeclaim=~"^cac
 					Replacement: ptr.To("registry-cache-metrics"),
 					TargetLabel: "job",
 				},
+				// The debug port and the metrics port of the upstream limiter are selected by their names and the metrics
+				// path is built from the container port number, hence custom debug ports (`http.debugPort`) are scraped
+				// without further configuration.
 				{
 					SourceLabels: []monitoringv1.LabelName{"__meta_kubernetes_pod_label_upstream_host", "__meta_kubernetes_pod_container_port_name"},
 					Action:       "keep",
-					Regex:        `(.+);debug`,
+					Regex:        `(.+);(debug|limiter-metrics)`,
 				},
 				{
 					Action: "labelmap",
 					Regex:  `__meta_kubernetes_pod_label_(.+)`,
 				},
+				{
+					SourceLabels: []monitoringv1.LabelName{"__meta_kubernetes_pod_name"},
+					TargetLabel:  "pod",
+				},
 				{
 					TargetLabel: "__address__",
 					Action:      "replace",
@@ -159,7 +276,12 @@ predict_linear(kubelet_volume_stats_available_bytes{persistentvolumeclaim=~"^cac
 					Replacement:  ptr.To("/api/v1/namespaces/kube-system/pods/${1}:${2}/proxy/metrics"),
 				},
 			},
-			MetricRelabelConfigs: monitoringutils.StandardMetricRelabelConfig("registry_proxy_.+"),
+			MetricRelabelConfigs: monitoringutils.StandardMetricRelabelConfig(
+				"registry_proxy_.+",
+				"registry_http_requests_total",
+				"registry_http_request_duration_seconds_bucket",
+				"registry_cache_upstream_limiter_.+",
+			),
 		}
 		return nil
 	}); err != nil {
@@ -169,6 +291,76 @@ predict_linear(kubelet_volume_stats_available_bytes{persistentvolumeclaim=~"^cac
 	return nil
 }
 
+// strictNotAvailableExpr returns the expression of the RegistryCacheStrictNotAvailable alert. A strict registry cache
+// without scrape target, e.g. because its Pod is not running, does not have an 'up' series and is detected by the absent
+// clause for its upstream.
+func (r *registryCaches) strictNotAvailableExpr() string {
+	expr := `up{job="registry-cache-metrics", strict_mode="true"} == 0`
+	for _, cache := range r.values.Caches {
+		if !ptr.Deref(cache.Strict, false) {
+			continue
+		}
+
+		expr += fmt.Sprintf(`
+or
+absent(up{job="registry-cache-metrics", upstream_host=%q})`, registryutils.ComputeUpstreamLabelValue(cache.Upstream))
+	}
+
+	return expr
+}
+
+// computeCertificateExpirationRules returns recording rules which expose the expiration times of the CA and server
+// certificates of the registry caches. The rules are updated with every reconciliation, hence a certificate which is
+// not renewed in time because the Extension is not reconciled is detected by the RegistryCacheCertificateExpiresSoon alert.
+func (r *registryCaches) computeCertificateExpirationRules(caSecret *corev1.Secret, tlsSecrets map[string]*corev1.Secret) ([]monitoringv1.Rule, error) {
+	var rules []monitoringv1.Rule
+
+	if caSecret != nil {
+		caRule, err := certificateExpirationRule(caSecret, map[string]string{"certificate": "ca"})
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, caRule)
+	}
+
+	for _, cache := range r.values.Caches {
+		tlsSecret, ok := tlsSecrets[cache.Upstream]
+		if !ok {
+			continue
+		}
+
+		rule, err := certificateExpirationRule(tlsSecret, map[string]string{
+			"certificate":   "server",
+			"upstream_host": registryutils.ComputeUpstreamLabelValue(cache.Upstream),
+		})
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func certificateExpirationRule(secret *corev1.Secret, labels map[string]string) (monitoringv1.Rule, error) {
+	// CA certificates are stored with a different data key than certificates signed by a CA.
+	certificatePEM, ok := secret.Data[secretsutils.DataKeyCertificate]
+	if !ok {
+		certificatePEM = secret.Data[secretsutils.DataKeyCertificateCA]
+	}
+
+	certificate, err := utils.DecodeCertificate(certificatePEM)
+	if err != nil {
+		return monitoringv1.Rule{}, fmt.Errorf("failed to decode certificate of secret %s: %w", secret.Name, err)
+	}
+
+	return monitoringv1.Rule{
+		Record: "registry_cache:certificate_expiration_timestamp_seconds",
+		Expr:   intstr.FromString(fmt.Sprintf("vector(%d)", certificate.NotAfter.Unix())),
+		Labels: labels,
+	}, nil
+}
+
 func (r *registryCaches) destroyMonitoringConfig(ctx context.Context) error {
 	return kubernetesutils.DeleteObjects(ctx, r.client,
 		r.emptyDashboardsConfigMap(),
@@ -5,11 +5,13 @@
 package registrycaches
 
 import (
-	"bytes"
 	"context"
-	_ "embed"
+	"crypto/x509"
 	"fmt"
-	"text/template"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gardener/gardener/extensions/pkg/controller"
@@ -24,17 +26,17 @@ import (
 	kubernetesutils "github.com/gardener/gardener/pkg/utils/kubernetes"
 	"github.com/gardener/gardener/pkg/utils/managedresources"
 	secretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager"
-	appsv1 "k8s.io/api/apps/v1"
-	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	vpaautoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/helper"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
@@ -43,43 +45,49 @@ import (
 )
 
 const (
-	managedResourceName = "extension-registry-cache"
+	managedResourceName          = "extension-registry-cache"
+	redisContainerName           = "redis"
+	upstreamLimiterContainerName = "upstream-limiter"
 )
 
-var (
-	//go:embed templates/config.yml.tpl
-	configContentTpl string
-	configTpl        *template.Template
-)
-
-func init() {
-	var err error
-	configTpl, err = template.
-		New("config.yml.tpl").
-		Parse(configContentTpl)
-	utilruntime.Must(err)
-}
-
 // Interface is an interface for managing Registry Caches.
 type Interface interface {
 	component.DeployWaiter
 	// CASecretName returns the name of the CA secret.
 	// Returns nil when there is no registry cache that enables TLS for the HTTP server.
 	CASecretName() *string
+	// UpstreamsWithPendingDisruptiveChanges returns the upstreams of the registry caches with deferred disruptive changes.
+	UpstreamsWithPendingDisruptiveChanges() []string
 }
 
 // Values is a set of configuration values for the registry caches.
 type Values struct {
 	// Image is the container image used for the registry cache.
 	Image string
+	// RedisImage is the container image used for the blob descriptor cache of the registry caches.
+	RedisImage string
+	// UpstreamLimiterImage is the container image used for limiting the requests of the registry caches to the upstream.
+	UpstreamLimiterImage string
 	// VPAEnabled marks whether VerticalPodAutoscaler is enabled for the shoot.
 	VPAEnabled bool
 	// Services are the registry cache services used for certificate generation.
 	Services []corev1.Service
+	// Certificates contains settings for the certificates of the registry caches.
+	Certificates *config.Certificates
 	// Caches are the registry caches to deploy.
 	Caches []api.RegistryCache
 	// ResourceReferences are the resource references from the Shoot spec (the .spec.resources field).
 	ResourceReferences []gardencorev1beta1.NamedResourceReference
+	// SharedCacheEndpoints are the endpoints of the shared registry caches in the seed by upstream.
+	// A registry cache for an upstream with a shared registry cache uses it as remote URL, unless
+	// the registry cache specifies a custom remote URL or upstream credentials.
+	SharedCacheEndpoints map[string]string
+	// SharedCacheCABundle is the CA bundle used to verify the endpoints of the shared registry caches.
+	SharedCacheCABundle []byte
+	// DeferDisruptiveChanges marks whether disruptive changes of the deployed registry caches, i.e. changes of the Pod
+	// template which restart the registry cache Pod, are deferred. The deployed Pod template is kept for deferred changes.
+	// Changes of the endpoint of a registry cache are never deferred.
+	DeferDisruptiveChanges bool
 	// KeepObjectsOnDestroy marks whether the ManagedResource's .spec.keepObjects will be set to true
 	// before ManagedResource deletion during the Destroy operation. When set to true, the deployed
 	// resources by ManagedResources won't be deleted, but the ManagedResource itself will be deleted.
@@ -89,12 +97,14 @@ type Values struct {
 // New creates a new instance of Interface for registry caches.
 func New(
 	client client.Client,
+	shootClient client.Reader,
 	namespace string,
 	secretManager secretsmanager.Interface,
 	values Values,
 ) Interface {
 	return &registryCaches{
 		client:        client,
+		shootClient:   shootClient,
 		namespace:     namespace,
 		secretManager: secretManager,
 		values:        values,
@@ -103,20 +113,24 @@ func New(
 
 type registryCaches struct {
 	client        client.Client
+	shootClient   client.Reader
 	namespace     string
 	secretManager secretsmanager.Interface
 	values        Values
 
-	caSecretName *string
+	caSecretName                          *string
+	caCertificate                         *x509.Certificate
+	upstreamsWithPendingDisruptiveChanges []string
 }
 
 // Deploy implements component.DeployWaiter.
 func (r *registryCaches) Deploy(ctx context.Context) error {
-	secretConfigs := secrets.ConfigsFor(r.values.Services)
+	secretConfigs := secrets.ConfigsFor(r.servicesWithGeneratedCertificates(), r.values.Certificates)
 
 	var generatedSecrets map[string]*corev1.Secret
 	if len(secretConfigs) > 1 {
-		// There is at least one cache with TLS enabled. Hence, we need to generate all secrets.
+		// There is at least one cache with TLS enabled which does not provide its own certificate. Hence, we need to
+		// generate all secrets.
 		var err error
 		generatedSecrets, err = extensionssecretsmanager.GenerateAllSecrets(ctx, r.secretManager, secretConfigs)
 		if err != nil {
@@ -128,9 +142,24 @@ func (r *registryCaches) Deploy(ctx context.Context) error {
 			return fmt.Errorf("secret %q not found", secrets.CAName)
 		}
 		r.caSecretName = &caSecret.Name
+
+		r.caCertificate, err = parseCACertificate(generatedSecrets[secrets.CAName])
+		if err != nil {
+			return fmt.Errorf("failed to decode CA certificate: %w", err)
+		}
 	}
 
-	data, err := r.computeResourcesData(ctx, generatedSecrets)
+	tlsSecrets, err := r.computeTLSSecrets(ctx, generatedSecrets)
+	if err != nil {
+		return err
+	}
+
+	certificateExpirationRules, err := r.computeCertificateExpirationRules(generatedSecrets[secrets.CAName], tlsSecrets)
+	if err != nil {
+		return fmt.Errorf("failed to compute certificate expiration rules: %w", err)
+	}
+
+	data, err := r.computeResourcesData(ctx, tlsSecrets)
 	if err != nil {
 		return err
 	}
@@ -151,7 +180,7 @@ func (r *registryCaches) Deploy(ctx context.Context) error {
 		return fmt.Errorf("failed to create or update managed resource: %w", err)
 	}
 
-	if err := r.deployMonitoringConfig(ctx); err != nil {
+	if err := r.deployMonitoringConfig(ctx, certificateExpirationRules); err != nil {
 		return fmt.Errorf("failed to deploy monitoring config: %w", err)
 	}
 
@@ -201,22 +230,79 @@ func (r *registryCaches) CASecretName() *string {
 	return r.caSecretName
 }
 
-func (r *registryCaches) computeResourcesData(ctx context.Context, generatedSecrets map[string]*corev1.Secret) (map[string][]byte, error) {
-	var objects []client.Object
+func (r *registryCaches) UpstreamsWithPendingDisruptiveChanges() []string {
+	return r.upstreamsWithPendingDisruptiveChanges
+}
+
+// servicesWithGeneratedCertificates returns the Services of the registry caches which do not provide their own
+// certificate. Server certificates are generated only for these Services.
+func (r *registryCaches) servicesWithGeneratedCertificates() []corev1.Service {
+	var services []corev1.Service
+	for _, service := range r.values.Services {
+		ok, cache := helper.FindCacheByUpstream(r.values.Caches, service.Annotations[constants.UpstreamAnnotation])
+		if ok && helper.TLSCertificateProvided(&cache) {
+			continue
+		}
+		services = append(services, service)
+	}
+
+	return services
+}
+
+// computeTLSSecrets returns the Secrets containing the server certificates of the registry caches with TLS enabled by
+// upstream. The Secret is either generated by the secrets manager or referenced by the registry cache.
+func (r *registryCaches) computeTLSSecrets(ctx context.Context, generatedSecrets map[string]*corev1.Secret) (map[string]*corev1.Secret, error) {
+	tlsSecrets := make(map[string]*corev1.Secret)
 
 	for _, cache := range r.values.Caches {
-		var generatedTLSSecret *corev1.Secret
-		if helper.TLSEnabled(&cache) {
-			tlsSecretName := secrets.TLSSecretNameForUpstream(cache.Upstream)
-
-			var ok bool
-			generatedTLSSecret, ok = generatedSecrets[tlsSecretName]
-			if !ok {
-				return nil, fmt.Errorf("secret for upstream %s not found", cache.Upstream)
+		if !helper.TLSEnabled(&cache) {
+			continue
+		}
+
+		if helper.TLSCertificateProvided(&cache) {
+			refSecret, err := r.getReferencedSecret(ctx, *cache.HTTP.TLSSecretReferenceName)
+			if err != nil {
+				return nil, err
 			}
+			tlsSecrets[cache.Upstream] = refSecret
+			continue
 		}
 
-		cacheObjects, err := r.computeResourcesDataForRegistryCache(ctx, &cache, generatedTLSSecret)
+		generatedTLSSecret, ok := generatedSecrets[secrets.TLSSecretNameForUpstream(cache.Upstream)]
+		if !ok {
+			return nil, fmt.Errorf("secret for upstream %s not found", cache.Upstream)
+		}
+		tlsSecrets[cache.Upstream] = generatedTLSSecret
+	}
+
+	return tlsSecrets, nil
+}
+
+// getReferencedSecret returns the Secret in the Shoot namespace for the given resource reference name.
+func (r *registryCaches) getReferencedSecret(ctx context.Context, referenceName string) (*corev1.Secret, error) {
+	ref := v1beta1helper.GetResourceByName(r.values.ResourceReferences, referenceName)
+	if ref == nil || ref.ResourceRef.Kind != "Secret" {
+		return nil, fmt.Errorf("failed to find referenced resource with name %s and kind Secret", referenceName)
+	}
+
+	refSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.ResourceRef.Name,
+			Namespace: r.namespace,
+		},
+	}
+	if err := controller.GetObjectByReference(ctx, r.client, &ref.ResourceRef, r.namespace, refSecret); err != nil {
+		return nil, fmt.Errorf("failed to read referenced secret %s%s for reference %s", v1beta1constants.ReferencedResourcesPrefix, ref.ResourceRef.Name, referenceName)
+	}
+
+	return refSecret, nil
+}
+
+func (r *registryCaches) computeResourcesData(ctx context.Context, tlsSecrets map[string]*corev1.Secret) (map[string][]byte, error) {
+	var objects []client.Object
+
+	for _, cache := range r.values.Caches {
+		cacheObjects, err := r.computeResourcesDataForRegistryCache(ctx, &cache, tlsSecrets[cache.Upstream])
 		if err != nil {
 			return nil, fmt.Errorf("failed to compute resources for upstream %s: %w", cache.Upstream, err)
 		}
@@ -229,30 +315,22 @@ func (r *registryCaches) computeResourcesData(ctx context.Context, generatedSecr
 	return registry.AddAllAndSerialize(objects...)
 }
 
-func (r *registryCaches) computeResourcesDataForRegistryCache(ctx context.Context, cache *api.RegistryCache, generatedTLSSecret *corev1.Secret) ([]client.Object, error) {
+func (r *registryCaches) computeResourcesDataForRegistryCache(ctx context.Context, cache *api.RegistryCache, serverTLSSecret *corev1.Secret) ([]client.Object, error) {
 	if cache.Volume == nil || cache.Volume.Size == nil {
 		return nil, fmt.Errorf("registry cache volume size is required")
 	}
 
 	const (
-		registryCacheVolumeName  = "cache-volume"
-		registryConfigVolumeName = "config-volume"
-		registryCertsVolumeName  = "certs-volume"
-		repositoryMountPath      = "/var/lib/registry"
-		debugPort                = 5001
+		sharedCAVolumeName = "shared-ca-volume"
+		sharedCAMountPath  = "/etc/distribution/shared-ca"
 	)
 
 	var (
 		upstreamLabel = registryutils.ComputeUpstreamLabelValue(cache.Upstream)
 		name          = registryutils.ComputeKubernetesResourceName(cache.Upstream)
 		remoteURL     = ptr.Deref(cache.RemoteURL, registryutils.GetUpstreamURL(cache.Upstream))
-		configValues  = map[string]interface{}{
-			"http_addr":       fmt.Sprintf(":%d", constants.RegistryCachePort),
-			"http_debug_addr": fmt.Sprintf(":%d", debugPort),
-			"proxy_remoteurl": remoteURL,
-			"proxy_ttl":       helper.GarbageCollectionTTL(cache).Duration.String(),
-			"http_tls":        helper.TLSEnabled(cache),
-		}
+		port          = helper.Port(cache)
+		debugPort     = helper.DebugPort(cache)
 	)
 
 	var storageClassName *string
@@ -260,28 +338,53 @@ func (r *registryCaches) computeResourcesDataForRegistryCache(ctx context.Contex
 		storageClassName = cache.Volume.StorageClassName
 	}
 
+	var username, password string
 	if cache.SecretReferenceName != nil {
-		ref := v1beta1helper.GetResourceByName(r.values.ResourceReferences, *cache.SecretReferenceName)
-		if ref == nil || ref.ResourceRef.Kind != "Secret" {
-			return nil, fmt.Errorf("failed to find referenced resource with name %s and kind Secret", *cache.SecretReferenceName)
+		refSecret, err := r.getReferencedSecret(ctx, *cache.SecretReferenceName)
+		if err != nil {
+			return nil, err
 		}
 
-		refSecret := &corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      ref.ResourceRef.Name,
-				Namespace: r.namespace,
-			},
-		}
-		if err := controller.GetObjectByReference(ctx, r.client, &ref.ResourceRef, r.namespace, refSecret); err != nil {
-			return nil, fmt.Errorf("failed to read referenced secret %s%s for reference %s", v1beta1constants.ReferencedResourcesPrefix, ref.ResourceRef.Name, *cache.SecretReferenceName)
-		}
+		username = string(refSecret.Data["username"])
+		password = string(refSecret.Data["password"])
+	}
 
-		configValues["proxy_username"] = string(refSecret.Data["username"])
-		configValues["proxy_password"] = string(refSecret.Data["password"])
+	distributionRemoteURL := registryutils.DistributionRemoteURL(remoteURL)
+	// containerd falls back to the upstream itself when a registry cache using the shared registry cache fails to pull
+	// an image. Strict registry caches do not have this fallback, hence they do not depend on the shared registry cache.
+	sharedCacheEndpoint, useSharedCache := r.values.SharedCacheEndpoints[cache.Upstream]
+	useSharedCache = useSharedCache && cache.RemoteURL == nil && cache.SecretReferenceName == nil && !ptr.Deref(cache.Strict, false)
+	if useSharedCache {
+		distributionRemoteURL = sharedCacheEndpoint
 	}
 
-	var configYAML bytes.Buffer
-	if err := configTpl.Execute(&configYAML, configValues); err != nil {
+	var notificationHeaders map[string]map[string][]byte
+	if cache.Notifications != nil {
+		for _, endpoint := range cache.Notifications.Endpoints {
+			if endpoint.HeadersSecretReferenceName == nil {
+				continue
+			}
+
+			refSecret, err := r.getReferencedSecret(ctx, *endpoint.HeadersSecretReferenceName)
+			if err != nil {
+				return nil, err
+			}
+
+			if notificationHeaders == nil {
+				notificationHeaders = make(map[string]map[string][]byte)
+			}
+			notificationHeaders[endpoint.Name] = refSecret.Data
+		}
+	}
+
+	// The upstream limiter proxies the requests of the registry cache to the upstream, hence the registry cache uses the
+	// upstream limiter as remote URL. The upstream limiter also runs without upstream rate limits as it exposes the status
+	// codes of the upstream responses.
+	upstreamLimiterURL := distributionRemoteURL
+	distributionRemoteURL = "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(constants.UpstreamLimiterPort))
+
+	configYAML, err := RenderConfig(cache, distributionRemoteURL, username, password, notificationHeaders)
+	if err != nil {
 		return nil, err
 	}
 
@@ -292,176 +395,100 @@ func (r *registryCaches) computeResourcesDataForRegistryCache(ctx context.Contex
 			Labels:    registryutils.GetLabels(name, upstreamLabel),
 		},
 		Data: map[string][]byte{
-			"config.yml": configYAML.Bytes(),
+			"config.yml": configYAML,
 		},
 	}
 	utilruntime.Must(kubernetesutils.MakeUnique(configSecret))
 
-	statefulSet := &appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: metav1.NamespaceSystem,
-			Labels:    registryutils.GetLabels(name, upstreamLabel),
+	// Mitigation for https://github.com/distribution/distribution/issues/4270:
+	// The export of traces is disabled unless tracing is configured for the registry cache.
+	env := []corev1.EnvVar{
+		{
+			Name:  "OTEL_TRACES_EXPORTER",
+			Value: "none",
 		},
-		Spec: appsv1.StatefulSetSpec{
-			ServiceName: name,
-			Selector: &metav1.LabelSelector{
-				MatchLabels: registryutils.GetLabels(name, upstreamLabel),
-			},
-			Replicas: ptr.To[int32](1),
-			Template: corev1.PodTemplateSpec{
+	}
+
+	var tracingHeadersSecret *corev1.Secret
+	if cache.Tracing != nil {
+		env = tracingEnv(name, cache.Tracing)
+
+		if cache.Tracing.HeadersSecretReferenceName != nil {
+			refSecret, err := r.getReferencedSecret(ctx, *cache.Tracing.HeadersSecretReferenceName)
+			if err != nil {
+				return nil, err
+			}
+
+			tracingHeadersSecret = &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: utils.MergeStringMaps(registryutils.GetLabels(name, upstreamLabel), map[string]string{
-						v1beta1constants.LabelNetworkPolicyToDNS:            v1beta1constants.LabelNetworkPolicyAllowed,
-						v1beta1constants.LabelNetworkPolicyToPublicNetworks: v1beta1constants.LabelNetworkPolicyAllowed,
-					}),
+					Name:      name + "-tracing-headers",
+					Namespace: metav1.NamespaceSystem,
+					Labels:    registryutils.GetLabels(name, upstreamLabel),
 				},
-				Spec: corev1.PodSpec{
-					AutomountServiceAccountToken: ptr.To(false),
-					PriorityClassName:            "system-cluster-critical",
-					SecurityContext: &corev1.PodSecurityContext{
-						SeccompProfile: &corev1.SeccompProfile{
-							Type: corev1.SeccompProfileTypeRuntimeDefault,
-						},
-					},
-					Containers: []corev1.Container{
-						{
-							Name:            "registry-cache",
-							Image:           r.values.Image,
-							ImagePullPolicy: corev1.PullIfNotPresent,
-							Resources: corev1.ResourceRequirements{
-								Requests: corev1.ResourceList{
-									corev1.ResourceCPU:    resource.MustParse("20m"),
-									corev1.ResourceMemory: resource.MustParse("50Mi"),
-								},
-							},
-							// Mitigation for https://github.com/distribution/distribution/issues/4478:
-							// The registry image entrypoint (https://github.com/distribution/distribution-library-image/blob/be4eca0a5f3af34a026d1e9294d63f3464c06131/Dockerfile#L31)
-							// is extended with a mitigation logic for https://github.com/distribution/distribution/issues/4478.
-							// Keep in sync the registry image entrypoint with the below invocation when updating the registry image version.
-							Command: []string{"/bin/sh", "-c", `REPO_ROOT=` + repositoryMountPath + `
-SCHEDULER_STATE_FILE="${REPO_ROOT}/scheduler-state.json"
-
-if [ -f "${SCHEDULER_STATE_FILE}" ]; then
-    if [ -s "${SCHEDULER_STATE_FILE}" ]; then
-        echo "The scheduler-state.json file exists and it is not empty. Won't clean up anything..."
-    else
-        echo "Detected a corrupted scheduler-state.json file"
-
-        echo "Cleaning up the scheduler-state.json file"
-        rm -f "${SCHEDULER_STATE_FILE}"
-
-        echo "Cleaning up the docker directory"
-        rm -rf "${REPO_ROOT}/docker"
-    fi
-else
-    echo "The scheduler-state.json file is not created yet. Won't clean up anything..."
-fi
-
-echo "Starting..."
-source /entrypoint.sh /etc/distribution/config.yml
-`},
-							Ports: []corev1.ContainerPort{
-								{
-									ContainerPort: constants.RegistryCachePort,
-									Name:          "registry-cache",
-								},
-								{
-									ContainerPort: debugPort,
-									Name:          "debug",
-								},
-							},
-							Env: []corev1.EnvVar{
-								// Mitigation for https://github.com/distribution/distribution/issues/4270.
-								{
-									Name:  "OTEL_TRACES_EXPORTER",
-									Value: "none",
-								},
-							},
-							SecurityContext: &corev1.SecurityContext{
-								AllowPrivilegeEscalation: ptr.To(false),
-							},
-							LivenessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/debug/health",
-										Port: intstr.FromInt32(debugPort),
-									},
-								},
-								FailureThreshold: 6,
-								SuccessThreshold: 1,
-								PeriodSeconds:    20,
-							},
-							ReadinessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/debug/health",
-										Port: intstr.FromInt32(debugPort),
-									},
-								},
-								FailureThreshold: 3,
-								SuccessThreshold: 1,
-								PeriodSeconds:    20,
-							},
-							VolumeMounts: []corev1.VolumeMount{
-								{
-									Name:      registryCacheVolumeName,
-									ReadOnly:  false,
-									MountPath: repositoryMountPath,
-								},
-								{
-									Name:      registryConfigVolumeName,
-									MountPath: "/etc/distribution",
-								},
-							},
-						},
-					},
-					Volumes: []corev1.Volume{
-						{
-							Name: registryConfigVolumeName,
-							VolumeSource: corev1.VolumeSource{
-								Secret: &corev1.SecretVolumeSource{
-									SecretName: configSecret.Name,
-								},
-							},
-						},
-					},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					"headers": []byte(otlpHeaders(refSecret.Data)),
 				},
-			},
-			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:   registryCacheVolumeName,
-						Labels: registryutils.GetLabels(name, upstreamLabel),
-					},
-					Spec: corev1.PersistentVolumeClaimSpec{
-						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-						Resources: corev1.VolumeResourceRequirements{
-							Requests: corev1.ResourceList{
-								corev1.ResourceStorage: *cache.Volume.Size,
-							},
-						},
-						StorageClassName: storageClassName,
+			}
+			utilruntime.Must(kubernetesutils.MakeUnique(tracingHeadersSecret))
+
+			env = append(env, corev1.EnvVar{
+				Name: "OTEL_EXPORTER_OTLP_HEADERS",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: tracingHeadersSecret.Name},
+						Key:                  "headers",
 					},
 				},
-			},
-		},
+			})
+		}
+	}
+
+	podLabels := utils.MergeStringMaps(registryutils.GetLabels(name, upstreamLabel), map[string]string{
+		v1beta1constants.LabelNetworkPolicyToDNS:            v1beta1constants.LabelNetworkPolicyAllowed,
+		v1beta1constants.LabelNetworkPolicyToPublicNetworks: v1beta1constants.LabelNetworkPolicyAllowed,
+	})
+	if ptr.Deref(cache.Strict, false) {
+		podLabels[constants.StrictModeLabel] = "true"
 	}
 
+	statefulSet := NewStatefulSet(StatefulSetValues{
+		Name:              name,
+		Namespace:         metav1.NamespaceSystem,
+		Upstream:          cache.Upstream,
+		Image:             r.values.Image,
+		PodLabels:         podLabels,
+		PriorityClassName: "system-cluster-critical",
+		Port:              port,
+		DebugPort:         debugPort,
+		Env:               env,
+		ConfigSecretName:  configSecret.Name,
+		Size:              *cache.Volume.Size,
+		StorageClassName:  storageClassName,
+	})
+
+	var proxyEnv []corev1.EnvVar
 	if cache.Proxy != nil {
 		if cache.Proxy.HTTPProxy != nil {
-			statefulSet.Spec.Template.Spec.Containers[0].Env = append(statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+			proxyEnv = append(proxyEnv, corev1.EnvVar{
 				Name:  "HTTP_PROXY",
 				Value: *cache.Proxy.HTTPProxy,
 			})
 		}
 		if cache.Proxy.HTTPSProxy != nil {
-			statefulSet.Spec.Template.Spec.Containers[0].Env = append(statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+			proxyEnv = append(proxyEnv, corev1.EnvVar{
 				Name:  "HTTPS_PROXY",
 				Value: *cache.Proxy.HTTPSProxy,
 			})
 		}
 	}
+	statefulSet.Spec.Template.Spec.Containers[0].Env = append(statefulSet.Spec.Template.Spec.Containers[0].Env, proxyEnv...)
+
+	statefulSet.Spec.Template.Spec.Containers = append(statefulSet.Spec.Template.Spec.Containers, r.upstreamLimiterContainer(cache.UpstreamRateLimits, upstreamLimiterURL, proxyEnv))
+
+	if helper.BlobDescriptorCacheEnabled(cache) {
+		statefulSet.Spec.Template.Spec.Containers = append(statefulSet.Spec.Template.Spec.Containers, r.redisContainer(cache))
+	}
 
 	var tlsSecret *corev1.Secret
 	if helper.TLSEnabled(cache) {
@@ -472,66 +499,269 @@ source /entrypoint.sh /etc/distribution/config.yml
 				Labels:    registryutils.GetLabels(name, upstreamLabel),
 			},
 			Type: corev1.SecretTypeOpaque,
-			Data: generatedTLSSecret.Data,
+			Data: serverTLSSecret.Data,
 		}
 		utilruntime.Must(kubernetesutils.MakeUnique(tlsSecret))
 
+		AddCertificatesVolume(statefulSet, tlsSecret.Name)
+	}
+
+	var sharedCASecret *corev1.Secret
+	if useSharedCache {
+		sharedCASecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-shared-ca",
+				Namespace: metav1.NamespaceSystem,
+				Labels:    registryutils.GetLabels(name, upstreamLabel),
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				"ca.crt": r.values.SharedCacheCABundle,
+			},
+		}
+		utilruntime.Must(kubernetesutils.MakeUnique(sharedCASecret))
+
 		statefulSet.Spec.Template.Spec.Volumes = append(statefulSet.Spec.Template.Spec.Volumes, corev1.Volume{
-			Name: registryCertsVolumeName,
+			Name: sharedCAVolumeName,
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName:  tlsSecret.Name,
-					DefaultMode: ptr.To[int32](0640),
+					SecretName: sharedCASecret.Name,
 				},
 			},
 		})
-		statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts = append(statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
-			Name:      registryCertsVolumeName,
-			MountPath: "/etc/distribution/certs",
-		})
+		for i, container := range statefulSet.Spec.Template.Spec.Containers {
+			// Only the registry cache and the upstream limiter send requests to the shared registry cache.
+			if container.Name == redisContainerName {
+				continue
+			}
+
+			statefulSet.Spec.Template.Spec.Containers[i].VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      sharedCAVolumeName,
+				MountPath: sharedCAMountPath,
+			})
+			// Trust the CA of the shared registry cache in addition to the system CAs.
+			statefulSet.Spec.Template.Spec.Containers[i].Env = append(container.Env, corev1.EnvVar{
+				Name:  "SSL_CERT_DIR",
+				Value: "/etc/ssl/certs:" + sharedCAMountPath,
+			})
+		}
 	}
 
 	utilruntime.Must(references.InjectAnnotations(statefulSet))
+	metav1.SetMetaDataAnnotation(&statefulSet.ObjectMeta, podTemplateChecksumAnnotation, utils.ComputeChecksum(statefulSet.Spec.Template))
+
+	objects := []client.Object{
+		configSecret,
+		tlsSecret,
+		sharedCASecret,
+		tracingHeadersSecret,
+	}
+	deployed, deferred, err := r.deferDisruptiveChanges(ctx, cache, statefulSet)
+	if err != nil {
+		return nil, err
+	}
+	if deferred {
+		// The deployed Pod template and the Secrets referenced by it are kept until the changes are applied.
+		statefulSet.Spec.Template = deployed.Spec.Template
+		statefulSet.Annotations = nil
+		utilruntime.Must(references.InjectAnnotations(statefulSet))
+		metav1.SetMetaDataAnnotation(&statefulSet.ObjectMeta, podTemplateChecksumAnnotation, deployed.Annotations[podTemplateChecksumAnnotation])
+
+		objects, err = r.deployedReferencedSecrets(ctx, deployed)
+		if err != nil {
+			return nil, err
+		}
+		r.upstreamsWithPendingDisruptiveChanges = append(r.upstreamsWithPendingDisruptiveChanges, cache.Upstream)
+	}
 
 	var vpa *vpaautoscalingv1.VerticalPodAutoscaler
 	if r.values.VPAEnabled {
-		vpa = &vpaautoscalingv1.VerticalPodAutoscaler{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      name,
-				Namespace: metav1.NamespaceSystem,
+		vpa = NewVerticalPodAutoscaler(name, metav1.NamespaceSystem)
+	}
+
+	if vpa != nil {
+		vpa.Spec.ResourcePolicy.ContainerPolicies = append(vpa.Spec.ResourcePolicy.ContainerPolicies, vpaautoscalingv1.ContainerResourcePolicy{
+			ContainerName:    upstreamLimiterContainerName,
+			ControlledValues: ptr.To(vpaautoscalingv1.ContainerControlledValuesRequestsOnly),
+			MinAllowed: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
 			},
-			Spec: vpaautoscalingv1.VerticalPodAutoscalerSpec{
-				TargetRef: &autoscalingv1.CrossVersionObjectReference{
-					APIVersion: appsv1.SchemeGroupVersion.String(),
-					Kind:       "StatefulSet",
-					Name:       name,
-				},
-				UpdatePolicy: &vpaautoscalingv1.PodUpdatePolicy{
-					UpdateMode: ptr.To(vpaautoscalingv1.UpdateModeAuto),
-				},
-				ResourcePolicy: &vpaautoscalingv1.PodResourcePolicy{
-					ContainerPolicies: []vpaautoscalingv1.ContainerResourcePolicy{
-						{
-							ContainerName:    vpaautoscalingv1.DefaultContainerResourcePolicy,
-							ControlledValues: ptr.To(vpaautoscalingv1.ContainerControlledValuesRequestsOnly),
-							MinAllowed: corev1.ResourceList{
-								corev1.ResourceMemory: resource.MustParse("20Mi"),
-							},
-							MaxAllowed: corev1.ResourceList{
-								corev1.ResourceCPU:    resource.MustParse("4"),
-								corev1.ResourceMemory: resource.MustParse("8Gi"),
-							},
-						},
-					},
-				},
+			MaxAllowed: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
 			},
+		})
+	}
+
+	if vpa != nil && helper.BlobDescriptorCacheEnabled(cache) {
+		maxMemory := helper.BlobDescriptorCacheMaxMemory(cache)
+		// Allow twice the maximum memory of the blob descriptor cache for the memory overhead of Redis.
+		maxAllowedMemory := maxMemory.DeepCopy()
+		maxAllowedMemory.Add(maxMemory)
+
+		vpa.Spec.ResourcePolicy.ContainerPolicies = append(vpa.Spec.ResourcePolicy.ContainerPolicies, vpaautoscalingv1.ContainerResourcePolicy{
+			ContainerName:    redisContainerName,
+			ControlledValues: ptr.To(vpaautoscalingv1.ContainerControlledValuesRequestsOnly),
+			MinAllowed: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
+			},
+			MaxAllowed: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: maxAllowedMemory,
+			},
+		})
+	}
+
+	return append(objects, statefulSet, vpa), nil
+}
+
+// redisContainer returns the sidecar container running the Redis instance which serves as blob descriptor cache of
+// the given registry cache. Redis only listens on the loopback interface and does not persist the cached descriptors.
+func (r *registryCaches) redisContainer(cache *api.RegistryCache) corev1.Container {
+	maxMemory := helper.BlobDescriptorCacheMaxMemory(cache)
+	host, port, _ := net.SplitHostPort(redisAddr)
+
+	probeHandler := corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"redis-cli", "-h", host, "-p", port, "ping"},
+		},
+	}
+
+	return corev1.Container{
+		Name:            redisContainerName,
+		Image:           r.values.RedisImage,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Args: []string{
+			"redis-server",
+			"--bind", host,
+			"--port", port,
+			"--save", "",
+			"--appendonly", "no",
+			"--maxmemory", strconv.FormatInt(maxMemory.Value(), 10),
+			"--maxmemory-policy", "allkeys-lru",
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("20Mi"),
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+		},
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler:     probeHandler,
+			FailureThreshold: 6,
+			SuccessThreshold: 1,
+			PeriodSeconds:    20,
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler:     probeHandler,
+			FailureThreshold: 3,
+			SuccessThreshold: 1,
+			PeriodSeconds:    20,
+		},
+	}
+}
+
+// upstreamLimiterContainer returns the sidecar container running the upstream limiter which proxies the requests of
+// the registry cache to the given upstream URL and enforces the given rate limits, if any. The upstream limiter only
+// listens on the loopback interface for requests, its metrics and health checks are served on all interfaces.
+func (r *registryCaches) upstreamLimiterContainer(rateLimits *api.UpstreamRateLimits, upstreamURL string, env []corev1.EnvVar) corev1.Container {
+	args := []string{"--upstream-url=" + upstreamURL}
+	if rateLimits != nil {
+		if rateLimits.RequestsPerSecond != nil {
+			args = append(args, fmt.Sprintf("--requests-per-second=%d", *rateLimits.RequestsPerSecond))
+		}
+		if rateLimits.MaxConcurrentRequests != nil {
+			args = append(args, fmt.Sprintf("--max-concurrent-requests=%d", *rateLimits.MaxConcurrentRequests))
+		}
+		if rateLimits.MaxBandwidth != nil {
+			args = append(args, fmt.Sprintf("--max-bandwidth=%d", rateLimits.MaxBandwidth.Value()))
 		}
 	}
 
-	return []client.Object{
-		configSecret,
-		tlsSecret,
-		statefulSet,
-		vpa,
-	}, nil
+	probeHandler := corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path: "/healthz",
+			Port: intstr.FromInt32(constants.UpstreamLimiterMetricsPort),
+		},
+	}
+
+	return corev1.Container{
+		Name:            upstreamLimiterContainerName,
+		Image:           r.values.UpstreamLimiterImage,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Args:            args,
+		Ports: []corev1.ContainerPort{
+			{
+				ContainerPort: constants.UpstreamLimiterMetricsPort,
+				Name:          "limiter-metrics",
+			},
+		},
+		Env: env,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("20Mi"),
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+		},
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler:     probeHandler,
+			FailureThreshold: 6,
+			SuccessThreshold: 1,
+			PeriodSeconds:    20,
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler:     probeHandler,
+			FailureThreshold: 3,
+			SuccessThreshold: 1,
+			PeriodSeconds:    20,
+		},
+	}
+}
+
+// tracingEnv returns the environment variables configuring the OpenTelemetry SDK of the registry cache to export
+// traces to the OTLP endpoint of the given tracing settings.
+func tracingEnv(serviceName string, tracing *api.Tracing) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "OTEL_TRACES_EXPORTER",
+			Value: "otlp",
+		},
+		{
+			Name:  "OTEL_EXPORTER_OTLP_ENDPOINT",
+			Value: tracing.Endpoint,
+		},
+		{
+			Name:  "OTEL_EXPORTER_OTLP_PROTOCOL",
+			Value: string(helper.TracingProtocol(tracing)),
+		},
+		{
+			Name:  "OTEL_SERVICE_NAME",
+			Value: serviceName,
+		},
+		{
+			Name:  "OTEL_TRACES_SAMPLER",
+			Value: "parentbased_traceidratio",
+		},
+		{
+			Name:  "OTEL_TRACES_SAMPLER_ARG",
+			Value: helper.TracingSamplingRatio(tracing),
+		},
+	}
+}
+
+// otlpHeaders returns the given headers in the format of the OTEL_EXPORTER_OTLP_HEADERS environment variable, i.e. a
+// comma-separated list of `<name>=<value>` pairs with percent-encoded values.
+func otlpHeaders(headers map[string][]byte) string {
+	pairs := make([]string, 0, len(headers))
+	for _, name := range sets.List(sets.KeySet(headers)) {
+		pairs = append(pairs, name+"="+strings.ReplaceAll(url.QueryEscape(string(headers[name])), "+", "%20"))
+	}
+
+	return strings.Join(pairs, ",")
 }
@@ -6,15 +6,20 @@ package registrycaches_test
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
 	"github.com/gardener/gardener/pkg/client/kubernetes"
 	"github.com/gardener/gardener/pkg/resourcemanager/controller/garbagecollector/references"
+	"github.com/gardener/gardener/pkg/utils"
 	kubernetesutils "github.com/gardener/gardener/pkg/utils/kubernetes"
+	"github.com/gardener/gardener/pkg/utils/managedresources"
 	"github.com/gardener/gardener/pkg/utils/retry"
 	retryfake "github.com/gardener/gardener/pkg/utils/retry/fake"
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
 	secretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager"
 	fakesecretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager/fake"
 	"github.com/gardener/gardener/pkg/utils/test"
@@ -46,8 +51,9 @@ var _ = Describe("RegistryCaches", func() {
 	const (
 		managedResourceName = "extension-registry-cache"
 
-		namespace = "some-namespace"
-		image     = "some-image:some-tag"
+		namespace            = "some-namespace"
+		image                = "some-image:some-tag"
+		upstreamLimiterImage = "some-upstream-limiter-image:some-tag"
 	)
 
 	var (
@@ -56,6 +62,7 @@ var _ = Describe("RegistryCaches", func() {
 		arSize     = resource.MustParse("20Gi")
 
 		c                     client.Client
+		shootClient           client.Client
 		secretsManager        secretsmanager.Interface
 		values                Values
 		managedResource       *resourcesv1alpha1.ManagedResource
@@ -67,10 +74,12 @@ var _ = Describe("RegistryCaches", func() {
 
 	BeforeEach(func() {
 		c = fakeclient.NewClientBuilder().WithScheme(kubernetes.SeedScheme).Build()
+		shootClient = fakeclient.NewClientBuilder().WithScheme(kubernetes.ShootScheme).Build()
 		secretsManager = fakesecretsmanager.New(c, namespace)
 		values = Values{
-			Image:      image,
-			VPAEnabled: true,
+			Image:                image,
+			UpstreamLimiterImage: upstreamLimiterImage,
+			VPAEnabled:           true,
 			Services: []corev1.Service{
 				{
 					ObjectMeta: metav1.ObjectMeta{
@@ -138,11 +147,19 @@ var _ = Describe("RegistryCaches", func() {
 				Namespace: namespace,
 			},
 		}
-		consistOf = NewManagedResourceConsistOfObjectsMatcher(c)
+		consistOfObjects := NewManagedResourceConsistOfObjectsMatcher(c)
+		consistOf = func(objects ...client.Object) types.GomegaMatcher {
+			for _, obj := range objects {
+				if statefulSet, ok := obj.(*appsv1.StatefulSet); ok {
+					metav1.SetMetaDataAnnotation(&statefulSet.ObjectMeta, "registry-cache.extensions.gardener.cloud/pod-template-checksum", utils.ComputeChecksum(statefulSet.Spec.Template))
+				}
+			}
+			return consistOfObjects(objects...)
+		}
 	})
 
 	JustBeforeEach(func() {
-		registryCaches = New(c, namespace, secretsManager, values)
+		registryCaches = New(c, shootClient, namespace, secretsManager, values)
 	})
 
 	Describe("#Deploy", func() {
@@ -191,23 +208,12 @@ var _ = Describe("RegistryCaches", func() {
 				return tlsSecret
 			}
 
-			configYAMLFor = func(upstreamURL string, ttl string, username, password string, tlsEnabled bool) string {
-				config := `# Maintain this file with the default config file (/etc/distribution/config.yml) from the registry image (europe-docker.pkg.dev/gardener-project/releases/3rd/registry:3.0.0-rc.3).
-version: 0.1
-log:
-  fields:
-    service: registry
-storage:
-  delete:
+			configYAMLFor = func(ttl string, username, password string, tlsEnabled bool) string {
+				config := `health:
+  storagedriver:
     enabled: true
-  # Mitigate https://github.com/distribution/distribution/issues/2367 by disabling the blobdescriptor cache.
-  # For more details, see https://github.com/distribution/distribution/issues/2367#issuecomment-1874449361.
-  # cache:
-  #  blobdescriptor: inmemory
-  filesystem:
-    rootdirectory: /var/lib/registry
-  tag:
-    concurrencylimit: 5
+    interval: 10s
+    threshold: 3
 http:
   addr: :5000
   debug:
@@ -215,37 +221,100 @@ http:
     prometheus:
       enabled: true
       path: /metrics
-  draintimeout: 25s`
+  draintimeout: 25s
+  headers:
+    X-Content-Type-Options:
+    - nosniff
+`
 
 				if tlsEnabled {
-					config += `
-  tls:
+					config += `  tls:
     certificate: /etc/distribution/certs/tls.crt
-    key: /etc/distribution/certs/tls.key`
+    key: /etc/distribution/certs/tls.key
+`
 				}
 
-				config += `
-  headers:
-    X-Content-Type-Options: [nosniff]
-health:
-  storagedriver:
-    enabled: true
-    interval: 10s
-    threshold: 3
+				config += `log:
+  fields:
+    service: registry
 proxy:
-  remoteurl: ` + upstreamURL + `
+`
+
+				if username != "" && password != "" {
+					config += `  password: ` + password + `
+`
+				}
+
+				config += `  remoteurl: http://127.0.0.1:5002
   ttl: ` + ttl + `
 `
 
 				if username != "" && password != "" {
 					config += `  username: ` + username + `
-  password: '` + password + `'
 `
 				}
 
+				config += `storage:
+  delete:
+    enabled: true
+  filesystem:
+    rootdirectory: /var/lib/registry
+  tag:
+    concurrencylimit: 5
+version: "0.1"
+`
+
 				return config
 			}
 
+			upstreamURLs = map[string]string{
+				"docker.io":             "https://registry-1.docker.io",
+				"europe-docker.pkg.dev": "https://europe-docker.pkg.dev",
+			}
+
+			limiterContainerFor = func(upstreamURL string, args ...string) corev1.Container {
+				probeHandler := corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{
+						Path: "/healthz",
+						Port: intstr.FromInt32(5003),
+					},
+				}
+
+				return corev1.Container{
+					Name:            "upstream-limiter",
+					Image:           upstreamLimiterImage,
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					Args:            append([]string{"--upstream-url=" + upstreamURL}, args...),
+					Ports: []corev1.ContainerPort{
+						{
+							ContainerPort: 5003,
+							Name:          "limiter-metrics",
+						},
+					},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10m"),
+							corev1.ResourceMemory: resource.MustParse("20Mi"),
+						},
+					},
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: ptr.To(false),
+					},
+					LivenessProbe: &corev1.Probe{
+						ProbeHandler:     probeHandler,
+						FailureThreshold: 6,
+						SuccessThreshold: 1,
+						PeriodSeconds:    20,
+					},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler:     probeHandler,
+						FailureThreshold: 3,
+						SuccessThreshold: 1,
+						PeriodSeconds:    20,
+					},
+				}
+			}
+
 			statefulSetFor = func(name, upstream, size, configSecretName string, tlsEnabled bool, tlsSecretName string, storageClassName *string, additionalEnvs []corev1.EnvVar) *appsv1.StatefulSet {
 				env := []corev1.EnvVar{
 					{
@@ -407,6 +476,8 @@ source /entrypoint.sh /etc/distribution/config.yml
 					},
 				}
 
+				statefulSet.Spec.Template.Spec.Containers = append(statefulSet.Spec.Template.Spec.Containers, limiterContainerFor(upstreamURLs[upstream]))
+
 				if tlsEnabled {
 					statefulSet.Spec.Template.Spec.Volumes = append(statefulSet.Spec.Template.Spec.Volumes, corev1.Volume{
 						Name: "certs-volume",
@@ -456,6 +527,17 @@ source /entrypoint.sh /etc/distribution/config.yml
 										corev1.ResourceMemory: resource.MustParse("8Gi"),
 									},
 								},
+								{
+									ContainerName:    "upstream-limiter",
+									ControlledValues: ptr.To(vpaautoscalingv1.ContainerControlledValuesRequestsOnly),
+									MinAllowed: corev1.ResourceList{
+										corev1.ResourceMemory: resource.MustParse("10Mi"),
+									},
+									MaxAllowed: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("1"),
+										corev1.ResourceMemory: resource.MustParse("1Gi"),
+									},
+								},
 							},
 						},
 					},
@@ -516,8 +598,8 @@ source /entrypoint.sh /etc/distribution/config.yml
 				Expect(managedResourceSecret.Immutable).To(Equal(ptr.To(true)))
 				Expect(managedResourceSecret.Labels["resources.gardener.cloud/garbage-collectable-reference"]).To(Equal("true"))
 
-				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("https://registry-1.docker.io", "336h0m0s", "", "", true))
-				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("https://europe-docker.pkg.dev", "0s", "", "", false))
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
 
 				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
 				Expect(ok).To(BeTrue())
@@ -545,8 +627,8 @@ source /entrypoint.sh /etc/distribution/config.yml
 
 				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
 
-				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("https://registry-1.docker.io", "336h0m0s", "", "", true))
-				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("https://europe-docker.pkg.dev", "0s", "", "", false))
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
 
 				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
 				Expect(ok).To(BeTrue())
@@ -590,20 +672,467 @@ source /entrypoint.sh /etc/distribution/config.yml
 					},
 				}
 
-				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("https://registry-1.docker.io", "336h0m0s", "", "", true))
-				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("https://europe-docker.pkg.dev", "0s", "", "", false))
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				// The upstream limiter sends the requests to the upstream, hence it also uses the proxy.
+				dockerStatefulSet := statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, additionalEnvs)
+				dockerStatefulSet.Spec.Template.Spec.Containers[1].Env = additionalEnvs
+				arStatefulSet := statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), additionalEnvs)
+				arStatefulSet.Spec.Template.Spec.Containers[1].Env = additionalEnvs
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					dockerStatefulSet,
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					arStatefulSet,
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+		})
+
+		Context("when a remote URL with path is set", func() {
+			BeforeEach(func() {
+				values.Caches[0].RemoteURL = ptr.To("https://nexus.example.com/repository/docker-proxy/v2")
+				values.Caches[1].RemoteURL = ptr.To("https://nexus.example.com/repository/gar-proxy")
+			})
+
+			It("should proxy to the remote URL without the API root", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				dockerStatefulSet := statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, nil)
+				dockerStatefulSet.Spec.Template.Spec.Containers[1] = limiterContainerFor("https://nexus.example.com/repository/docker-proxy")
+				arStatefulSet := statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil)
+				arStatefulSet.Spec.Template.Spec.Containers[1] = limiterContainerFor("https://nexus.example.com/repository/gar-proxy")
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					dockerStatefulSet,
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					arStatefulSet,
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+		})
+
+		Context("when a cache is strict", func() {
+			BeforeEach(func() {
+				values.Caches[0].Strict = ptr.To(true)
+			})
+
+			It("should label the registry cache Pods", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
 
 				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
 				Expect(ok).To(BeTrue())
 				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
 
+				dockerStatefulSet := statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, nil)
+				dockerStatefulSet.Spec.Template.Labels["strict-mode"] = "true"
+
 				Expect(managedResource).To(consistOf(
 					dockerConfigSecret,
 					dockerTLSSecret,
-					statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, additionalEnvs),
+					dockerStatefulSet,
 					vpaFor("registry-docker-io"),
 					arConfigSecret,
-					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), additionalEnvs),
+					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil),
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+		})
+
+		Context("when the ports and the log settings are set", func() {
+			BeforeEach(func() {
+				values.Caches[0].HTTP = &api.HTTP{
+					TLS:       true,
+					Port:      ptr.To[int32](8443),
+					DebugPort: ptr.To[int32](9090),
+				}
+				values.Caches[0].Log = &api.Log{
+					Level:     ptr.To(api.LogLevelDebug),
+					Format:    ptr.To(api.LogFormatJSON),
+					AccessLog: ptr.To(false),
+				}
+			})
+
+			It("should configure the ports and the log settings", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigYAML := configYAMLFor("336h0m0s", "", "", true)
+				dockerConfigYAML = strings.Replace(dockerConfigYAML, `log:
+  fields:
+    service: registry
+`, `log:
+  accesslog:
+    disabled: true
+  fields:
+    service: registry
+  formatter: json
+  level: debug
+`, 1)
+				dockerConfigYAML = strings.Replace(dockerConfigYAML, "addr: :5000", "addr: :8443", 1)
+				dockerConfigYAML = strings.Replace(dockerConfigYAML, "addr: :5001", "addr: :9090", 1)
+
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", dockerConfigYAML)
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				dockerStatefulSet := statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, nil)
+				container := &dockerStatefulSet.Spec.Template.Spec.Containers[0]
+				container.Ports[0].ContainerPort = 8443
+				container.Ports[1].ContainerPort = 9090
+				container.LivenessProbe.HTTPGet.Port = intstr.FromInt32(9090)
+				container.ReadinessProbe.HTTPGet.Port = intstr.FromInt32(9090)
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					dockerStatefulSet,
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil),
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+		})
+
+		Context("when a blob descriptor cache is enabled", func() {
+			BeforeEach(func() {
+				values.RedisImage = "some-redis-image:some-tag"
+				values.Caches[0].BlobDescriptorCache = &api.BlobDescriptorCache{
+					Type:      api.BlobDescriptorCacheTypeRedis,
+					MaxMemory: ptr.To(resource.MustParse("128Mi")),
+				}
+			})
+
+			It("should deploy a Redis sidecar and configure it as blob descriptor cache", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigYAML := strings.Replace(configYAMLFor("336h0m0s", "", "", true), `storage:
+`, `redis:
+  addrs:
+  - 127.0.0.1:6379
+  dialtimeout: 1s
+  poolsize: 10
+  readtimeout: 1s
+  writetimeout: 1s
+storage:
+  cache:
+    blobdescriptor: redis
+`, 1)
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", dockerConfigYAML)
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				redisProbeHandler := corev1.ProbeHandler{
+					Exec: &corev1.ExecAction{
+						Command: []string{"redis-cli", "-h", "127.0.0.1", "-p", "6379", "ping"},
+					},
+				}
+				dockerStatefulSet := statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, nil)
+				dockerStatefulSet.Spec.Template.Spec.Containers = append(dockerStatefulSet.Spec.Template.Spec.Containers, corev1.Container{
+					Name:            "redis",
+					Image:           "some-redis-image:some-tag",
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					Args: []string{
+						"redis-server",
+						"--bind", "127.0.0.1",
+						"--port", "6379",
+						"--save", "",
+						"--appendonly", "no",
+						"--maxmemory", "134217728",
+						"--maxmemory-policy", "allkeys-lru",
+					},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10m"),
+							corev1.ResourceMemory: resource.MustParse("20Mi"),
+						},
+					},
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: ptr.To(false),
+					},
+					LivenessProbe: &corev1.Probe{
+						ProbeHandler:     redisProbeHandler,
+						FailureThreshold: 6,
+						SuccessThreshold: 1,
+						PeriodSeconds:    20,
+					},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler:     redisProbeHandler,
+						FailureThreshold: 3,
+						SuccessThreshold: 1,
+						PeriodSeconds:    20,
+					},
+				})
+
+				dockerVPA := vpaFor("registry-docker-io")
+				dockerVPA.Spec.ResourcePolicy.ContainerPolicies = append(dockerVPA.Spec.ResourcePolicy.ContainerPolicies, vpaautoscalingv1.ContainerResourcePolicy{
+					ContainerName:    "redis",
+					ControlledValues: ptr.To(vpaautoscalingv1.ContainerControlledValuesRequestsOnly),
+					MinAllowed: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("10Mi"),
+					},
+					MaxAllowed: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+				})
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					dockerStatefulSet,
+					dockerVPA,
+					arConfigSecret,
+					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil),
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+		})
+
+		Context("when tracing is set", func() {
+			BeforeEach(func() {
+				Expect(c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespace,
+						Name:      "ref-otel-headers",
+					},
+					Data: map[string][]byte{
+						"Authorization": []byte("Bearer s3cret"),
+						"X-Scope-OrgID": []byte("shoot,dev"),
+					},
+				})).To(Succeed())
+
+				values.ResourceReferences = []gardencorev1beta1.NamedResourceReference{
+					{Name: "otel-headers-ref", ResourceRef: autoscalingv1.CrossVersionObjectReference{Name: "otel-headers", Kind: "Secret"}},
+				}
+				values.Caches[0].Tracing = &api.Tracing{
+					Endpoint:                   "http://otel-collector.monitoring.svc:4317",
+					Protocol:                   ptr.To(api.TracingProtocolGRPC),
+					SamplingRatio:              ptr.To("0.1"),
+					HeadersSecretReferenceName: ptr.To("otel-headers-ref"),
+				}
+				values.Caches[1].Tracing = &api.Tracing{
+					Endpoint: "https://otel.example.com",
+				}
+			})
+
+			It("should configure the export of traces", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				dockerTracingHeadersSecret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "registry-docker-io-tracing-headers",
+						Namespace: "kube-system",
+						Labels: map[string]string{
+							"app":           "registry-docker-io",
+							"upstream-host": "docker.io",
+							"resources.gardener.cloud/garbage-collectable-reference": "true",
+						},
+					},
+					Immutable: ptr.To(true),
+					Type:      corev1.SecretTypeOpaque,
+					Data: map[string][]byte{
+						"headers": []byte("Authorization=Bearer%20s3cret,X-Scope-OrgID=shoot%2Cdev"),
+					},
+				}
+				utilruntime.Must(kubernetesutils.MakeUnique(dockerTracingHeadersSecret))
+
+				dockerStatefulSet := statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, nil)
+				dockerStatefulSet.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+					{Name: "OTEL_TRACES_EXPORTER", Value: "otlp"},
+					{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: "http://otel-collector.monitoring.svc:4317"},
+					{Name: "OTEL_EXPORTER_OTLP_PROTOCOL", Value: "grpc"},
+					{Name: "OTEL_SERVICE_NAME", Value: "registry-docker-io"},
+					{Name: "OTEL_TRACES_SAMPLER", Value: "parentbased_traceidratio"},
+					{Name: "OTEL_TRACES_SAMPLER_ARG", Value: "0.1"},
+					{
+						Name: "OTEL_EXPORTER_OTLP_HEADERS",
+						ValueFrom: &corev1.EnvVarSource{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: dockerTracingHeadersSecret.Name},
+								Key:                  "headers",
+							},
+						},
+					},
+				}
+				utilruntime.Must(references.InjectAnnotations(dockerStatefulSet))
+
+				arStatefulSet := statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil)
+				arStatefulSet.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+					{Name: "OTEL_TRACES_EXPORTER", Value: "otlp"},
+					{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: "https://otel.example.com"},
+					{Name: "OTEL_EXPORTER_OTLP_PROTOCOL", Value: "http/protobuf"},
+					{Name: "OTEL_SERVICE_NAME", Value: "registry-europe-docker-pkg-dev"},
+					{Name: "OTEL_TRACES_SAMPLER", Value: "parentbased_traceidratio"},
+					{Name: "OTEL_TRACES_SAMPLER_ARG", Value: "1"},
+				}
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					dockerTracingHeadersSecret,
+					dockerStatefulSet,
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					arStatefulSet,
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+		})
+
+		Context("when upstream rate limits are set", func() {
+			BeforeEach(func() {
+				values.Caches[0].Proxy = &api.Proxy{
+					HTTPSProxy: ptr.To("http://proxy.example.com:3128"),
+				}
+				values.Caches[0].UpstreamRateLimits = &api.UpstreamRateLimits{
+					RequestsPerSecond:     ptr.To[int32](20),
+					MaxConcurrentRequests: ptr.To[int32](10),
+					MaxBandwidth:          ptr.To(resource.MustParse("50Mi")),
+				}
+			})
+
+			It("should configure the limits of the upstream limiter sidecar", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				proxyEnv := corev1.EnvVar{
+					Name:  "HTTPS_PROXY",
+					Value: "http://proxy.example.com:3128",
+				}
+				dockerStatefulSet := statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, []corev1.EnvVar{proxyEnv})
+				dockerStatefulSet.Spec.Template.Spec.Containers[1] = limiterContainerFor("https://registry-1.docker.io",
+					"--requests-per-second=20",
+					"--max-concurrent-requests=10",
+					"--max-bandwidth=52428800",
+				)
+				dockerStatefulSet.Spec.Template.Spec.Containers[1].Env = []corev1.EnvVar{proxyEnv}
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					dockerStatefulSet,
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil),
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+		})
+
+		Context("when notifications are set", func() {
+			BeforeEach(func() {
+				Expect(c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespace,
+						Name:      "ref-audit-headers",
+					},
+					Data: map[string][]byte{
+						"Authorization": []byte("Bearer token"),
+					},
+				})).To(Succeed())
+
+				values.ResourceReferences = []gardencorev1beta1.NamedResourceReference{
+					{Name: "audit-headers-ref", ResourceRef: autoscalingv1.CrossVersionObjectReference{Name: "audit-headers", Kind: "Secret"}},
+				}
+				values.Caches[0].Notifications = &api.Notifications{
+					Endpoints: []api.NotificationEndpoint{
+						{
+							Name:                       "audit",
+							URL:                        "https://audit.example.com/events",
+							HeadersSecretReferenceName: ptr.To("audit-headers-ref"),
+							Actions:                    []api.NotificationAction{api.NotificationActionPull},
+						},
+					},
+				}
+			})
+
+			It("should configure the notification endpoints with the referenced headers", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigYAML := strings.Replace(configYAMLFor("336h0m0s", "", "", true), `proxy:
+`, `notifications:
+  endpoints:
+  - headers:
+      Authorization:
+      - Bearer token
+    ignore:
+      actions:
+      - push
+      - mount
+      - delete
+    name: audit
+    url: https://audit.example.com/events
+proxy:
+`, 1)
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", dockerConfigYAML)
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, nil),
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil),
 					vpaFor("registry-europe-docker-pkg-dev"),
 				))
 			})
@@ -627,8 +1156,8 @@ source /entrypoint.sh /etc/distribution/config.yml
 
 				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
 
-				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("https://registry-1.docker.io", "336h0m0s", "", "", false))
-				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("https://europe-docker.pkg.dev", "0s", "", "", false))
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", false))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
 
 				_, ok := secretsManager.Get("docker.io-tls")
 				Expect(ok).To(BeFalse())
@@ -646,6 +1175,91 @@ source /entrypoint.sh /etc/distribution/config.yml
 			})
 		})
 
+		Context("when a TLS certificate is provided", func() {
+			var certificate *secretsutils.Certificate
+
+			BeforeEach(func() {
+				var err error
+				certificate, err = (&secretsutils.CertificateSecretConfig{
+					Name:       "registry-docker-io",
+					CommonName: "registry-docker-io",
+					CertType:   secretsutils.CACert,
+				}).GenerateCertificate()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespace,
+						Name:      "ref-docker-tls",
+					},
+					Data: map[string][]byte{
+						"tls.crt": certificate.CertificatePEM,
+						"tls.key": certificate.PrivateKeyPEM,
+					},
+				})).To(Succeed())
+
+				values.ResourceReferences = []gardencorev1beta1.NamedResourceReference{
+					{Name: "docker-tls-ref", ResourceRef: autoscalingv1.CrossVersionObjectReference{Name: "docker-tls", Kind: "Secret"}},
+				}
+				values.Caches[0].HTTP = &api.HTTP{
+					TLS:                    true,
+					TLSSecretReferenceName: ptr.To("docker-tls-ref"),
+				}
+			})
+
+			It("should use the provided certificate instead of generating one", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+				Expect(registryCaches.CASecretName()).To(BeNil())
+
+				_, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeFalse())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerTLSSecret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "registry-docker-io-tls",
+						Namespace: "kube-system",
+						Labels: map[string]string{
+							"app":           "registry-docker-io",
+							"upstream-host": "docker.io",
+							"resources.gardener.cloud/garbage-collectable-reference": "true",
+						},
+					},
+					Immutable: ptr.To(true),
+					Type:      corev1.SecretTypeOpaque,
+					Data: map[string][]byte{
+						"tls.crt": certificate.CertificatePEM,
+						"tls.key": certificate.PrivateKeyPEM,
+					},
+				}
+				utilruntime.Must(kubernetesutils.MakeUnique(dockerTLSSecret))
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, nil),
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil),
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+
+				prometheusRule := &monitoringv1.PrometheusRule{ObjectMeta: metav1.ObjectMeta{Name: "shoot-registry-cache", Namespace: namespace}}
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(prometheusRule), prometheusRule)).To(Succeed())
+				Expect(prometheusRule.Spec.Groups[0].Rules[14:]).To(Equal([]monitoringv1.Rule{
+					{
+						Record: "registry_cache:certificate_expiration_timestamp_seconds",
+						Expr:   intstr.FromString(fmt.Sprintf("vector(%d)", certificate.Certificate.NotAfter.Unix())),
+						Labels: map[string]string{"certificate": "server", "upstream_host": "docker.io"},
+					},
+				}))
+			})
+		})
+
 		Context("upstream credentials are set", func() {
 			var (
 				dockerSecret *corev1.Secret
@@ -695,8 +1309,8 @@ source /entrypoint.sh /etc/distribution/config.yml
 
 				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
 
-				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("https://registry-1.docker.io", "336h0m0s", "docker-user", "s3cret", true))
-				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("https://europe-docker.pkg.dev", "0s", "ar-user", `{"foo":"bar"}`, false))
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "docker-user", "s3cret", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "ar-user", `'{"foo":"bar"}'`, false))
 
 				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
 				Expect(ok).To(BeTrue())
@@ -737,6 +1351,223 @@ source /entrypoint.sh /etc/distribution/config.yml
 			})
 		})
 
+		Context("when shared registry caches are available", func() {
+			BeforeEach(func() {
+				values.SharedCacheEndpoints = map[string]string{
+					"docker.io":             "https://10.0.0.1:5000",
+					"europe-docker.pkg.dev": "https://10.0.0.2:5000",
+				}
+				values.SharedCacheCABundle = []byte("shared-ca-bundle")
+				values.Caches[1].RemoteURL = ptr.To("https://europe-docker.pkg.dev")
+			})
+
+			It("should use the shared registry cache as remote URL for caches without custom remote URL", func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				dockerSharedCASecret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "registry-docker-io-shared-ca",
+						Namespace: "kube-system",
+						Labels: map[string]string{
+							"app":           "registry-docker-io",
+							"upstream-host": "docker.io",
+							"resources.gardener.cloud/garbage-collectable-reference": "true",
+						},
+					},
+					Immutable: ptr.To(true),
+					Type:      corev1.SecretTypeOpaque,
+					Data: map[string][]byte{
+						"ca.crt": []byte("shared-ca-bundle"),
+					},
+				}
+				utilruntime.Must(kubernetesutils.MakeUnique(dockerSharedCASecret))
+
+				dockerStatefulSet := statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, []corev1.EnvVar{
+					{
+						Name:  "SSL_CERT_DIR",
+						Value: "/etc/ssl/certs:/etc/distribution/shared-ca",
+					},
+				})
+				dockerStatefulSet.Spec.Template.Spec.Volumes = append(dockerStatefulSet.Spec.Template.Spec.Volumes, corev1.Volume{
+					Name: "shared-ca-volume",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: dockerSharedCASecret.Name,
+						},
+					},
+				})
+				dockerStatefulSet.Spec.Template.Spec.Containers[1] = limiterContainerFor("https://10.0.0.1:5000")
+				for i := range dockerStatefulSet.Spec.Template.Spec.Containers {
+					container := &dockerStatefulSet.Spec.Template.Spec.Containers[i]
+					container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+						Name:      "shared-ca-volume",
+						MountPath: "/etc/distribution/shared-ca",
+					})
+				}
+				dockerStatefulSet.Spec.Template.Spec.Containers[1].Env = []corev1.EnvVar{
+					{
+						Name:  "SSL_CERT_DIR",
+						Value: "/etc/ssl/certs:/etc/distribution/shared-ca",
+					},
+				}
+				utilruntime.Must(references.InjectAnnotations(dockerStatefulSet))
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					dockerSharedCASecret,
+					dockerStatefulSet,
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil),
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+
+			It("should not use the shared registry cache for strict caches", func() {
+				values.Caches[0].Strict = ptr.To(true)
+
+				registryCaches = New(c, shootClient, namespace, secretsManager, values)
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				objects, err := managedresources.GetObjects(ctx, c, namespace, managedResourceName)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objects).To(ContainElement(And(
+					HaveField("ObjectMeta.Name", Equal("registry-docker-io")),
+					HaveField("Spec.Template.Spec.Containers", ContainElement(HaveField("Args", ContainElement("--upstream-url=https://registry-1.docker.io")))),
+				)))
+				Expect(objects).NotTo(ContainElement(HaveField("ObjectMeta.Name", HavePrefix("registry-docker-io-shared-ca"))))
+			})
+		})
+
+		Context("when disruptive changes are deferred", func() {
+			statefulSetsOf := func() map[string]*appsv1.StatefulSet {
+				objects, err := managedresources.GetObjects(ctx, c, namespace, managedResourceName)
+				Expect(err).NotTo(HaveOccurred())
+
+				statefulSets := make(map[string]*appsv1.StatefulSet)
+				for _, obj := range objects {
+					if statefulSet, ok := obj.(*appsv1.StatefulSet); ok {
+						statefulSets[statefulSet.Name] = statefulSet
+					}
+				}
+				return statefulSets
+			}
+
+			JustBeforeEach(func() {
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				// Apply the StatefulSets and Secrets of the ManagedResource to the Shoot.
+				objects, err := managedresources.GetObjects(ctx, c, namespace, managedResourceName)
+				Expect(err).NotTo(HaveOccurred())
+				for _, obj := range objects {
+					switch obj.(type) {
+					case *appsv1.StatefulSet, *corev1.Secret:
+						Expect(shootClient.Create(ctx, obj)).To(Succeed())
+					}
+				}
+
+				values.Image = "some-new-image:some-tag"
+				values.Caches[0].GarbageCollection.TTL = metav1.Duration{Duration: 7 * 24 * time.Hour}
+				values.DeferDisruptiveChanges = true
+			})
+
+			It("should keep the deployed Pod templates", func() {
+				registryCaches = New(c, shootClient, namespace, secretsManager, values)
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+				Expect(registryCaches.UpstreamsWithPendingDisruptiveChanges()).To(ConsistOf("docker.io", "europe-docker.pkg.dev"))
+
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+				dockerConfigSecret := configSecretFor("registry-docker-io", "docker.io", configYAMLFor("336h0m0s", "", "", true))
+				arConfigSecret := configSecretFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", configYAMLFor("0s", "", "", false))
+
+				dockerSecretsManagerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+				Expect(ok).To(BeTrue())
+				dockerTLSSecret := tlsSecretFor("registry-docker-io", "docker.io", dockerSecretsManagerSecret.Data["ca.crt"], dockerSecretsManagerSecret.Data["ca.key"])
+
+				Expect(managedResource).To(consistOf(
+					dockerConfigSecret,
+					dockerTLSSecret,
+					statefulSetFor("registry-docker-io", "docker.io", "10Gi", dockerConfigSecret.Name, true, dockerTLSSecret.Name, nil, nil),
+					vpaFor("registry-docker-io"),
+					arConfigSecret,
+					statefulSetFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "20Gi", arConfigSecret.Name, false, "", ptr.To("premium"), nil),
+					vpaFor("registry-europe-docker-pkg-dev"),
+				))
+			})
+
+			It("should keep the deployed Pod templates on subsequent deployments", func() {
+				registryCaches = New(c, shootClient, namespace, secretsManager, values)
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+				registryCaches = New(c, shootClient, namespace, secretsManager, values)
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+				Expect(registryCaches.UpstreamsWithPendingDisruptiveChanges()).To(ConsistOf("docker.io", "europe-docker.pkg.dev"))
+
+				statefulSets := statefulSetsOf()
+				Expect(statefulSets["registry-docker-io"].Spec.Template.Spec.Containers[0].Image).To(Equal(image))
+				Expect(statefulSets["registry-europe-docker-pkg-dev"].Spec.Template.Spec.Containers[0].Image).To(Equal(image))
+			})
+
+			It("should apply changes of the endpoint immediately", func() {
+				values.Caches[1].HTTP.Port = ptr.To[int32](6000)
+
+				registryCaches = New(c, shootClient, namespace, secretsManager, values)
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+				Expect(registryCaches.UpstreamsWithPendingDisruptiveChanges()).To(ConsistOf("docker.io"))
+
+				statefulSets := statefulSetsOf()
+				Expect(statefulSets["registry-docker-io"].Spec.Template.Spec.Containers[0].Image).To(Equal(image))
+				Expect(statefulSets["registry-europe-docker-pkg-dev"].Spec.Template.Spec.Containers[0].Image).To(Equal("some-new-image:some-tag"))
+				Expect(statefulSets["registry-europe-docker-pkg-dev"].Spec.Template.Spec.Containers[0].Ports[0].ContainerPort).To(Equal(int32(6000)))
+			})
+
+			It("should not report pending changes when the Pod templates are unchanged", func() {
+				values.Image = image
+				values.Caches[0].GarbageCollection.TTL = metav1.Duration{Duration: 14 * 24 * time.Hour}
+
+				registryCaches = New(c, shootClient, namespace, secretsManager, values)
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+				Expect(registryCaches.UpstreamsWithPendingDisruptiveChanges()).To(BeEmpty())
+			})
+
+			It("should apply the changes when they are not deferred", func() {
+				values.DeferDisruptiveChanges = false
+
+				registryCaches = New(c, shootClient, namespace, secretsManager, values)
+				Expect(registryCaches.Deploy(ctx)).To(Succeed())
+				Expect(registryCaches.UpstreamsWithPendingDisruptiveChanges()).To(BeEmpty())
+
+				statefulSets := statefulSetsOf()
+				Expect(statefulSets["registry-docker-io"].Spec.Template.Spec.Containers[0].Image).To(Equal("some-new-image:some-tag"))
+				Expect(statefulSets["registry-europe-docker-pkg-dev"].Spec.Template.Spec.Containers[0].Image).To(Equal("some-new-image:some-tag"))
+			})
+		})
+
+		It("should detect strict registry caches without scrape target", func() {
+			values.Caches[0].Strict = ptr.To(true)
+
+			registryCaches = New(c, shootClient, namespace, secretsManager, values)
+			Expect(registryCaches.Deploy(ctx)).To(Succeed())
+
+			prometheusRule := &monitoringv1.PrometheusRule{ObjectMeta: metav1.ObjectMeta{Name: "shoot-registry-cache", Namespace: namespace}}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(prometheusRule), prometheusRule)).To(Succeed())
+			Expect(prometheusRule.Spec.Groups[0].Rules[2].Alert).To(Equal("RegistryCacheStrictNotAvailable"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[2].Expr.String()).To(Equal(`up{job="registry-cache-metrics", strict_mode="true"} == 0
+or
+absent(up{job="registry-cache-metrics", upstream_host="docker.io"})`))
+		})
+
 		It("should deploy a monitoring objects", func() {
 			Expect(registryCaches.Deploy(ctx)).To(Succeed())
 
@@ -761,11 +1592,47 @@ source /entrypoint.sh /etc/distribution/config.yml
 			Expect(prometheusRule.Labels).To(HaveKeyWithValue("prometheus", "shoot"))
 			Expect(prometheusRule.Labels).To(HaveKeyWithValue("component", "registry-cache"))
 			Expect(prometheusRule.Spec.Groups[0].Name).To(Equal("registry-cache.rules"))
-			Expect(prometheusRule.Spec.Groups[0].Rules).To(HaveLen(4))
+			Expect(prometheusRule.Spec.Groups[0].Rules).To(HaveLen(16))
 			Expect(prometheusRule.Spec.Groups[0].Rules[0].Alert).To(Equal("RegistryCachePersistentVolumeUsageCritical"))
 			Expect(prometheusRule.Spec.Groups[0].Rules[1].Alert).To(Equal("RegistryCachePersistentVolumeFullInFourDays"))
-			Expect(prometheusRule.Spec.Groups[0].Rules[2].Record).To(Equal("shoot:registry_proxy_pushed_bytes_total:sum"))
-			Expect(prometheusRule.Spec.Groups[0].Rules[3].Record).To(Equal("shoot:registry_proxy_pulled_bytes_total:sum"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[2].Alert).To(Equal("RegistryCacheStrictNotAvailable"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[2].Expr.String()).To(Equal(`up{job="registry-cache-metrics", strict_mode="true"} == 0`))
+			Expect(prometheusRule.Spec.Groups[0].Rules[3].Alert).To(Equal("RegistryCacheNotReady"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[4].Alert).To(Equal("RegistryCacheUpstreamAuthenticationFailing"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[4].Expr.String()).To(Equal(`sum by (upstream_host) (registry_cache:registry_cache_upstream_limiter_upstream_errors:rate5m{code=~"401|403"}) > 0`))
+			Expect(prometheusRule.Spec.Groups[0].Rules[5].Alert).To(Equal("RegistryCacheUpstreamRateLimited"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[5].Expr.String()).To(Equal(`sum by (upstream_host) (registry_cache:registry_cache_upstream_limiter_upstream_errors:rate5m{code="429"}) > 0`))
+			Expect(prometheusRule.Spec.Groups[0].Rules[6].Alert).To(Equal("RegistryCacheCertificateExpiresSoon"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[7].Record).To(Equal("registry_cache:registry_proxy_hits:ratio_rate5m"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[8].Record).To(Equal("registry_cache:registry_cache_upstream_limiter_upstream_errors:rate5m"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[8].Expr.String()).To(Equal(`sum by (upstream_host, code) (rate(registry_cache_upstream_limiter_upstream_responses_total{code=~"401|403|429|5.."}[5m]))`))
+			Expect(prometheusRule.Spec.Groups[0].Rules[9].Record).To(Equal("registry_cache:registry_http_request_duration_seconds:p99_rate5m"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[10].Record).To(Equal("registry_cache:registry_cache_upstream_limiter_throttled_requests:rate5m"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[11].Record).To(Equal("registry_cache:kube_pod_container_status_restarts:increase1h"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[12].Record).To(Equal("shoot:registry_proxy_pushed_bytes_total:sum"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[13].Record).To(Equal("shoot:registry_proxy_pulled_bytes_total:sum"))
+
+			caSecret, ok := secretsManager.Get("ca-extension-registry-cache")
+			Expect(ok).To(BeTrue())
+			caCertificate, err := utils.DecodeCertificate(caSecret.Data["ca.crt"])
+			Expect(err).NotTo(HaveOccurred())
+			dockerSecret, ok := secretsManager.Get("registry-docker-io-tls")
+			Expect(ok).To(BeTrue())
+			dockerCertificate, err := utils.DecodeCertificate(dockerSecret.Data["ca.crt"])
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(prometheusRule.Spec.Groups[0].Rules[14:]).To(Equal([]monitoringv1.Rule{
+				{
+					Record: "registry_cache:certificate_expiration_timestamp_seconds",
+					Expr:   intstr.FromString(fmt.Sprintf("vector(%d)", caCertificate.NotAfter.Unix())),
+					Labels: map[string]string{"certificate": "ca"},
+				},
+				{
+					Record: "registry_cache:certificate_expiration_timestamp_seconds",
+					Expr:   intstr.FromString(fmt.Sprintf("vector(%d)", dockerCertificate.NotAfter.Unix())),
+					Labels: map[string]string{"certificate": "server", "upstream_host": "docker.io"},
+				},
+			}))
 
 			scrapeConfig := &monitoringv1alpha1.ScrapeConfig{
 				ObjectMeta: metav1.ObjectMeta{
@@ -778,9 +1645,9 @@ source /entrypoint.sh /etc/distribution/config.yml
 			Expect(scrapeConfig.Labels).To(HaveKeyWithValue("component", "registry-cache"))
 			Expect(scrapeConfig.Spec.Authorization.Credentials.LocalObjectReference.Name).To(Equal("shoot-access-prometheus-shoot"))
 			Expect(scrapeConfig.Spec.KubernetesSDConfigs[0].APIServer).To(Equal(ptr.To("https://kube-apiserver:443")))
-			Expect(scrapeConfig.Spec.RelabelConfigs).To(HaveLen(5))
+			Expect(scrapeConfig.Spec.RelabelConfigs).To(HaveLen(6))
 			Expect(scrapeConfig.Spec.MetricRelabelConfigs).To(HaveLen(1))
-			Expect(scrapeConfig.Spec.MetricRelabelConfigs[0].Regex).To(Equal("^(registry_proxy_.+)$"))
+			Expect(scrapeConfig.Spec.MetricRelabelConfigs[0].Regex).To(Equal("^(registry_proxy_.+|registry_http_requests_total|registry_http_request_duration_seconds_bucket|registry_cache_upstream_limiter_.+)$"))
 		})
 	})
 
@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registrycaches
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	vpaautoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/utils/ptr"
+
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+)
+
+const (
+	registryCacheContainerName = "registry-cache"
+	registryCacheVolumeName    = "cache-volume"
+	registryConfigVolumeName   = "config-volume"
+	registryCertsVolumeName    = "certs-volume"
+)
+
+// StatefulSetValues is a set of configuration values for the StatefulSet of a registry cache.
+type StatefulSetValues struct {
+	// Name is the name of the StatefulSet.
+	Name string
+	// Namespace is the namespace of the StatefulSet.
+	Namespace string
+	// Upstream is the upstream of the registry cache.
+	Upstream string
+	// Image is the container image used for the registry cache.
+	Image string
+	// PodLabels are additional labels of the registry cache Pod.
+	PodLabels map[string]string
+	// PriorityClassName is the name of the PriorityClass of the registry cache Pod.
+	PriorityClassName string
+	// Port is the port on which the registry cache listens.
+	Port int32
+	// DebugPort is the port on which the registry cache serves the debug endpoint.
+	DebugPort int32
+	// Env are the environment variables of the registry cache container.
+	Env []corev1.EnvVar
+	// ConfigSecretName is the name of the Secret containing the configuration of the registry cache.
+	ConfigSecretName string
+	// Size is the size of the registry cache volume.
+	Size resource.Quantity
+	// StorageClassName is the name of the StorageClass of the registry cache volume.
+	StorageClassName *string
+}
+
+// NewStatefulSet returns the StatefulSet of a registry cache for the given values.
+func NewStatefulSet(values StatefulSetValues) *appsv1.StatefulSet {
+	labels := registryutils.GetLabels(values.Name, registryutils.ComputeUpstreamLabelValue(values.Upstream))
+
+	podLabels := registryutils.GetLabels(values.Name, registryutils.ComputeUpstreamLabelValue(values.Upstream))
+	for k, v := range values.PodLabels {
+		podLabels[k] = v
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      values.Name,
+			Namespace: values.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			ServiceName: values.Name,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: registryutils.GetLabels(values.Name, registryutils.ComputeUpstreamLabelValue(values.Upstream)),
+			},
+			Replicas: ptr.To[int32](1),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+				Spec: corev1.PodSpec{
+					AutomountServiceAccountToken: ptr.To(false),
+					PriorityClassName:            values.PriorityClassName,
+					SecurityContext: &corev1.PodSecurityContext{
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            registryCacheContainerName,
+							Image:           values.Image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("20m"),
+									corev1.ResourceMemory: resource.MustParse("50Mi"),
+								},
+							},
+							// Mitigation for https://github.com/distribution/distribution/issues/4478:
+							// The registry image entrypoint (https://github.com/distribution/distribution-library-image/blob/be4eca0a5f3af34a026d1e9294d63f3464c06131/Dockerfile#L31)
+							// is extended with a mitigation logic for https://github.com/distribution/distribution/issues/4478.
+							// Keep in sync the registry image entrypoint with the below invocation when updating the registry image version.
+							Command: []string{"/bin/sh", "-c", `REPO_ROOT=` + repositoryMountPath + `
+SCHEDULER_STATE_FILE="${REPO_ROOT}/scheduler-state.json"
+
+if [ -f "${SCHEDULER_STATE_FILE}" ]; then
+    if [ -s "${SCHEDULER_STATE_FILE}" ]; then
+        echo "The scheduler-state.json file exists and it is not empty. Won't clean up anything..."
+    else
+        echo "Detected a corrupted scheduler-state.json file"
+
+        echo "Cleaning up the scheduler-state.json file"
+        rm -f "${SCHEDULER_STATE_FILE}"
+
+        echo "Cleaning up the docker directory"
+        rm -rf "${REPO_ROOT}/docker"
+    fi
+else
+    echo "The scheduler-state.json file is not created yet. Won't clean up anything..."
+fi
+
+echo "Starting..."
+source /entrypoint.sh /etc/distribution/config.yml
+`},
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: values.Port,
+									Name:          "registry-cache",
+								},
+								{
+									ContainerPort: values.DebugPort,
+									Name:          "debug",
+								},
+							},
+							Env: values.Env,
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: ptr.To(false),
+							},
+							LivenessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/debug/health",
+										Port: intstr.FromInt32(values.DebugPort),
+									},
+								},
+								FailureThreshold: 6,
+								SuccessThreshold: 1,
+								PeriodSeconds:    20,
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/debug/health",
+										Port: intstr.FromInt32(values.DebugPort),
+									},
+								},
+								FailureThreshold: 3,
+								SuccessThreshold: 1,
+								PeriodSeconds:    20,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      registryCacheVolumeName,
+									ReadOnly:  false,
+									MountPath: repositoryMountPath,
+								},
+								{
+									Name:      registryConfigVolumeName,
+									MountPath: "/etc/distribution",
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: registryConfigVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: values.ConfigSecretName,
+								},
+							},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   registryCacheVolumeName,
+						Labels: registryutils.GetLabels(values.Name, registryutils.ComputeUpstreamLabelValue(values.Upstream)),
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: values.Size,
+							},
+						},
+						StorageClassName: values.StorageClassName,
+					},
+				},
+			},
+		},
+	}
+}
+
+// AddCertificatesVolume mounts the Secret with the given name containing the server certificate into the registry cache
+// container of the given StatefulSet.
+func AddCertificatesVolume(statefulSet *appsv1.StatefulSet, secretName string) {
+	statefulSet.Spec.Template.Spec.Volumes = append(statefulSet.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: registryCertsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  secretName,
+				DefaultMode: ptr.To[int32](0640),
+			},
+		},
+	})
+	statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts = append(statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      registryCertsVolumeName,
+		MountPath: certsMountPath,
+	})
+}
+
+// NewVerticalPodAutoscaler returns the VerticalPodAutoscaler for the StatefulSet of a registry cache with the given
+// name.
+func NewVerticalPodAutoscaler(name, namespace string) *vpaautoscalingv1.VerticalPodAutoscaler {
+	return &vpaautoscalingv1.VerticalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vpaautoscalingv1.VerticalPodAutoscalerSpec{
+			TargetRef: &autoscalingv1.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "StatefulSet",
+				Name:       name,
+			},
+			UpdatePolicy: &vpaautoscalingv1.PodUpdatePolicy{
+				UpdateMode: ptr.To(vpaautoscalingv1.UpdateModeAuto),
+			},
+			ResourcePolicy: &vpaautoscalingv1.PodResourcePolicy{
+				ContainerPolicies: []vpaautoscalingv1.ContainerResourcePolicy{
+					{
+						ContainerName:    vpaautoscalingv1.DefaultContainerResourcePolicy,
+						ControlledValues: ptr.To(vpaautoscalingv1.ContainerControlledValuesRequestsOnly),
+						MinAllowed: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("20Mi"),
+						},
+						MaxAllowed: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+}
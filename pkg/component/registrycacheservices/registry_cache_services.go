@@ -14,6 +14,7 @@ import (
 	"github.com/gardener/gardener/pkg/component"
 	"github.com/gardener/gardener/pkg/utils/managedresources"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
@@ -33,6 +34,8 @@ const (
 type Values struct {
 	// Caches are the registry caches to deploy.
 	Caches []api.RegistryCache
+	// ProviderType is the provider type of the Shoot. It is used to determine the annotations for internal load balancers.
+	ProviderType string
 	// KeepObjectsOnDestroy marks whether the ManagedResource's .spec.keepObjects will be set to true
 	// before ManagedResource deletion during the Destroy operation. When set to true, the deployed
 	// resources by ManagedResources won't be deleted, but the ManagedResource itself will be deleted.
@@ -103,20 +106,24 @@ func (r *registryCacheServices) WaitCleanup(ctx context.Context) error {
 }
 
 func (r *registryCacheServices) computeResourcesData() (map[string][]byte, error) {
-	var services []client.Object
+	var objects []client.Object
 
 	for _, cache := range r.values.Caches {
-		service := computeResourcesDataForService(&cache)
+		service := computeResourcesDataForService(&cache, r.values.ProviderType)
 
-		services = append(services, service)
+		objects = append(objects, service)
+
+		if cache.Exposure != nil && cache.Exposure.Type == api.ExposureTypeIngress {
+			objects = append(objects, computeResourcesDataForIngress(&cache, service))
+		}
 	}
 
 	registry := managedresources.NewRegistry(kubernetes.ShootScheme, kubernetes.ShootCodec, kubernetes.ShootSerializer)
 
-	return registry.AddAllAndSerialize(services...)
+	return registry.AddAllAndSerialize(objects...)
 }
 
-func computeResourcesDataForService(cache *api.RegistryCache) *corev1.Service {
+func computeResourcesDataForService(cache *api.RegistryCache, providerType string) *corev1.Service {
 	var (
 		upstreamLabel = registryutils.ComputeUpstreamLabelValue(cache.Upstream)
 		name          = "registry-" + strings.ReplaceAll(upstreamLabel, ".", "-")
@@ -139,17 +146,91 @@ func computeResourcesDataForService(cache *api.RegistryCache) *corev1.Service {
 			Selector: registryutils.GetLabels(name, upstreamLabel),
 			Ports: []corev1.ServicePort{{
 				Name:       "registry-cache",
-				Port:       constants.RegistryCachePort,
+				Port:       helper.Port(cache),
 				Protocol:   corev1.ProtocolTCP,
 				TargetPort: intstr.FromString("registry-cache"),
 			}},
 			Type: corev1.ServiceTypeClusterIP,
+			// The Service gets a cluster IP per IP family in dual-stack Shoots and a single cluster IP otherwise.
+			IPFamilyPolicy: ptr.To(corev1.IPFamilyPolicyPreferDualStack),
 		},
 	}
 
+	if cache.Exposure != nil {
+		switch cache.Exposure.Type {
+		case api.ExposureTypeLoadBalancer:
+			service.Spec.Type = corev1.ServiceTypeLoadBalancer
+			addAnnotations(service, cache.Exposure.Annotations)
+		case api.ExposureTypeInternalLoadBalancer:
+			service.Spec.Type = corev1.ServiceTypeLoadBalancer
+			addAnnotations(service, registryutils.InternalLoadBalancerAnnotations(providerType))
+			addAnnotations(service, cache.Exposure.Annotations)
+		case api.ExposureTypeIngress:
+			// The hostname is added to the DNS names of the server certificate of the registry cache.
+			metav1.SetMetaDataAnnotation(&service.ObjectMeta, constants.ExternalHostnameAnnotation, ptr.Deref(cache.Exposure.Hostname, ""))
+		}
+	}
+
 	return service
 }
 
+func computeResourcesDataForIngress(cache *api.RegistryCache, service *corev1.Service) *networkingv1.Ingress {
+	var (
+		hostname = ptr.Deref(cache.Exposure.Hostname, "")
+		pathType = networkingv1.PathTypePrefix
+	)
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service.Name,
+			Namespace: service.Namespace,
+			Labels:    service.Labels,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: cache.Exposure.IngressClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: hostname,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: service.Name,
+									Port: networkingv1.ServiceBackendPort{Number: service.Spec.Ports[0].Port},
+								},
+							},
+						}},
+					},
+				},
+			}},
+			TLS: []networkingv1.IngressTLS{{
+				Hosts:      []string{hostname},
+				SecretName: service.Name + "-ingress-tls",
+			}},
+		},
+	}
+
+	if service.Annotations[constants.SchemeAnnotation] == "https" {
+		// The registry cache serves HTTPS, hence the ingress controller has to connect to it via HTTPS.
+		metav1.SetMetaDataAnnotation(&ingress.ObjectMeta, "nginx.ingress.kubernetes.io/backend-protocol", "HTTPS")
+	}
+	// Image layers can be large, hence the request body size is not limited.
+	metav1.SetMetaDataAnnotation(&ingress.ObjectMeta, "nginx.ingress.kubernetes.io/proxy-body-size", "0")
+	for key, value := range cache.Exposure.Annotations {
+		metav1.SetMetaDataAnnotation(&ingress.ObjectMeta, key, value)
+	}
+
+	return ingress
+}
+
+func addAnnotations(service *corev1.Service, annotations map[string]string) {
+	for key, value := range annotations {
+		metav1.SetMetaDataAnnotation(&service.ObjectMeta, key, value)
+	}
+}
+
 func computeScheme(cache *api.RegistryCache) string {
 	scheme := "http"
 	if helper.TLSEnabled(cache) {
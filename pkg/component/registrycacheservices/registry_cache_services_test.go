@@ -21,6 +21,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/types"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -83,7 +84,8 @@ var _ = Describe("RegistryCacheServices", func() {
 						Protocol:   corev1.ProtocolTCP,
 						TargetPort: intstr.FromString("registry-cache"),
 					}},
-					Type: corev1.ServiceTypeClusterIP,
+					Type:           corev1.ServiceTypeClusterIP,
+					IPFamilyPolicy: ptr.To(corev1.IPFamilyPolicyPreferDualStack),
 				},
 			}
 		}
@@ -152,6 +154,108 @@ var _ = Describe("RegistryCacheServices", func() {
 				serviceFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "https://europe-docker.pkg.dev", "http"),
 			))
 		})
+
+		It("should successfully deploy the resources for exposed registry caches", func() {
+			values.ProviderType = "aws"
+			values.Caches = []api.RegistryCache{
+				{
+					Upstream: "docker.io",
+					Exposure: &api.Exposure{
+						Type:        api.ExposureTypeLoadBalancer,
+						Annotations: map[string]string{"foo": "bar"},
+					},
+				},
+				{
+					Upstream: "ghcr.io",
+					Exposure: &api.Exposure{
+						Type: api.ExposureTypeInternalLoadBalancer,
+					},
+				},
+				{
+					Upstream: "quay.io",
+					Exposure: &api.Exposure{
+						Type:             api.ExposureTypeIngress,
+						Hostname:         ptr.To("quay-cache.example.com"),
+						IngressClassName: ptr.To("nginx"),
+						Annotations:      map[string]string{"cert.gardener.cloud/purpose": "managed"},
+					},
+				},
+			}
+			registryCacheServices = New(c, c, namespace, values)
+
+			Expect(registryCacheServices.Deploy(ctx)).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+			dockerService := serviceFor("registry-docker-io", "docker.io", "https://registry-1.docker.io", "https")
+			dockerService.Spec.Type = corev1.ServiceTypeLoadBalancer
+			dockerService.Annotations["foo"] = "bar"
+
+			ghcrService := serviceFor("registry-ghcr-io", "ghcr.io", "https://ghcr.io", "https")
+			ghcrService.Spec.Type = corev1.ServiceTypeLoadBalancer
+			ghcrService.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"] = "true"
+
+			quayService := serviceFor("registry-quay-io", "quay.io", "https://quay.io", "https")
+			quayService.Annotations["external-hostname"] = "quay-cache.example.com"
+
+			pathType := networkingv1.PathTypePrefix
+			quayIngress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "registry-quay-io",
+					Namespace: "kube-system",
+					Labels: map[string]string{
+						"app":           "registry-quay-io",
+						"upstream-host": "quay.io",
+					},
+					Annotations: map[string]string{
+						"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+						"nginx.ingress.kubernetes.io/proxy-body-size":  "0",
+						"cert.gardener.cloud/purpose":                  "managed",
+					},
+				},
+				Spec: networkingv1.IngressSpec{
+					IngressClassName: ptr.To("nginx"),
+					Rules: []networkingv1.IngressRule{{
+						Host: "quay-cache.example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "registry-quay-io",
+											Port: networkingv1.ServiceBackendPort{Number: 5000},
+										},
+									},
+								}},
+							},
+						},
+					}},
+					TLS: []networkingv1.IngressTLS{{
+						Hosts:      []string{"quay-cache.example.com"},
+						SecretName: "registry-quay-io-ingress-tls",
+					}},
+				},
+			}
+
+			Expect(managedResource).To(consistOf(dockerService, ghcrService, quayService, quayIngress))
+		})
+
+		It("should successfully deploy the resources for registry caches with a custom port", func() {
+			values.Caches[0].HTTP = &api.HTTP{TLS: true, Port: ptr.To[int32](8443)}
+			registryCacheServices = New(c, c, namespace, values)
+
+			Expect(registryCacheServices.Deploy(ctx)).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(managedResource), managedResource)).To(Succeed())
+
+			dockerService := serviceFor("registry-docker-io", "docker.io", "https://registry-1.docker.io", "https")
+			dockerService.Spec.Ports[0].Port = 8443
+
+			Expect(managedResource).To(consistOf(
+				dockerService,
+				serviceFor("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev", "https://europe-docker.pkg.dev", "http"),
+			))
+		})
 	})
 
 	Describe("#Destroy", func() {
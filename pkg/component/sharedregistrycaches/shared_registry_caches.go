@@ -0,0 +1,451 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sharedregistrycaches
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	extensionssecretsmanager "github.com/gardener/gardener/extensions/pkg/util/secret/manager"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/gardener/gardener/pkg/component"
+	"github.com/gardener/gardener/pkg/controllerutils"
+	"github.com/gardener/gardener/pkg/utils"
+	kubernetesutils "github.com/gardener/gardener/pkg/utils/kubernetes"
+	"github.com/gardener/gardener/pkg/utils/kubernetes/health"
+	"github.com/gardener/gardener/pkg/utils/retry"
+	secretutils "github.com/gardener/gardener/pkg/utils/secrets"
+	secretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	vpaautoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/component/registrycaches"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/component/registrycaches/distribution"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/secrets"
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+)
+
+const (
+	// ManagerIdentity is the identity used for the Secrets Manager of the shared registry caches.
+	ManagerIdentity = "extension-registry-cache-shared"
+	// CAName is the name of the CA secret of the shared registry caches.
+	CAName = "ca-extension-registry-cache-shared"
+	// CABundleSecretName is the name of the Secret containing the CA bundle of the shared registry caches.
+	CABundleSecretName = "registry-cache-shared-ca-bundle"
+	// ManagedByLabel is the label marking the namespaces of the shared registry caches. Its value is constants.Origin.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+)
+
+// Values is a set of configuration values for the shared registry caches.
+type Values struct {
+	// Image is the container image used for the shared registry cache.
+	Image string
+	// Caches are the shared registry caches to deploy.
+	Caches []config.SharedRegistryCache
+	// LoadBalancerSourceRanges are the CIDRs from which the load balancers of the shared registry caches are reachable.
+	LoadBalancerSourceRanges []string
+	// Certificates contains settings for the certificates of the shared registry caches.
+	Certificates *config.Certificates
+}
+
+// New creates a new instance of component.DeployWaiter for the shared registry caches in the seed.
+func New(
+	client client.Client,
+	namespace string,
+	secretsManager secretsmanager.Interface,
+	values Values,
+) component.DeployWaiter {
+	return &sharedRegistryCaches{
+		client:         client,
+		namespace:      namespace,
+		secretsManager: secretsManager,
+		values:         values,
+	}
+}
+
+type sharedRegistryCaches struct {
+	client         client.Client
+	namespace      string
+	secretsManager secretsmanager.Interface
+	values         Values
+}
+
+// Deploy implements component.DeployWaiter.
+func (s *sharedRegistryCaches) Deploy(ctx context.Context) error {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: s.namespace}}
+	if _, err := controllerutils.GetAndCreateOrMergePatch(ctx, s.client, namespace, func() error {
+		metav1.SetMetaDataLabel(&namespace.ObjectMeta, ManagedByLabel, constants.Origin)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to create or update namespace %s: %w", s.namespace, err)
+	}
+
+	caValidity, serverCertificateValidity := secrets.Validities(s.values.Certificates)
+	secretConfigs := []extensionssecretsmanager.SecretConfigWithOptions{
+		{
+			Config: &secretutils.CertificateSecretConfig{
+				Name:       CAName,
+				CommonName: CAName,
+				CertType:   secretutils.CACert,
+				Validity:   ptr.To(caValidity),
+			},
+			Options: []secretsmanager.GenerateOption{secretsmanager.Persist()},
+		},
+	}
+
+	for _, cache := range s.values.Caches {
+		service, err := s.deployService(ctx, cache)
+		if err != nil {
+			return fmt.Errorf("failed to deploy Service for upstream %s: %w", cache.Upstream, err)
+		}
+
+		address := registryutils.LoadBalancerAddress(service)
+		if address == "" {
+			return fmt.Errorf("load balancer of Service %s is not ready yet", client.ObjectKeyFromObject(service))
+		}
+
+		secretConfigs = append(secretConfigs, extensionssecretsmanager.SecretConfigWithOptions{
+			Config: serverCertificateConfig(service, address, serverCertificateValidity),
+			Options: []secretsmanager.GenerateOption{
+				secretsmanager.SignedByCA(CAName, secretsmanager.UseOldCA),
+				secretsmanager.RenewAfterValidityPercentage(secrets.ServerCertificateRenewAfterValidityPercentage),
+			},
+		})
+	}
+
+	generatedSecrets, err := extensionssecretsmanager.GenerateAllSecrets(ctx, s.secretsManager, secretConfigs)
+	if err != nil {
+		return err
+	}
+
+	caBundleSecret, found := s.secretsManager.Get(CAName)
+	if !found {
+		return fmt.Errorf("secret %q not found", CAName)
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: CABundleSecretName, Namespace: s.namespace}}
+	if _, err := controllerutils.GetAndCreateOrMergePatch(ctx, s.client, secret, func() error {
+		secret.Data = map[string][]byte{
+			secretutils.DataKeyCertificateBundle: caBundleSecret.Data[secretutils.DataKeyCertificateBundle],
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to create or update CA bundle secret: %w", err)
+	}
+
+	for _, cache := range s.values.Caches {
+		tlsSecret, ok := generatedSecrets[tlsSecretName(cache.Upstream)]
+		if !ok {
+			return fmt.Errorf("secret for upstream %s not found", cache.Upstream)
+		}
+
+		if err := s.deployRegistryCache(ctx, cache, tlsSecret); err != nil {
+			return fmt.Errorf("failed to deploy shared registry cache for upstream %s: %w", cache.Upstream, err)
+		}
+	}
+
+	return s.deleteStaleRegistryCaches(ctx)
+}
+
+// deleteStaleRegistryCaches deletes the objects of shared registry caches which are no longer configured.
+func (s *sharedRegistryCaches) deleteStaleRegistryCaches(ctx context.Context) error {
+	selector, err := upstreamHostSelector()
+	if err != nil {
+		return err
+	}
+
+	desired := sets.New[string]()
+	for _, cache := range s.values.Caches {
+		desired.Insert(registryutils.ComputeKubernetesResourceName(cache.Upstream))
+	}
+
+	serviceList := &corev1.ServiceList{}
+	if err := s.client.List(ctx, serviceList, client.InNamespace(s.namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+
+	var objects []client.Object
+	for _, service := range serviceList.Items {
+		if desired.Has(service.Name) {
+			continue
+		}
+
+		objects = append(objects,
+			&vpaautoscalingv1.VerticalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: service.Name, Namespace: s.namespace}},
+			&policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: service.Name, Namespace: s.namespace}},
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: service.Name, Namespace: s.namespace}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: service.Name + "-config", Namespace: s.namespace}},
+			service.DeepCopy(),
+		)
+	}
+
+	return kubernetesutils.DeleteObjects(ctx, s.client, objects...)
+}
+
+// Destroy implements component.DeployWaiter.
+// Besides the objects of the shared registry caches, it deletes their volumes and the secrets of the secrets manager and
+// removes the label marking the namespace as namespace of shared registry caches.
+func (s *sharedRegistryCaches) Destroy(ctx context.Context) error {
+	selector, err := upstreamHostSelector()
+	if err != nil {
+		return err
+	}
+
+	var objects []client.Object
+	for _, list := range []client.ObjectList{&vpaautoscalingv1.VerticalPodAutoscalerList{}, &policyv1.PodDisruptionBudgetList{}, &appsv1.StatefulSetList{}, &corev1.ServiceList{}, &corev1.SecretList{}, &corev1.PersistentVolumeClaimList{}} {
+		if err := s.client.List(ctx, list, client.InNamespace(s.namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			objects = append(objects, item.(client.Object))
+		}
+	}
+	objects = append(objects, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: CABundleSecretName, Namespace: s.namespace}})
+
+	if err := kubernetesutils.DeleteObjects(ctx, s.client, objects...); err != nil {
+		return err
+	}
+
+	if err := s.client.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(s.namespace), client.MatchingLabels{secretsmanager.LabelKeyManagerIdentity: ManagerIdentity}); err != nil {
+		return fmt.Errorf("failed to delete secrets of the secrets manager: %w", err)
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := s.client.Get(ctx, client.ObjectKey{Name: s.namespace}, namespace); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFrom(namespace.DeepCopy())
+	delete(namespace.Labels, ManagedByLabel)
+	return s.client.Patch(ctx, namespace, patch)
+}
+
+// TimeoutWaitForStatefulSets is the timeout used while waiting for the shared registry cache StatefulSets to become
+// healthy or deleted.
+var TimeoutWaitForStatefulSets = 5 * time.Minute
+
+// Wait implements component.DeployWaiter.
+func (s *sharedRegistryCaches) Wait(ctx context.Context) error {
+	return retry.UntilTimeout(ctx, 5*time.Second, TimeoutWaitForStatefulSets, func(ctx context.Context) (bool, error) {
+		for _, cache := range s.values.Caches {
+			statefulSet := &appsv1.StatefulSet{}
+			if err := s.client.Get(ctx, client.ObjectKey{Name: registryutils.ComputeKubernetesResourceName(cache.Upstream), Namespace: s.namespace}, statefulSet); err != nil {
+				return retry.MinorError(err)
+			}
+			if err := health.CheckStatefulSet(statefulSet); err != nil {
+				return retry.MinorError(err)
+			}
+		}
+		return retry.Ok()
+	})
+}
+
+// WaitCleanup implements component.DeployWaiter.
+func (s *sharedRegistryCaches) WaitCleanup(ctx context.Context) error {
+	selector, err := upstreamHostSelector()
+	if err != nil {
+		return err
+	}
+
+	return retry.UntilTimeout(ctx, 5*time.Second, TimeoutWaitForStatefulSets, func(ctx context.Context) (bool, error) {
+		statefulSetList := &appsv1.StatefulSetList{}
+		if err := s.client.List(ctx, statefulSetList, client.InNamespace(s.namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return retry.SevereError(err)
+		}
+		if len(statefulSetList.Items) > 0 {
+			return retry.MinorError(fmt.Errorf("%d shared registry cache StatefulSets still exist", len(statefulSetList.Items)))
+		}
+		return retry.Ok()
+	})
+}
+
+// Endpoints returns the endpoints of the shared registry caches in the given namespace by upstream and
+// the CA bundle that has to be used to verify them. Shared registry caches whose load balancer is not
+// ready yet are skipped.
+func Endpoints(ctx context.Context, reader client.Reader, namespace string) (map[string]string, []byte, error) {
+	caBundleSecret := &corev1.Secret{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: CABundleSecretName, Namespace: namespace}, caBundleSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to get CA bundle secret of the shared registry caches: %w", err)
+	}
+
+	selector, err := upstreamHostSelector()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serviceList := &corev1.ServiceList{}
+	if err := reader.List(ctx, serviceList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, nil, fmt.Errorf("failed to list Services of the shared registry caches: %w", err)
+	}
+
+	endpoints := make(map[string]string, len(serviceList.Items))
+	for _, service := range serviceList.Items {
+		address := registryutils.LoadBalancerAddress(&service)
+		if address == "" {
+			continue
+		}
+
+		endpoints[service.Annotations[constants.UpstreamAnnotation]] = registryutils.Endpoint("https", address, constants.RegistryCachePort)
+	}
+
+	return endpoints, caBundleSecret.Data[secretutils.DataKeyCertificateBundle], nil
+}
+
+func (s *sharedRegistryCaches) deployService(ctx context.Context, cache config.SharedRegistryCache) (*corev1.Service, error) {
+	var (
+		upstreamLabel = registryutils.ComputeUpstreamLabelValue(cache.Upstream)
+		name          = registryutils.ComputeKubernetesResourceName(cache.Upstream)
+		service       = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.namespace}}
+	)
+
+	_, err := controllerutils.GetAndCreateOrMergePatch(ctx, s.client, service, func() error {
+		service.Labels = utils.MergeStringMaps(service.Labels, registryutils.GetLabels(name, upstreamLabel))
+		metav1.SetMetaDataAnnotation(&service.ObjectMeta, constants.UpstreamAnnotation, cache.Upstream)
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+		// The shared registry caches do not require authentication, hence they must not be reachable from everywhere.
+		service.Spec.LoadBalancerSourceRanges = s.values.LoadBalancerSourceRanges
+		service.Spec.Selector = registryutils.GetLabels(name, upstreamLabel)
+		service.Spec.Ports = kubernetesutils.ReconcileServicePorts(service.Spec.Ports, []corev1.ServicePort{{
+			Name:       "registry-cache",
+			Port:       constants.RegistryCachePort,
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromString("registry-cache"),
+		}}, corev1.ServiceTypeLoadBalancer)
+		return nil
+	})
+
+	return service, err
+}
+
+func (s *sharedRegistryCaches) deployRegistryCache(ctx context.Context, cache config.SharedRegistryCache, tlsSecret *corev1.Secret) error {
+	const debugPort = 5001
+
+	var (
+		upstreamLabel = registryutils.ComputeUpstreamLabelValue(cache.Upstream)
+		name          = registryutils.ComputeKubernetesResourceName(cache.Upstream)
+		ttl           = ptr.Deref(cache.GarbageCollectionTTL, metav1.Duration{Duration: 7 * 24 * time.Hour})
+	)
+
+	remoteURL := registryutils.DistributionRemoteURL(ptr.Deref(cache.RemoteURL, registryutils.GetUpstreamURL(cache.Upstream)))
+	configYAML, err := distribution.Marshal(registrycaches.NewConfig(remoteURL, ttl, constants.RegistryCachePort, debugPort, true))
+	if err != nil {
+		return err
+	}
+
+	configSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name + "-config", Namespace: s.namespace}}
+	if _, err := controllerutils.GetAndCreateOrMergePatch(ctx, s.client, configSecret, func() error {
+		configSecret.Labels = utils.MergeStringMaps(configSecret.Labels, registryutils.GetLabels(name, upstreamLabel))
+		configSecret.Data = map[string][]byte{"config.yml": configYAML}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	desiredStatefulSet := registrycaches.NewStatefulSet(registrycaches.StatefulSetValues{
+		Name:              name,
+		Namespace:         s.namespace,
+		Upstream:          cache.Upstream,
+		Image:             s.values.Image,
+		PriorityClassName: v1beta1constants.PriorityClassNameSeedSystem600,
+		Port:              constants.RegistryCachePort,
+		DebugPort:         debugPort,
+		// Mitigation for https://github.com/distribution/distribution/issues/4270.
+		Env:              []corev1.EnvVar{{Name: "OTEL_TRACES_EXPORTER", Value: "none"}},
+		ConfigSecretName: configSecret.Name,
+		Size:             ptr.Deref(cache.Size, resource.MustParse("100Gi")),
+		StorageClassName: cache.StorageClassName,
+	})
+	registrycaches.AddCertificatesVolume(desiredStatefulSet, tlsSecret.Name)
+	// The name of the config Secret does not change with its content, hence the Pod is restarted via a checksum.
+	metav1.SetMetaDataAnnotation(&desiredStatefulSet.Spec.Template.ObjectMeta, "checksum/secret-"+configSecret.Name, utils.ComputeSHA256Hex(configYAML))
+
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.namespace}}
+	if _, err := controllerutils.GetAndCreateOrMergePatch(ctx, s.client, statefulSet, func() error {
+		statefulSet.Labels = utils.MergeStringMaps(statefulSet.Labels, desiredStatefulSet.Labels)
+		statefulSet.Spec = desiredStatefulSet.Spec
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	desiredVPA := registrycaches.NewVerticalPodAutoscaler(name, s.namespace)
+	vpa := &vpaautoscalingv1.VerticalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.namespace}}
+	if _, err := controllerutils.GetAndCreateOrMergePatch(ctx, s.client, vpa, func() error {
+		vpa.Labels = utils.MergeStringMaps(vpa.Labels, registryutils.GetLabels(name, upstreamLabel))
+		vpa.Spec = desiredVPA.Spec
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	pdb := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.namespace}}
+	_, err = controllerutils.GetAndCreateOrMergePatch(ctx, s.client, pdb, func() error {
+		pdb.Labels = utils.MergeStringMaps(pdb.Labels, registryutils.GetLabels(name, upstreamLabel))
+		pdb.Spec = policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable:             ptr.To(intstr.FromInt32(1)),
+			Selector:                   desiredStatefulSet.Spec.Selector,
+			UnhealthyPodEvictionPolicy: ptr.To(policyv1.AlwaysAllow),
+		}
+		return nil
+	})
+
+	return err
+}
+
+func serverCertificateConfig(service *corev1.Service, address string, validity time.Duration) *secretutils.CertificateSecretConfig {
+	name := tlsSecretName(service.Annotations[constants.UpstreamAnnotation])
+	certConfig := &secretutils.CertificateSecretConfig{
+		Name:                        name,
+		CommonName:                  name,
+		CertType:                    secretutils.ServerCert,
+		DNSNames:                    kubernetesutils.DNSNamesForService(service.Name, service.Namespace),
+		Validity:                    ptr.To(validity),
+		SkipPublishingCACertificate: true,
+	}
+
+	if ip := net.ParseIP(address); ip != nil {
+		certConfig.IPAddresses = []net.IP{ip}
+	} else {
+		certConfig.DNSNames = append(certConfig.DNSNames, address)
+	}
+
+	return certConfig
+}
+
+func tlsSecretName(upstream string) string {
+	return registryutils.ComputeKubernetesResourceName(upstream) + "-tls"
+}
+
+func upstreamHostSelector() (labels.Selector, error) {
+	requirement, err := labels.NewRequirement(constants.UpstreamHostLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create label selector: %w", err)
+	}
+
+	return labels.NewSelector().Add(*requirement), nil
+}
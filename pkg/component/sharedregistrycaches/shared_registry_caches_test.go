@@ -0,0 +1,234 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sharedregistrycaches_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/component"
+	"github.com/gardener/gardener/pkg/utils"
+	secretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager"
+	fakesecretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager/fake"
+	. "github.com/gardener/gardener/pkg/utils/test/matchers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	vpaautoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/component/sharedregistrycaches"
+)
+
+var _ = Describe("SharedRegistryCaches", func() {
+	const (
+		namespace = "registry-cache-shared"
+		image     = "some-image:some-tag"
+	)
+
+	var (
+		ctx  = context.Background()
+		size = resource.MustParse("200Gi")
+
+		c                    client.Client
+		secretsManager       secretsmanager.Interface
+		values               Values
+		sharedRegistryCaches component.DeployWaiter
+
+		setLoadBalancerIngress = func(name string, ingress corev1.LoadBalancerIngress) {
+			service := &corev1.Service{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, service)).To(Succeed())
+			service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{ingress}
+			Expect(c.Status().Update(ctx, service)).To(Succeed())
+		}
+	)
+
+	BeforeEach(func() {
+		c = fakeclient.NewClientBuilder().WithScheme(kubernetes.SeedScheme).WithStatusSubresource(&corev1.Service{}).Build()
+		secretsManager = fakesecretsmanager.New(c, namespace)
+		values = Values{
+			Image: image,
+			Caches: []config.SharedRegistryCache{
+				{
+					Upstream:         "docker.io",
+					RemoteURL:        ptr.To("https://registry-1.docker.io"),
+					Size:             &size,
+					StorageClassName: ptr.To("premium"),
+				},
+				{
+					Upstream: "ghcr.io",
+				},
+			},
+			LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+		}
+	})
+
+	JustBeforeEach(func() {
+		sharedRegistryCaches = New(c, namespace, secretsManager, values)
+	})
+
+	Describe("#Deploy", func() {
+		It("should return error when the load balancer is not ready yet", func() {
+			Expect(sharedRegistryCaches.Deploy(ctx)).To(MatchError(ContainSubstring("load balancer of Service registry-cache-shared/registry-docker-io is not ready yet")))
+
+			service := &corev1.Service{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, service)).To(Succeed())
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+			Expect(service.Spec.LoadBalancerSourceRanges).To(ConsistOf("10.0.0.0/8"))
+			Expect(service.Annotations).To(HaveKeyWithValue("upstream", "docker.io"))
+		})
+
+		It("should successfully deploy the resources", func() {
+			Expect(sharedRegistryCaches.Deploy(ctx)).NotTo(Succeed())
+			setLoadBalancerIngress("registry-docker-io", corev1.LoadBalancerIngress{IP: "10.0.0.1"})
+			Expect(sharedRegistryCaches.Deploy(ctx)).NotTo(Succeed())
+			setLoadBalancerIngress("registry-ghcr-io", corev1.LoadBalancerIngress{Hostname: "ghcr.example.com"})
+			Expect(sharedRegistryCaches.Deploy(ctx)).To(Succeed())
+
+			Expect(c.Get(ctx, client.ObjectKey{Name: namespace}, &corev1.Namespace{})).To(Succeed())
+
+			statefulSet := &appsv1.StatefulSet{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.Template.Spec.Containers[0].Image).To(Equal(image))
+			Expect(statefulSet.Spec.Template.Spec.PriorityClassName).To(Equal("gardener-system-600"))
+			Expect(statefulSet.Spec.Template.Annotations).To(HaveKey("checksum/secret-registry-docker-io-config"))
+			Expect(statefulSet.Spec.VolumeClaimTemplates[0].Spec.StorageClassName).To(Equal(ptr.To("premium")))
+			Expect(statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()).To(Equal("200Gi"))
+
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-ghcr-io", Namespace: namespace}, statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()).To(Equal("100Gi"))
+
+			vpa := &vpaautoscalingv1.VerticalPodAutoscaler{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, vpa)).To(Succeed())
+			Expect(vpa.Spec.TargetRef.Name).To(Equal("registry-docker-io"))
+
+			pdb := &policyv1.PodDisruptionBudget{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-ghcr-io", Namespace: namespace}, pdb)).To(Succeed())
+			Expect(pdb.Spec.MaxUnavailable).To(PointTo(Equal(intstr.FromInt32(1))))
+			Expect(pdb.Spec.Selector).To(Equal(statefulSet.Spec.Selector))
+
+			configSecret := &corev1.Secret{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io-config", Namespace: namespace}, configSecret)).To(Succeed())
+			Expect(string(configSecret.Data["config.yml"])).To(And(
+				ContainSubstring("remoteurl: https://registry-1.docker.io"),
+				ContainSubstring("ttl: 168h0m0s"),
+				ContainSubstring("certificate: /etc/distribution/certs/tls.crt"),
+			))
+
+			_, ok := secretsManager.Get("registry-docker-io-tls")
+			Expect(ok).To(BeTrue())
+
+			Expect(c.Get(ctx, client.ObjectKey{Name: CABundleSecretName, Namespace: namespace}, &corev1.Secret{})).To(Succeed())
+
+			endpoints, _, err := Endpoints(ctx, c, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(endpoints).To(Equal(map[string]string{
+				"docker.io": "https://10.0.0.1:5000",
+				"ghcr.io":   "https://ghcr.example.com:5000",
+			}))
+		})
+
+		It("should issue the certificates with the configured validities", func() {
+			values.Certificates = &config.Certificates{
+				CAValidity:                &metav1.Duration{Duration: 365 * 24 * time.Hour},
+				ServerCertificateValidity: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+			}
+			sharedRegistryCaches = New(c, namespace, secretsManager, values)
+
+			Expect(sharedRegistryCaches.Deploy(ctx)).NotTo(Succeed())
+			setLoadBalancerIngress("registry-docker-io", corev1.LoadBalancerIngress{IP: "10.0.0.1"})
+			Expect(sharedRegistryCaches.Deploy(ctx)).NotTo(Succeed())
+			setLoadBalancerIngress("registry-ghcr-io", corev1.LoadBalancerIngress{Hostname: "ghcr.example.com"})
+			Expect(sharedRegistryCaches.Deploy(ctx)).To(Succeed())
+
+			caSecret, ok := secretsManager.Get(CAName)
+			Expect(ok).To(BeTrue())
+			caCertificate, err := utils.DecodeCertificate(caSecret.Data["ca.crt"])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(caCertificate.NotAfter.Sub(caCertificate.NotBefore)).To(BeNumerically("~", 365*24*time.Hour, time.Hour))
+
+			tlsSecret, ok := secretsManager.Get("registry-docker-io-tls")
+			Expect(ok).To(BeTrue())
+			// The fake secrets manager does not sign the server certificates with the CA.
+			tlsCertificate, err := utils.DecodeCertificate(tlsSecret.Data["ca.crt"])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tlsCertificate.NotAfter.Sub(tlsCertificate.NotBefore)).To(BeNumerically("~", 30*24*time.Hour, time.Hour))
+		})
+
+		It("should delete shared registry caches which are no longer configured", func() {
+			Expect(sharedRegistryCaches.Deploy(ctx)).NotTo(Succeed())
+			setLoadBalancerIngress("registry-docker-io", corev1.LoadBalancerIngress{IP: "10.0.0.1"})
+			Expect(sharedRegistryCaches.Deploy(ctx)).NotTo(Succeed())
+			setLoadBalancerIngress("registry-ghcr-io", corev1.LoadBalancerIngress{IP: "10.0.0.2"})
+			Expect(sharedRegistryCaches.Deploy(ctx)).To(Succeed())
+
+			values.Caches = values.Caches[:1]
+			Expect(New(c, namespace, secretsManager, values).Deploy(ctx)).To(Succeed())
+
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-ghcr-io", Namespace: namespace}, &appsv1.StatefulSet{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-ghcr-io", Namespace: namespace}, &vpaautoscalingv1.VerticalPodAutoscaler{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-ghcr-io", Namespace: namespace}, &policyv1.PodDisruptionBudget{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-ghcr-io", Namespace: namespace}, &corev1.Service{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-ghcr-io-config", Namespace: namespace}, &corev1.Secret{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, &appsv1.StatefulSet{})).To(Succeed())
+		})
+	})
+
+	Describe("#Endpoints", func() {
+		It("should return no endpoints when the CA bundle does not exist", func() {
+			endpoints, caBundle, err := Endpoints(ctx, c, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(endpoints).To(BeNil())
+			Expect(caBundle).To(BeNil())
+		})
+	})
+
+	Describe("#Destroy", func() {
+		It("should successfully destroy all resources", func() {
+			Expect(sharedRegistryCaches.Deploy(ctx)).NotTo(Succeed())
+			setLoadBalancerIngress("registry-docker-io", corev1.LoadBalancerIngress{IP: "10.0.0.1"})
+			Expect(sharedRegistryCaches.Deploy(ctx)).NotTo(Succeed())
+			setLoadBalancerIngress("registry-ghcr-io", corev1.LoadBalancerIngress{IP: "10.0.0.2"})
+			Expect(sharedRegistryCaches.Deploy(ctx)).To(Succeed())
+
+			pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Name:      "cache-volume-registry-docker-io-0",
+				Namespace: namespace,
+				Labels:    map[string]string{"app": "registry-docker-io", "upstream-host": "docker.io"},
+			}}
+			Expect(c.Create(ctx, pvc)).To(Succeed())
+			caSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      "ca-extension-registry-cache-shared-1a2b3c4d",
+				Namespace: namespace,
+				Labels:    map[string]string{"manager-identity": ManagerIdentity},
+			}}
+			Expect(c.Create(ctx, caSecret)).To(Succeed())
+
+			Expect(sharedRegistryCaches.Destroy(ctx)).To(Succeed())
+
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(pvc), &corev1.PersistentVolumeClaim{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(caSecret), &corev1.Secret{})).To(BeNotFoundError())
+			ns := &corev1.Namespace{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: namespace}, ns)).To(Succeed())
+			Expect(ns.Labels).NotTo(HaveKey(ManagedByLabel))
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, &appsv1.StatefulSet{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, &vpaautoscalingv1.VerticalPodAutoscaler{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, &policyv1.PodDisruptionBudget{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, &corev1.Service{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io-config", Namespace: namespace}, &corev1.Secret{})).To(BeNotFoundError())
+			Expect(c.Get(ctx, client.ObjectKey{Name: CABundleSecretName, Namespace: namespace}, &corev1.Secret{})).To(BeNotFoundError())
+		})
+	})
+})
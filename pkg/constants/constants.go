@@ -14,8 +14,18 @@ const (
 
 	// UpstreamHostLabel is a label on registry cache resources (Service, StatefulSet) which denotes the upstream host.
 	UpstreamHostLabel = "upstream-host"
-	// RegistryCachePort is the port on which the pull through cache serves requests.
+	// StrictModeLabel is a label on registry cache Pods which denotes that containerd does not fall back to the upstream
+	// registry when the registry cache is not available.
+	StrictModeLabel = "strict-mode"
+	// RegistryCachePort is the default port on which the pull through cache serves requests.
 	RegistryCachePort = 5000
+	// RegistryCacheDebugPort is the default port of the debug endpoint of the pull through cache.
+	RegistryCacheDebugPort = 5001
+	// UpstreamLimiterPort is the port on which the upstream limiter sidecar proxies the requests to the upstream.
+	// The upstream limiter listens on the loopback interface only.
+	UpstreamLimiterPort = 5002
+	// UpstreamLimiterMetricsPort is the port on which the upstream limiter sidecar serves its metrics and health checks.
+	UpstreamLimiterMetricsPort = 5003
 
 	// RemoteURLAnnotation is an annotation on registry cache Service which denotes the upstream registry URL.
 	RemoteURLAnnotation = "remote-url"
@@ -24,4 +34,11 @@ const (
 	// SchemeAnnotation is an annotation on registry cache Service which donotes the scheme used to access the registry cache
 	// Supported values are "http" and "https".
 	SchemeAnnotation = "scheme"
+	// ExternalHostnameAnnotation is an annotation on registry cache Service which denotes the hostname under which the
+	// registry cache is exposed via an Ingress.
+	ExternalHostnameAnnotation = "external-hostname"
+
+	// ForceDisruptiveChangesAnnotation is an annotation on the Shoot which denotes that disruptive changes of the
+	// registry caches are applied immediately instead of being deferred to the maintenance time window of the Shoot.
+	ForceDisruptiveChangesAnnotation = "registry-cache.extensions.gardener.cloud/force-disruptive-changes"
 )
@@ -7,16 +7,21 @@ package extension
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	extensionsconfigv1alpha1 "github.com/gardener/gardener/extensions/pkg/apis/config/v1alpha1"
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/extension"
 	"github.com/gardener/gardener/extensions/pkg/util"
 	extensionssecretsmanager "github.com/gardener/gardener/extensions/pkg/util/secret/manager"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
 	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
 	"github.com/gardener/gardener/pkg/component"
+	gardenerutils "github.com/gardener/gardener/pkg/utils/gardener"
+	kubernetesutils "github.com/gardener/gardener/pkg/utils/kubernetes"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -24,17 +29,23 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
+	kubernetesclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/component-base/version"
 	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/gardener/gardener-extension-registry-cache/imagevector"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/helper"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha3"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/component/registrycaches"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/component/registrycacheservices"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/component/sharedregistrycaches"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/secrets"
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
 )
 
 // NewActuator returns an actuator responsible for registry-cache Extension resources.
@@ -62,6 +73,8 @@ func (a *actuator) Reconcile(ctx context.Context, logger logr.Logger, ex *extens
 		return fmt.Errorf("failed to get cluster: %w", err)
 	}
 
+	// The Services of the registry caches and the provider status are kept while the Shoot is hibernated, so that the
+	// OperatingSystemConfig of the Nodes created on wake-up is mutated with stable endpoints of the registry caches.
 	if v1beta1helper.HibernationIsEnabled(cluster.Shoot) {
 		return nil
 	}
@@ -83,7 +96,8 @@ func (a *actuator) Reconcile(ctx context.Context, logger logr.Logger, ex *extens
 	}
 
 	registryCacheServices := registrycacheservices.New(a.client, a.apiReader, namespace, registrycacheservices.Values{
-		Caches: registryConfig.Caches,
+		Caches:       registryConfig.Caches,
+		ProviderType: cluster.Shoot.Spec.Provider.Type,
 	})
 
 	if err = registryCacheServices.Deploy(ctx); err != nil {
@@ -94,12 +108,17 @@ func (a *actuator) Reconcile(ctx context.Context, logger logr.Logger, ex *extens
 		return fmt.Errorf("failed to wait the registry cache services component to be healthy: %w", err)
 	}
 
-	services, err := a.fetchRegistryCacheServices(ctx, namespace, registryConfig)
+	shootRESTConfig, shootClient, err := util.NewClientForShoot(ctx, a.client, namespace, client.Options{}, extensionsconfigv1alpha1.RESTOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create shoot client: %w", err)
+	}
+
+	services, err := fetchRegistryCacheServices(ctx, shootClient, registryConfig)
 	if err != nil {
 		return fmt.Errorf("failed to fetch registry cache Services: %w", err)
 	}
 
-	secretConfigs := secrets.ConfigsFor([]corev1.Service{})
+	secretConfigs := secrets.ConfigsFor([]corev1.Service{}, a.config.Certificates)
 	secretsManager, err := extensionssecretsmanager.SecretsManagerForCluster(ctx, logger.WithName("secretsmanager"), clock.RealClock{}, a.client, cluster, secrets.ManagerIdentity, secretConfigs)
 	if err != nil {
 		return err
@@ -110,12 +129,41 @@ func (a *actuator) Reconcile(ctx context.Context, logger logr.Logger, ex *extens
 		return fmt.Errorf("failed to find the registry image: %w", err)
 	}
 
-	registryCaches := registrycaches.New(a.client, namespace, secretsManager, registrycaches.Values{
-		Image:              image.String(),
-		VPAEnabled:         v1beta1helper.ShootWantsVerticalPodAutoscaler(cluster.Shoot),
-		Services:           services,
-		Caches:             registryConfig.Caches,
-		ResourceReferences: cluster.Shoot.Spec.Resources,
+	redisImage, err := imagevector.ImageVector().FindImage("redis")
+	if err != nil {
+		return fmt.Errorf("failed to find the redis image: %w", err)
+	}
+
+	upstreamLimiterImage, err := imagevector.ImageVector().FindImage("upstream-limiter")
+	if err != nil {
+		return fmt.Errorf("failed to find the upstream-limiter image: %w", err)
+	}
+	// The upstream limiter image is built from this repository, hence its tag is the version of the extension.
+	upstreamLimiterImage.WithOptionalTag(version.Get().GitVersion)
+
+	var (
+		sharedCacheEndpoints map[string]string
+		sharedCacheCABundle  []byte
+	)
+	if a.config.SharedCache != nil {
+		sharedCacheEndpoints, sharedCacheCABundle, err = sharedregistrycaches.Endpoints(ctx, a.apiReader, a.config.SharedCache.Namespace)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the shared registry cache endpoints: %w", err)
+		}
+	}
+
+	registryCaches := registrycaches.New(a.client, shootClient, namespace, secretsManager, registrycaches.Values{
+		Image:                  image.String(),
+		RedisImage:             redisImage.String(),
+		UpstreamLimiterImage:   upstreamLimiterImage.String(),
+		VPAEnabled:             v1beta1helper.ShootWantsVerticalPodAutoscaler(cluster.Shoot),
+		Services:               services,
+		Certificates:           a.config.Certificates,
+		Caches:                 registryConfig.Caches,
+		ResourceReferences:     cluster.Shoot.Spec.Resources,
+		SharedCacheEndpoints:   sharedCacheEndpoints,
+		SharedCacheCABundle:    sharedCacheCABundle,
+		DeferDisruptiveChanges: !disruptiveChangesAllowed(cluster.Shoot, clock.RealClock{}),
 	})
 
 	if err = registryCaches.Deploy(ctx); err != nil {
@@ -133,16 +181,48 @@ func (a *actuator) Reconcile(ctx context.Context, logger logr.Logger, ex *extens
 		}
 	}
 
-	registryStatus := computeProviderStatus(services, registryCaches.CASecretName())
+	registryStatus, err := computeProviderStatus(services, registryConfig, registryCaches.CASecretName(), registryCaches.UpstreamsWithPendingDisruptiveChanges(), cluster.Shoot.Spec.Resources)
+	if err != nil {
+		return fmt.Errorf("failed to compute provider status: %w", err)
+	}
+
+	// New registry caches are advertised to the Nodes via the provider status only when they are ready. Registry caches
+	// which do not become ready within the timeout are omitted from the provider status and the reconciliation fails.
+	var notReadyErr error
+	if waitForNewRegistryCaches(cluster.Shoot) {
+		newServices, err := a.newRegistryCacheServices(ex, services)
+		if err != nil {
+			return fmt.Errorf("failed to determine the new registry caches: %w", err)
+		}
+
+		if len(newServices) > 0 {
+			shootClientSet, err := kubernetesclientset.NewForConfig(shootRESTConfig)
+			if err != nil {
+				return fmt.Errorf("failed to create shoot clientset: %w", err)
+			}
+
+			var notReadyUpstreams []string
+			notReadyUpstreams, notReadyErr = waitUntilRegistryCachesReady(ctx, shootClient, shootClientSet, newServices)
+			registryStatus.Caches = slices.DeleteFunc(registryStatus.Caches, func(cache v1alpha3.RegistryCacheStatus) bool {
+				return slices.Contains(notReadyUpstreams, cache.Upstream)
+			})
+		}
+	}
 
 	if err = a.updateProviderStatus(ctx, ex, registryStatus); err != nil {
 		return fmt.Errorf("failed to update Extension status: %w", err)
 	}
 
+	// The secrets of the registry caches which are not ready yet are generated in this reconciliation, hence they are
+	// kept by the cleanup.
 	if err = secretsManager.Cleanup(ctx); err != nil {
 		return fmt.Errorf("failed to cleanup secrets: %w", err)
 	}
 
+	if notReadyErr != nil {
+		return fmt.Errorf("new registry caches are not advertised to the Nodes until they are ready: %w", notReadyErr)
+	}
+
 	return nil
 }
 
@@ -164,7 +244,7 @@ func (a *actuator) Delete(ctx context.Context, logger logr.Logger, ex *extension
 		return fmt.Errorf("failed to destroy the registry cache services component: %w", err)
 	}
 
-	registryCaches := registrycaches.New(a.client, namespace, secretsManager, registrycaches.Values{})
+	registryCaches := registrycaches.New(a.client, nil, namespace, secretsManager, registrycaches.Values{})
 	if err := component.OpDestroyAndWait(registryCaches).Destroy(ctx); err != nil {
 		return fmt.Errorf("failed to destroy the registry caches component: %w", err)
 	}
@@ -188,7 +268,7 @@ func (a *actuator) Migrate(ctx context.Context, _ logr.Logger, ex *extensionsv1a
 		return fmt.Errorf("failed to destroy the registry cache services component: %w", err)
 	}
 
-	registryCaches := registrycaches.New(a.client, namespace, nil, registrycaches.Values{
+	registryCaches := registrycaches.New(a.client, nil, namespace, nil, registrycaches.Values{
 		KeepObjectsOnDestroy: true,
 	})
 	if err := component.OpDestroyAndWait(registryCaches).Destroy(ctx); err != nil {
@@ -219,7 +299,7 @@ func (a *actuator) ForceDelete(ctx context.Context, logger logr.Logger, ex *exte
 		return fmt.Errorf("failed to destroy the registry cache services component: %w", err)
 	}
 
-	registryCaches := registrycaches.New(a.client, namespace, secretsManager, registrycaches.Values{})
+	registryCaches := registrycaches.New(a.client, nil, namespace, secretsManager, registrycaches.Values{})
 	if err := registryCaches.Destroy(ctx); err != nil {
 		return fmt.Errorf("failed to destroy the registry caches component: %w", err)
 	}
@@ -227,12 +307,7 @@ func (a *actuator) ForceDelete(ctx context.Context, logger logr.Logger, ex *exte
 	return secretsManager.Cleanup(ctx)
 }
 
-func (a *actuator) fetchRegistryCacheServices(ctx context.Context, namespace string, registryConfig *api.RegistryConfig) ([]corev1.Service, error) {
-	_, shootClient, err := util.NewClientForShoot(ctx, a.client, namespace, client.Options{}, extensionsconfigv1alpha1.RESTOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create shoot client: %w", err)
-	}
-
+func fetchRegistryCacheServices(ctx context.Context, shootClient client.Client, registryConfig *api.RegistryConfig) ([]corev1.Service, error) {
 	selector := labels.NewSelector()
 	requirement, err := labels.NewRequirement(constants.UpstreamHostLabel, selection.Exists, nil)
 	if err != nil {
@@ -252,14 +327,56 @@ func (a *actuator) fetchRegistryCacheServices(ctx context.Context, namespace str
 	return serviceList.Items, nil
 }
 
-func computeProviderStatus(services []corev1.Service, caSecretName *string) *v1alpha3.RegistryStatus {
-	caches := make([]v1alpha3.RegistryCacheStatus, 0, len(services))
+func computeProviderStatus(services []corev1.Service, registryConfig *api.RegistryConfig, caSecretName *string, upstreamsWithPendingDisruptiveChanges []string, resources []gardencorev1beta1.NamedResourceReference) (*v1alpha3.RegistryStatus, error) {
+	cachesByUpstream := make(map[string]api.RegistryCache, len(registryConfig.Caches))
+	for _, cache := range registryConfig.Caches {
+		cachesByUpstream[cache.Upstream] = cache
+	}
+
+	cachesStatus := make([]v1alpha3.RegistryCacheStatus, 0, len(services))
 	for _, service := range services {
-		caches = append(caches, v1alpha3.RegistryCacheStatus{
-			Upstream:  service.Annotations[constants.UpstreamAnnotation],
-			Endpoint:  fmt.Sprintf("%s://%s:%d", service.Annotations[constants.SchemeAnnotation], service.Spec.ClusterIP, constants.RegistryCachePort),
-			RemoteURL: service.Annotations[constants.RemoteURLAnnotation],
-		})
+		upstream := service.Annotations[constants.UpstreamAnnotation]
+		cache := cachesByUpstream[upstream]
+
+		var capabilities []v1alpha3.RegistryCacheCapability
+		for _, capability := range cache.Capabilities {
+			capabilities = append(capabilities, v1alpha3.RegistryCacheCapability(capability))
+		}
+
+		var endpoints []string
+		for _, clusterIP := range registryutils.ClusterIPs(&service) {
+			endpoints = append(endpoints, registryutils.Endpoint(service.Annotations[constants.SchemeAnnotation], clusterIP, servicePort(service)))
+		}
+		if len(endpoints) == 0 {
+			return nil, fmt.Errorf("service %s does not have a cluster IP", client.ObjectKeyFromObject(&service))
+		}
+
+		cacheStatus := v1alpha3.RegistryCacheStatus{
+			Upstream:                 upstream,
+			Endpoint:                 endpoints[0],
+			Endpoints:                endpoints,
+			RemoteURL:                service.Annotations[constants.RemoteURLAnnotation],
+			Strict:                   ptr.Deref(cache.Strict, false),
+			Capabilities:             capabilities,
+			ProvidedCertificate:      helper.TLSCertificateProvided(&cache),
+			ExternalEndpoint:         externalEndpoint(service),
+			PendingDisruptiveChanges: slices.Contains(upstreamsWithPendingDisruptiveChanges, upstream),
+		}
+
+		if helper.HostnameEndpointsEnabled(registryConfig) {
+			// The hostname is covered by the DNS names of the server certificate issued by the extension.
+			cacheStatus.Hostname = ptr.To(fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace))
+		}
+
+		if cacheStatus.ProvidedCertificate && cache.HTTP.CABundleSecretReferenceName != nil {
+			ref := v1beta1helper.GetResourceByName(resources, *cache.HTTP.CABundleSecretReferenceName)
+			if ref == nil || ref.ResourceRef.Kind != "Secret" {
+				return nil, fmt.Errorf("failed to find referenced resource with name %s and kind Secret", *cache.HTTP.CABundleSecretReferenceName)
+			}
+			cacheStatus.CABundleSecretName = ptr.To(v1beta1constants.ReferencedResourcesPrefix + ref.ResourceRef.Name)
+		}
+
+		cachesStatus = append(cachesStatus, cacheStatus)
 	}
 
 	return &v1alpha3.RegistryStatus{
@@ -267,9 +384,44 @@ func computeProviderStatus(services []corev1.Service, caSecretName *string) *v1a
 			APIVersion: v1alpha3.SchemeGroupVersion.String(),
 			Kind:       "RegistryStatus",
 		},
-		Caches:       caches,
+		Caches:       cachesStatus,
 		CASecretName: caSecretName,
+	}, nil
+}
+
+// disruptiveChangesAllowed returns whether disruptive changes of the registry caches can be applied immediately. This
+// is the case during the maintenance time window of the given Shoot, when the Shoot is woken up from hibernation (the
+// registry cache Pods are started anyway) or when the disruptive changes are forced via annotation on the Shoot.
+func disruptiveChangesAllowed(shoot *gardencorev1beta1.Shoot, clock clock.Clock) bool {
+	return shoot.Status.IsHibernated ||
+		kubernetesutils.HasMetaDataAnnotation(shoot, constants.ForceDisruptiveChangesAnnotation, "true") ||
+		gardenerutils.IsNowInEffectiveShootMaintenanceTimeWindow(shoot, clock)
+}
+
+// externalEndpoint returns the endpoint of the registry cache outside of the Shoot cluster. It returns nil when the
+// registry cache is not exposed or the load balancer is not ready yet.
+func externalEndpoint(service corev1.Service) *string {
+	if hostname := service.Annotations[constants.ExternalHostnameAnnotation]; hostname != "" {
+		// The Ingress is always served via HTTPS on the default port.
+		return ptr.To("https://" + hostname)
+	}
+
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		if address := registryutils.LoadBalancerAddress(&service); address != "" {
+			return ptr.To(registryutils.Endpoint(service.Annotations[constants.SchemeAnnotation], address, servicePort(service)))
+		}
 	}
+
+	return nil
+}
+
+// servicePort returns the port of the given registry cache Service.
+func servicePort(service corev1.Service) int32 {
+	if len(service.Spec.Ports) == 0 {
+		return constants.RegistryCachePort
+	}
+
+	return service.Spec.Ports[0].Port
 }
 
 func (a *actuator) updateProviderStatus(ctx context.Context, ex *extensionsv1alpha1.Extension, registryStatus *v1alpha3.RegistryStatus) error {
@@ -0,0 +1,299 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extension_test
+
+import (
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"github.com/onsi/gomega/types"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha3"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/controller/cache"
+)
+
+var _ = Describe("Actuator", func() {
+	Describe("#DisruptiveChangesAllowed", func() {
+		var (
+			shoot     *gardencorev1beta1.Shoot
+			fakeClock *testclock.FakeClock
+		)
+
+		BeforeEach(func() {
+			shoot = &gardencorev1beta1.Shoot{
+				Spec: gardencorev1beta1.ShootSpec{
+					Maintenance: &gardencorev1beta1.Maintenance{
+						TimeWindow: &gardencorev1beta1.MaintenanceTimeWindow{
+							Begin: "220000+0000",
+							End:   "230000+0000",
+						},
+					},
+				},
+			}
+			fakeClock = testclock.NewFakeClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+		})
+
+		It("should not allow disruptive changes outside of the maintenance time window", func() {
+			Expect(DisruptiveChangesAllowed(shoot, fakeClock)).To(BeFalse())
+		})
+
+		It("should allow disruptive changes during the maintenance time window", func() {
+			fakeClock.SetTime(time.Date(2024, 6, 1, 22, 30, 0, 0, time.UTC))
+
+			Expect(DisruptiveChangesAllowed(shoot, fakeClock)).To(BeTrue())
+		})
+
+		It("should allow disruptive changes when the Shoot has no maintenance time window", func() {
+			shoot.Spec.Maintenance = nil
+
+			Expect(DisruptiveChangesAllowed(shoot, fakeClock)).To(BeTrue())
+		})
+
+		It("should allow disruptive changes when the Shoot is hibernated", func() {
+			shoot.Status.IsHibernated = true
+
+			Expect(DisruptiveChangesAllowed(shoot, fakeClock)).To(BeTrue())
+		})
+
+		It("should allow disruptive changes when they are forced via annotation", func() {
+			metav1.SetMetaDataAnnotation(&shoot.ObjectMeta, "registry-cache.extensions.gardener.cloud/force-disruptive-changes", "true")
+
+			Expect(DisruptiveChangesAllowed(shoot, fakeClock)).To(BeTrue())
+		})
+
+		It("should not allow disruptive changes when the annotation is not set to true", func() {
+			metav1.SetMetaDataAnnotation(&shoot.ObjectMeta, "registry-cache.extensions.gardener.cloud/force-disruptive-changes", "false")
+
+			Expect(DisruptiveChangesAllowed(shoot, fakeClock)).To(BeFalse())
+		})
+	})
+
+	Describe("#ComputeProviderStatus", func() {
+		var (
+			registryConfig *api.RegistryConfig
+			service        corev1.Service
+		)
+
+		BeforeEach(func() {
+			registryConfig = &api.RegistryConfig{
+				Caches: []api.RegistryCache{{Upstream: "docker.io"}},
+			}
+			service = corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "registry-docker-io",
+					Namespace: "kube-system",
+					Annotations: map[string]string{
+						constants.UpstreamAnnotation:  "docker.io",
+						constants.RemoteURLAnnotation: "https://registry-1.docker.io",
+						constants.SchemeAnnotation:    "https",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Port: 5000}},
+				},
+			}
+		})
+
+		DescribeTable("should compute the endpoints from the cluster IPs of the Service",
+			func(clusterIP string, clusterIPs []string, matcher types.GomegaMatcher) {
+				service.Spec.ClusterIP = clusterIP
+				service.Spec.ClusterIPs = clusterIPs
+
+				status, err := ComputeProviderStatus([]corev1.Service{service}, registryConfig, nil, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Caches).To(ConsistOf(matcher))
+			},
+			Entry("IPv4", "10.4.0.10", []string{"10.4.0.10"}, MatchFields(IgnoreExtras, Fields{
+				"Endpoint":  Equal("https://10.4.0.10:5000"),
+				"Endpoints": Equal([]string{"https://10.4.0.10:5000"}),
+			})),
+			Entry("IPv6-only", "fd00:10:4::10", []string{"fd00:10:4::10"}, MatchFields(IgnoreExtras, Fields{
+				"Endpoint":  Equal("https://[fd00:10:4::10]:5000"),
+				"Endpoints": Equal([]string{"https://[fd00:10:4::10]:5000"}),
+			})),
+			Entry("dual-stack with IPv4 as primary family", "10.4.0.10", []string{"10.4.0.10", "fd00:10:4::10"}, MatchFields(IgnoreExtras, Fields{
+				"Endpoint":  Equal("https://10.4.0.10:5000"),
+				"Endpoints": Equal([]string{"https://10.4.0.10:5000", "https://[fd00:10:4::10]:5000"}),
+			})),
+			Entry("dual-stack with IPv6 as primary family", "fd00:10:4::10", []string{"fd00:10:4::10", "10.4.0.10"}, MatchFields(IgnoreExtras, Fields{
+				"Endpoint":  Equal("https://[fd00:10:4::10]:5000"),
+				"Endpoints": Equal([]string{"https://[fd00:10:4::10]:5000", "https://10.4.0.10:5000"}),
+			})),
+			Entry("only the cluster IP", "10.4.0.10", nil, MatchFields(IgnoreExtras, Fields{
+				"Endpoint":  Equal("https://10.4.0.10:5000"),
+				"Endpoints": Equal([]string{"https://10.4.0.10:5000"}),
+			})),
+		)
+
+		It("should compute the endpoints with the custom port of the registry cache", func() {
+			service.Spec.ClusterIPs = []string{"10.4.0.10", "fd00:10:4::10"}
+			service.Spec.Ports[0].Port = 8443
+
+			status, err := ComputeProviderStatus([]corev1.Service{service}, registryConfig, nil, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Caches).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+				"Endpoint":  Equal("https://10.4.0.10:8443"),
+				"Endpoints": Equal([]string{"https://10.4.0.10:8443", "https://[fd00:10:4::10]:8443"}),
+			})))
+		})
+
+		DescribeTable("should compute the hostname of the registry cache",
+			func(endpointType *api.EndpointType, matcher types.GomegaMatcher) {
+				registryConfig.EndpointType = endpointType
+				service.Spec.ClusterIPs = []string{"10.4.0.10"}
+
+				status, err := ComputeProviderStatus([]corev1.Service{service}, registryConfig, nil, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Caches).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Endpoint": Equal("https://10.4.0.10:5000"),
+					"Hostname": matcher,
+				})))
+			},
+			Entry("default endpoint type", nil, BeNil()),
+			Entry("ClusterIP endpoint type", ptr.To(api.EndpointTypeClusterIP), BeNil()),
+			Entry("Hostname endpoint type", ptr.To(api.EndpointTypeHostname), PointTo(Equal("registry-docker-io.kube-system.svc"))),
+		)
+
+		It("should fail when the Service does not have cluster IPs", func() {
+			_, err := ComputeProviderStatus([]corev1.Service{service}, registryConfig, nil, nil, nil)
+			Expect(err).To(MatchError("service kube-system/registry-docker-io does not have a cluster IP"))
+		})
+
+		It("should compute the status of the registry cache", func() {
+			registryConfig.Caches[0].Strict = ptr.To(true)
+			registryConfig.Caches[0].Capabilities = []api.RegistryCacheCapability{api.RegistryCacheCapabilityPull}
+			service.Spec.ClusterIPs = []string{"10.4.0.10"}
+
+			status, err := ComputeProviderStatus([]corev1.Service{service}, registryConfig, ptr.To("ca-extension-registry-cache-bundle"), []string{"docker.io"}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(&v1alpha3.RegistryStatus{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: v1alpha3.SchemeGroupVersion.String(),
+					Kind:       "RegistryStatus",
+				},
+				Caches: []v1alpha3.RegistryCacheStatus{{
+					Upstream:                 "docker.io",
+					Endpoint:                 "https://10.4.0.10:5000",
+					Endpoints:                []string{"https://10.4.0.10:5000"},
+					RemoteURL:                "https://registry-1.docker.io",
+					Strict:                   true,
+					Capabilities:             []v1alpha3.RegistryCacheCapability{v1alpha3.RegistryCacheCapabilityPull},
+					PendingDisruptiveChanges: true,
+				}},
+				CASecretName: ptr.To("ca-extension-registry-cache-bundle"),
+			}))
+		})
+
+		Context("provided certificate", func() {
+			BeforeEach(func() {
+				service.Spec.ClusterIPs = []string{"10.4.0.10"}
+				registryConfig.Caches[0].HTTP = &api.HTTP{TLS: true, TLSSecretReferenceName: ptr.To("docker-tls")}
+			})
+
+			DescribeTable("should compute the CA bundle of the registry cache",
+				func(caBundleSecretReferenceName *string, resources []gardencorev1beta1.NamedResourceReference, matcher types.GomegaMatcher) {
+					registryConfig.Caches[0].HTTP.CABundleSecretReferenceName = caBundleSecretReferenceName
+
+					status, err := ComputeProviderStatus([]corev1.Service{service}, registryConfig, nil, nil, resources)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(status.Caches).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+						"ProvidedCertificate": BeTrue(),
+						"CABundleSecretName":  matcher,
+					})))
+				},
+				Entry("without CA bundle", nil, nil, BeNil()),
+				Entry("with CA bundle", ptr.To("docker-ca"), []gardencorev1beta1.NamedResourceReference{
+					{Name: "docker-tls", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "docker-tls-secret"}},
+					{Name: "docker-ca", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "docker-ca-secret"}},
+				}, PointTo(Equal("ref-docker-ca-secret"))),
+			)
+
+			DescribeTable("should fail when the CA bundle is not a referenced Secret",
+				func(resources []gardencorev1beta1.NamedResourceReference) {
+					registryConfig.Caches[0].HTTP.CABundleSecretReferenceName = ptr.To("docker-ca")
+
+					_, err := ComputeProviderStatus([]corev1.Service{service}, registryConfig, nil, nil, resources)
+					Expect(err).To(MatchError("failed to find referenced resource with name docker-ca and kind Secret"))
+				},
+				Entry("missing reference", nil),
+				Entry("reference to a ConfigMap", []gardencorev1beta1.NamedResourceReference{
+					{Name: "docker-ca", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "ConfigMap", Name: "docker-ca-configmap"}},
+				}),
+			)
+
+			It("should not compute a CA bundle when TLS is not provided", func() {
+				registryConfig.Caches[0].HTTP = &api.HTTP{TLS: true, CABundleSecretReferenceName: ptr.To("docker-ca")}
+
+				status, err := ComputeProviderStatus([]corev1.Service{service}, registryConfig, nil, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Caches).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"ProvidedCertificate": BeFalse(),
+					"CABundleSecretName":  BeNil(),
+				})))
+			})
+		})
+	})
+
+	Describe("#ExternalEndpoint", func() {
+		var service corev1.Service
+
+		BeforeEach(func() {
+			service = corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.SchemeAnnotation: "https"},
+				},
+				Spec: corev1.ServiceSpec{
+					Type:  corev1.ServiceTypeClusterIP,
+					Ports: []corev1.ServicePort{{Port: 5000}},
+				},
+			}
+		})
+
+		It("should return nil when the registry cache is not exposed", func() {
+			Expect(ExternalEndpoint(service)).To(BeNil())
+		})
+
+		It("should return the Ingress hostname via HTTPS on the default port", func() {
+			service.Annotations[constants.ExternalHostnameAnnotation] = "registry.example.com"
+
+			Expect(ExternalEndpoint(service)).To(PointTo(Equal("https://registry.example.com")))
+		})
+
+		DescribeTable("should return the load balancer address",
+			func(scheme string, ingress []corev1.LoadBalancerIngress, matcher types.GomegaMatcher) {
+				service.Annotations[constants.SchemeAnnotation] = scheme
+				service.Spec.Type = corev1.ServiceTypeLoadBalancer
+				service.Status.LoadBalancer.Ingress = ingress
+
+				Expect(ExternalEndpoint(service)).To(matcher)
+			},
+			Entry("load balancer not ready", "https", nil, BeNil()),
+			Entry("IPv4 address", "https", []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}}, PointTo(Equal("https://1.2.3.4:5000"))),
+			Entry("IPv6 address", "https", []corev1.LoadBalancerIngress{{IP: "2001:db8::1"}}, PointTo(Equal("https://[2001:db8::1]:5000"))),
+			Entry("hostname", "https", []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}, PointTo(Equal("https://lb.example.com:5000"))),
+			Entry("HTTP", "http", []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}}, PointTo(Equal("http://1.2.3.4:5000"))),
+		)
+	})
+
+	Describe("#ServicePort", func() {
+		It("should return the port of the Service", func() {
+			Expect(ServicePort(corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8443}}}})).To(Equal(int32(8443)))
+		})
+
+		It("should return the default port when the Service has no ports", func() {
+			Expect(ServicePort(corev1.Service{})).To(Equal(int32(constants.RegistryCachePort)))
+		})
+	})
+})
@@ -6,9 +6,12 @@ package extension
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/gardener/gardener/extensions/pkg/controller/extension"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -37,6 +40,9 @@ type AddOptions struct {
 	Config config.Configuration
 	// IgnoreOperationAnnotation specifies whether to ignore the operation annotation or not.
 	IgnoreOperationAnnotation bool
+	// CertificateRenewalCheckPeriod is the period with which the certificates of the registry caches are checked for
+	// renewal. The reconciliation of Extensions with certificates which must be renewed is triggered.
+	CertificateRenewalCheckPeriod time.Duration
 }
 
 // AddToManager adds a controller with the default Options to the given Controller Manager.
@@ -46,9 +52,24 @@ func AddToManager(ctx context.Context, mgr manager.Manager) error {
 
 // AddToManagerWithOptions adds a controller with the given Options to the given manager.
 // The opts.Reconciler is being set with a newly instantiated actuator.
+// Additionally, a runnable which triggers the reconciliation of Extensions with certificates which must be renewed is added.
 func AddToManagerWithOptions(ctx context.Context, mgr manager.Manager, opts AddOptions) error {
 	decoder := serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict).UniversalDecoder()
 
+	if opts.CertificateRenewalCheckPeriod == 0 {
+		opts.CertificateRenewalCheckPeriod = time.Hour
+	}
+
+	if err := mgr.Add(&certificateRenewal{
+		client: mgr.GetClient(),
+		reader: mgr.GetAPIReader(),
+		log:    mgr.GetLogger().WithName(ControllerName).WithName("certificate-renewal"),
+		clock:  clock.RealClock{},
+		period: opts.CertificateRenewalCheckPeriod,
+	}); err != nil {
+		return fmt.Errorf("failed to add certificate renewal runnable: %w", err)
+	}
+
 	return extension.Add(mgr, extension.AddArgs{
 		Actuator:          NewActuator(mgr.GetClient(), mgr.GetAPIReader(), decoder, opts.Config),
 		ControllerOptions: opts.ControllerOptions,
@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	secretsutils "github.com/gardener/gardener/pkg/utils/secrets"
+	secretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/secrets"
+)
+
+// certificateRenewal triggers the reconciliation of the registry-cache Extensions with certificates which must be renewed.
+// The secrets manager renews the certificates only during the reconciliation of an Extension. Without triggering it,
+// the certificates of a Shoot which is not reconciled for a long time would expire.
+type certificateRenewal struct {
+	client client.Client
+	reader client.Reader
+	log    logr.Logger
+	clock  clock.Clock
+	period time.Duration
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (c *certificateRenewal) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable.
+func (c *certificateRenewal) Start(ctx context.Context) error {
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		if err := c.check(ctx); err != nil {
+			c.log.Error(err, "Failed to trigger the reconciliation of Extensions with certificates which must be renewed")
+		}
+	}, c.period, 0.1, true)
+
+	return nil
+}
+
+func (c *certificateRenewal) check(ctx context.Context) error {
+	// Use the API reader to not start a cluster-wide informer for Secrets in the seed.
+	secretList := &corev1.SecretList{}
+	if err := c.reader.List(ctx, secretList, client.MatchingLabels{
+		secretsmanager.LabelKeyManagedBy:       secretsmanager.LabelValueSecretsManager,
+		secretsmanager.LabelKeyManagerIdentity: secrets.ManagerIdentity,
+	}); err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	// Only the newest secret per name is in use, older ones are kept until the next cleanup of the secrets manager.
+	nameToNewestSecret := make(map[client.ObjectKey]corev1.Secret, len(secretList.Items))
+	for _, secret := range secretList.Items {
+		key := client.ObjectKey{Namespace: secret.Namespace, Name: secret.Labels[secretsmanager.LabelKeyName]}
+		if oldSecret, ok := nameToNewestSecret[key]; !ok || oldSecret.CreationTimestamp.Before(&secret.CreationTimestamp) {
+			nameToNewestSecret[key] = secret
+		}
+	}
+
+	namespaces := sets.New[string]()
+	for _, secret := range nameToNewestSecret {
+		renew, err := mustRenew(secret, c.clock.Now())
+		if err != nil {
+			c.log.Error(err, "Failed to check whether secret must be renewed", "secret", client.ObjectKeyFromObject(&secret))
+			continue
+		}
+
+		if renew {
+			namespaces.Insert(secret.Namespace)
+		}
+	}
+
+	var errs []error
+	for _, namespace := range sets.List(namespaces) {
+		if err := c.triggerReconciliation(ctx, namespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *certificateRenewal) triggerReconciliation(ctx context.Context, namespace string) error {
+	extension := &extensionsv1alpha1.Extension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Type,
+			Namespace: namespace,
+		},
+	}
+	if err := c.client.Get(ctx, client.ObjectKeyFromObject(extension), extension); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get extension '%s': %w", client.ObjectKeyFromObject(extension), err)
+	}
+
+	if extension.DeletionTimestamp != nil || extension.Annotations[v1beta1constants.GardenerOperation] != "" {
+		return nil
+	}
+
+	cluster, err := extensionscontroller.GetCluster(ctx, c.client, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	// The registry caches of a hibernated Shoot are not reconciled. The certificates are renewed when the Shoot is woken up.
+	if v1beta1helper.HibernationIsEnabled(cluster.Shoot) {
+		return nil
+	}
+
+	c.log.Info("Triggering the reconciliation of the Extension to renew certificates", "extension", client.ObjectKeyFromObject(extension))
+
+	patch := client.MergeFrom(extension.DeepCopy())
+	metav1.SetMetaDataAnnotation(&extension.ObjectMeta, v1beta1constants.GardenerOperation, v1beta1constants.GardenerOperationReconcile)
+	if err := c.client.Patch(ctx, extension, patch); err != nil {
+		return fmt.Errorf("failed to annotate extension '%s' with the reconcile operation: %w", client.ObjectKeyFromObject(extension), err)
+	}
+
+	return nil
+}
+
+// mustRenew returns whether the given secrets manager certificate secret must be renewed. The server certificates are
+// generated with secrets.ServerCertificateRenewAfterValidityPercentage, hence the secrets manager renews them with a
+// reconciliation triggered once this percentage of their validity has passed. CA secrets are not renewed automatically,
+// they are rotated with the CA rotation of the Shoot.
+func mustRenew(secret corev1.Secret, now time.Time) (bool, error) {
+	if secret.Data[secretsutils.DataKeyCertificateCA] != nil && secret.Data[secretsutils.DataKeyPrivateKeyCA] != nil {
+		return false, nil
+	}
+
+	issuedAt, err := parseUnixTimeLabel(secret, secretsmanager.LabelKeyIssuedAtTime)
+	if err != nil || issuedAt == nil {
+		return false, err
+	}
+
+	validUntil, err := parseUnixTimeLabel(secret, secretsmanager.LabelKeyValidUntilTime)
+	if err != nil || validUntil == nil {
+		return false, err
+	}
+
+	renewAt := issuedAt.Add(validUntil.Sub(*issuedAt) * secrets.ServerCertificateRenewAfterValidityPercentage / 100)
+	return !now.Before(renewAt), nil
+}
+
+func parseUnixTimeLabel(secret corev1.Secret, key string) (*time.Time, error) {
+	value := secret.Labels[key]
+	if value == "" {
+		return nil, nil
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse label %q: %w", key, err)
+	}
+
+	return ptr.To(time.Unix(unix, 0)), nil
+}
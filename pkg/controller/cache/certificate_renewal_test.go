@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extension_test
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	secretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/controller/cache"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/secrets"
+)
+
+var _ = Describe("CertificateRenewal", func() {
+	var (
+		now = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		newSecret = func(namespace, name, configName string, data map[string][]byte, creationTimestamp, validUntil time.Time) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         namespace,
+					CreationTimestamp: metav1.NewTime(creationTimestamp),
+					Labels: map[string]string{
+						secretsmanager.LabelKeyManagedBy:       secretsmanager.LabelValueSecretsManager,
+						secretsmanager.LabelKeyManagerIdentity: secrets.ManagerIdentity,
+						secretsmanager.LabelKeyName:            configName,
+						secretsmanager.LabelKeyIssuedAtTime:    strconv.FormatInt(validUntil.Add(-90*24*time.Hour).Unix(), 10),
+						secretsmanager.LabelKeyValidUntilTime:  strconv.FormatInt(validUntil.Unix(), 10),
+					},
+				},
+				Data: data,
+			}
+		}
+		serverCertificateData = map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert"), "tls.key": []byte("key")}
+		caData                = map[string][]byte{"ca.crt": []byte("ca"), "ca.key": []byte("key")}
+	)
+
+	Describe("#MustRenew", func() {
+		It("should not renew a certificate before 80% of its validity has passed", func() {
+			Expect(MustRenew(*newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now.Add(19*24*time.Hour)), now)).To(BeFalse())
+		})
+
+		It("should renew a certificate when 80% of its validity has passed", func() {
+			Expect(MustRenew(*newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now.Add(18*24*time.Hour)), now)).To(BeTrue())
+		})
+
+		It("should compute the renewal time from the issue time of the certificate", func() {
+			secret := newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now.Add(19*24*time.Hour))
+			secret.Labels[secretsmanager.LabelKeyIssuedAtTime] = strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+
+			Expect(MustRenew(*secret, now)).To(BeFalse())
+		})
+
+		It("should renew an expired certificate", func() {
+			Expect(MustRenew(*newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now.Add(-time.Hour)), now)).To(BeTrue())
+		})
+
+		It("should never renew a CA", func() {
+			Expect(MustRenew(*newSecret("shoot--foo--bar", "ca-extension-registry-cache-abcd", "ca-extension-registry-cache", caData, now, now.Add(-time.Hour)), now)).To(BeFalse())
+		})
+
+		It("should not renew a secret without validity", func() {
+			secret := newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now)
+			delete(secret.Labels, secretsmanager.LabelKeyValidUntilTime)
+
+			Expect(MustRenew(*secret, now)).To(BeFalse())
+		})
+
+		It("should not renew a secret without issue time", func() {
+			secret := newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now)
+			delete(secret.Labels, secretsmanager.LabelKeyIssuedAtTime)
+
+			Expect(MustRenew(*secret, now)).To(BeFalse())
+		})
+
+		It("should fail for an invalid issue time", func() {
+			secret := newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now)
+			secret.Labels[secretsmanager.LabelKeyIssuedAtTime] = "foo"
+
+			_, err := MustRenew(*secret, now)
+			Expect(err).To(MatchError(ContainSubstring(`failed to parse label "issued-at-time"`)))
+		})
+
+		It("should fail for an invalid validity", func() {
+			secret := newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now)
+			secret.Labels[secretsmanager.LabelKeyValidUntilTime] = "foo"
+
+			_, err := MustRenew(*secret, now)
+			Expect(err).To(MatchError(ContainSubstring(`failed to parse label "valid-until-time"`)))
+		})
+	})
+
+	Describe("#Check", func() {
+		var (
+			ctx = context.Background()
+
+			fakeClient client.Client
+			fakeClock  *testclock.FakeClock
+		)
+
+		BeforeEach(func() {
+			fakeClient = fakeclient.NewClientBuilder().WithScheme(kubernetes.SeedScheme).Build()
+			fakeClock = testclock.NewFakeClock(now)
+		})
+
+		createExtension := func(namespace string, hibernated bool) {
+			shoot := &gardencorev1beta1.Shoot{
+				TypeMeta: metav1.TypeMeta{APIVersion: gardencorev1beta1.SchemeGroupVersion.String(), Kind: "Shoot"},
+				Spec: gardencorev1beta1.ShootSpec{
+					Hibernation: &gardencorev1beta1.Hibernation{Enabled: ptr.To(hibernated)},
+				},
+			}
+			Expect(fakeClient.Create(ctx, &extensionsv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: namespace},
+				Spec: extensionsv1alpha1.ClusterSpec{
+					CloudProfile: runtime.RawExtension{Raw: []byte("{}")},
+					Seed:         runtime.RawExtension{Raw: []byte("{}")},
+					Shoot:        runtime.RawExtension{Object: shoot},
+				},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &extensionsv1alpha1.Extension{ObjectMeta: metav1.ObjectMeta{Name: Type, Namespace: namespace}})).To(Succeed())
+		}
+
+		expectReconciliationTriggered := func(namespace string, triggered bool) {
+			extension := &extensionsv1alpha1.Extension{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: Type, Namespace: namespace}, extension)).To(Succeed())
+			if triggered {
+				ExpectWithOffset(1, extension.Annotations).To(HaveKeyWithValue(v1beta1constants.GardenerOperation, v1beta1constants.GardenerOperationReconcile))
+			} else {
+				ExpectWithOffset(1, extension.Annotations).NotTo(HaveKey(v1beta1constants.GardenerOperation))
+			}
+		}
+
+		It("should trigger the reconciliation of Extensions with server certificates which must be renewed", func() {
+			createExtension("shoot--foo--renew", false)
+			createExtension("shoot--foo--valid", false)
+			Expect(fakeClient.Create(ctx, newSecret("shoot--foo--renew", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now.Add(5*24*time.Hour)))).To(Succeed())
+			Expect(fakeClient.Create(ctx, newSecret("shoot--foo--valid", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now.Add(50*24*time.Hour)))).To(Succeed())
+
+			Expect(CheckCertificateRenewal(ctx, fakeClient, fakeClock)).To(Succeed())
+
+			expectReconciliationTriggered("shoot--foo--renew", true)
+			expectReconciliationTriggered("shoot--foo--valid", false)
+		})
+
+		It("should not trigger the reconciliation of Extensions with an expiring CA", func() {
+			createExtension("shoot--foo--bar", false)
+			Expect(fakeClient.Create(ctx, newSecret("shoot--foo--bar", "ca-extension-registry-cache-abcd", "ca-extension-registry-cache", caData, now, now.Add(5*24*time.Hour)))).To(Succeed())
+
+			Expect(CheckCertificateRenewal(ctx, fakeClient, fakeClock)).To(Succeed())
+
+			expectReconciliationTriggered("shoot--foo--bar", false)
+		})
+
+		It("should only consider the newest secret per name", func() {
+			createExtension("shoot--foo--bar", false)
+			Expect(fakeClient.Create(ctx, newSecret("shoot--foo--bar", "registry-docker-io-tls-old", "registry-docker-io-tls", serverCertificateData, now.Add(-80*24*time.Hour), now.Add(5*24*time.Hour)))).To(Succeed())
+			Expect(fakeClient.Create(ctx, newSecret("shoot--foo--bar", "registry-docker-io-tls-new", "registry-docker-io-tls", serverCertificateData, now.Add(-time.Hour), now.Add(90*24*time.Hour)))).To(Succeed())
+
+			Expect(CheckCertificateRenewal(ctx, fakeClient, fakeClock)).To(Succeed())
+
+			expectReconciliationTriggered("shoot--foo--bar", false)
+		})
+
+		It("should not trigger the reconciliation of Extensions of hibernated Shoots", func() {
+			createExtension("shoot--foo--bar", true)
+			Expect(fakeClient.Create(ctx, newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now.Add(5*24*time.Hour)))).To(Succeed())
+
+			Expect(CheckCertificateRenewal(ctx, fakeClient, fakeClock)).To(Succeed())
+
+			expectReconciliationTriggered("shoot--foo--bar", false)
+		})
+
+		It("should ignore secrets without Extension", func() {
+			Expect(fakeClient.Create(ctx, newSecret("shoot--foo--bar", "registry-docker-io-tls-abcd", "registry-docker-io-tls", serverCertificateData, now, now.Add(5*24*time.Hour)))).To(Succeed())
+
+			Expect(CheckCertificateRenewal(ctx, fakeClient, fakeClock)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extension
+
+import (
+	"context"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MustRenew exports mustRenew for testing.
+var MustRenew = mustRenew
+
+// CheckCertificateRenewal runs a single check of the certificate renewal runnable.
+func CheckCertificateRenewal(ctx context.Context, c client.Client, clock clock.Clock) error {
+	return (&certificateRenewal{client: c, reader: c, log: logr.Discard(), clock: clock}).check(ctx)
+}
+
+// DisruptiveChangesAllowed exports disruptiveChangesAllowed for testing.
+var DisruptiveChangesAllowed = disruptiveChangesAllowed
+
+// ComputeProviderStatus exports computeProviderStatus for testing.
+var ComputeProviderStatus = computeProviderStatus
+
+// ExternalEndpoint exports externalEndpoint for testing.
+var ExternalEndpoint = externalEndpoint
+
+// ServicePort exports servicePort for testing.
+var ServicePort = servicePort
+
+// WaitForNewRegistryCaches exports waitForNewRegistryCaches for testing.
+var WaitForNewRegistryCaches = waitForNewRegistryCaches
+
+// WaitUntilRegistryCachesReady exports waitUntilRegistryCachesReady for testing.
+var WaitUntilRegistryCachesReady = waitUntilRegistryCachesReady
+
+// NewRegistryCacheServices returns the given Services of the registry caches which are not advertised in the provider
+// status of the given Extension yet.
+func NewRegistryCacheServices(decoder runtime.Decoder, ex *extensionsv1alpha1.Extension, services []corev1.Service) ([]corev1.Service, error) {
+	return (&actuator{decoder: decoder}).newRegistryCacheServices(ex, services)
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/utils/kubernetes/health"
+	"github.com/gardener/gardener/pkg/utils/retry"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubernetesclientset "k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+)
+
+var (
+	// TimeoutWaitForRegistryCaches is the timeout used while waiting for new registry caches to become ready.
+	TimeoutWaitForRegistryCaches = 2 * time.Minute
+	// IntervalWaitForRegistryCaches is the interval used while waiting for new registry caches to become ready.
+	IntervalWaitForRegistryCaches = 5 * time.Second
+)
+
+// waitForNewRegistryCaches returns whether new registry caches are advertised to the Nodes only when they are ready.
+// When the given Shoot is created or woken up from hibernation, the registry cache Pods cannot become ready before the
+// Nodes are created. The Nodes are created only after the registry caches are advertised, and gardener-node-agent
+// probes the readiness of the registry caches before adding them to the containerd configuration.
+func waitForNewRegistryCaches(shoot *gardencorev1beta1.Shoot) bool {
+	if shoot.Status.IsHibernated {
+		return false
+	}
+
+	// The Shoot is created until its first reconciliation succeeds.
+	lastOperation := shoot.Status.LastOperation
+	return lastOperation != nil && (lastOperation.Type != gardencorev1beta1.LastOperationTypeCreate || lastOperation.State == gardencorev1beta1.LastOperationStateSucceeded)
+}
+
+// newRegistryCacheServices returns the given Services of the registry caches which are not advertised in the provider
+// status of the given Extension yet.
+func (a *actuator) newRegistryCacheServices(ex *extensionsv1alpha1.Extension, services []corev1.Service) ([]corev1.Service, error) {
+	advertisedUpstreams := sets.New[string]()
+	if ex.Status.ProviderStatus != nil {
+		registryStatus := &api.RegistryStatus{}
+		if err := runtime.DecodeInto(a.decoder, ex.Status.ProviderStatus.Raw, registryStatus); err != nil {
+			return nil, fmt.Errorf("failed to decode provider status: %w", err)
+		}
+
+		for _, cache := range registryStatus.Caches {
+			advertisedUpstreams.Insert(cache.Upstream)
+		}
+	}
+
+	var newServices []corev1.Service
+	for _, service := range services {
+		if !advertisedUpstreams.Has(service.Annotations[constants.UpstreamAnnotation]) {
+			newServices = append(newServices, service)
+		}
+	}
+
+	return newServices, nil
+}
+
+// waitUntilRegistryCachesReady waits until the registry caches of the given Services are ready. A registry cache is
+// ready when its StatefulSet is healthy and a health probe against its endpoint succeeds. It returns the upstreams of
+// the registry caches which are not ready within the timeout and an error describing why.
+func waitUntilRegistryCachesReady(ctx context.Context, shootClient client.Client, shootClientSet kubernetesclientset.Interface, services []corev1.Service) ([]string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, TimeoutWaitForRegistryCaches)
+	defer cancel()
+
+	notReady := make(map[string]error, len(services))
+	for _, service := range services {
+		notReady[service.Annotations[constants.UpstreamAnnotation]] = errors.New("readiness not checked yet")
+	}
+
+	// The error is not checked because the registry caches which are not ready are reported below.
+	_ = retry.Until(timeoutCtx, IntervalWaitForRegistryCaches, func(ctx context.Context) (bool, error) {
+		for _, service := range services {
+			upstream := service.Annotations[constants.UpstreamAnnotation]
+			if _, ok := notReady[upstream]; !ok {
+				continue
+			}
+
+			if err := checkRegistryCacheReady(ctx, shootClient, shootClientSet, service); err != nil {
+				notReady[upstream] = err
+				continue
+			}
+			delete(notReady, upstream)
+		}
+
+		if len(notReady) > 0 {
+			return retry.NotOk()
+		}
+		return retry.Ok()
+	})
+
+	var (
+		upstreams []string
+		errs      []error
+	)
+	for _, upstream := range sets.List(sets.KeySet(notReady)) {
+		upstreams = append(upstreams, upstream)
+		errs = append(errs, fmt.Errorf("registry cache for upstream %s is not ready after %s: %w", upstream, TimeoutWaitForRegistryCaches, notReady[upstream]))
+	}
+
+	return upstreams, errors.Join(errs...)
+}
+
+// checkRegistryCacheReady checks whether the StatefulSet of the registry cache of the given Service is healthy and
+// whether the registry cache serves requests via the Service. The request is proxied via the kube-apiserver of the
+// Shoot because the Service is not reachable from the Seed.
+func checkRegistryCacheReady(ctx context.Context, shootClient client.Client, shootClientSet kubernetesclientset.Interface, service corev1.Service) error {
+	// The StatefulSet has the same name as the Service of the registry cache.
+	statefulSet := &appsv1.StatefulSet{}
+	if err := shootClient.Get(ctx, client.ObjectKey{Namespace: service.Namespace, Name: service.Name}, statefulSet); err != nil {
+		return fmt.Errorf("failed to get StatefulSet: %w", err)
+	}
+	if err := health.CheckStatefulSet(statefulSet); err != nil {
+		return fmt.Errorf("StatefulSet is not healthy: %w", err)
+	}
+
+	scheme := service.Annotations[constants.SchemeAnnotation]
+	port := strconv.Itoa(int(servicePort(service)))
+	if _, err := shootClientSet.CoreV1().Services(service.Namespace).ProxyGet(scheme, service.Name, port, "/v2/", nil).DoRaw(ctx); err != nil {
+		return fmt.Errorf("health probe against the endpoint failed: %w", err)
+	}
+
+	return nil
+}
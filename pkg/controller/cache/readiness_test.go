@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package extension_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils/test"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	fakekubernetes "k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	registryinstall "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/install"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/v1alpha3"
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/controller/cache"
+)
+
+var _ = Describe("Readiness", func() {
+	newService := func(name, upstream string) corev1.Service {
+		return corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "kube-system",
+				Annotations: map[string]string{
+					"upstream": upstream,
+					"scheme":   "https",
+				},
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 5000}},
+			},
+		}
+	}
+
+	Describe("#WaitForNewRegistryCaches", func() {
+		var shoot *gardencorev1beta1.Shoot
+
+		BeforeEach(func() {
+			shoot = &gardencorev1beta1.Shoot{
+				Status: gardencorev1beta1.ShootStatus{
+					LastOperation: &gardencorev1beta1.LastOperation{
+						Type:  gardencorev1beta1.LastOperationTypeReconcile,
+						State: gardencorev1beta1.LastOperationStateProcessing,
+					},
+				},
+			}
+		})
+
+		It("should wait for new registry caches of a reconciled Shoot", func() {
+			Expect(WaitForNewRegistryCaches(shoot)).To(BeTrue())
+		})
+
+		It("should wait for new registry caches of a created Shoot", func() {
+			shoot.Status.LastOperation.Type = gardencorev1beta1.LastOperationTypeCreate
+			shoot.Status.LastOperation.State = gardencorev1beta1.LastOperationStateSucceeded
+
+			Expect(WaitForNewRegistryCaches(shoot)).To(BeTrue())
+		})
+
+		It("should not wait for new registry caches while the Shoot is created", func() {
+			shoot.Status.LastOperation.Type = gardencorev1beta1.LastOperationTypeCreate
+
+			Expect(WaitForNewRegistryCaches(shoot)).To(BeFalse())
+		})
+
+		It("should not wait for new registry caches before the first operation of the Shoot", func() {
+			shoot.Status.LastOperation = nil
+
+			Expect(WaitForNewRegistryCaches(shoot)).To(BeFalse())
+		})
+
+		It("should not wait for new registry caches while the Shoot is hibernated", func() {
+			shoot.Status.IsHibernated = true
+
+			Expect(WaitForNewRegistryCaches(shoot)).To(BeFalse())
+		})
+	})
+
+	Describe("#NewRegistryCacheServices", func() {
+		var (
+			decoder  runtime.Decoder
+			ex       *extensionsv1alpha1.Extension
+			services []corev1.Service
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			registryinstall.Install(scheme)
+			decoder = serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
+
+			ex = &extensionsv1alpha1.Extension{}
+			services = []corev1.Service{
+				newService("registry-docker-io", "docker.io"),
+				newService("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev"),
+			}
+		})
+
+		It("should return all Services when there is no provider status", func() {
+			Expect(NewRegistryCacheServices(decoder, ex, services)).To(Equal(services))
+		})
+
+		It("should return the Services of the registry caches which are not advertised yet", func() {
+			raw, err := json.Marshal(&v1alpha3.RegistryStatus{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: v1alpha3.SchemeGroupVersion.String(),
+					Kind:       "RegistryStatus",
+				},
+				Caches: []v1alpha3.RegistryCacheStatus{{Upstream: "docker.io", Endpoint: "https://10.4.0.10:5000", RemoteURL: "https://registry-1.docker.io"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			ex.Status.ProviderStatus = &runtime.RawExtension{Raw: raw}
+
+			Expect(NewRegistryCacheServices(decoder, ex, services)).To(ConsistOf(services[1]))
+		})
+
+		It("should fail when the provider status cannot be decoded", func() {
+			ex.Status.ProviderStatus = &runtime.RawExtension{Raw: []byte(`{"apiVersion":"registry.extensions.gardener.cloud/v1alpha3","kind":"Foo"}`)}
+
+			_, err := NewRegistryCacheServices(decoder, ex, services)
+			Expect(err).To(MatchError(ContainSubstring("failed to decode provider status")))
+		})
+	})
+
+	Describe("#WaitUntilRegistryCachesReady", func() {
+		var (
+			ctx = context.Background()
+
+			shootClient    client.Client
+			shootClientSet *fakekubernetes.Clientset
+			probeErr       error
+			services       []corev1.Service
+		)
+
+		BeforeEach(func() {
+			DeferCleanup(test.WithVars(
+				&TimeoutWaitForRegistryCaches, 50*time.Millisecond,
+				&IntervalWaitForRegistryCaches, 5*time.Millisecond,
+			))
+
+			shootClient = fakeclient.NewClientBuilder().WithScheme(kubernetes.ShootScheme).Build()
+			shootClientSet = fakekubernetes.NewClientset()
+			probeErr = nil
+			shootClientSet.PrependProxyReactor("services", func(k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+				return true, &fakeResponseWrapper{err: probeErr}, nil
+			})
+
+			services = []corev1.Service{
+				newService("registry-docker-io", "docker.io"),
+				newService("registry-europe-docker-pkg-dev", "europe-docker.pkg.dev"),
+			}
+		})
+
+		createStatefulSet := func(name string, ready bool) {
+			statefulSet := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](1)},
+			}
+			if ready {
+				statefulSet.Status.ReadyReplicas = 1
+			}
+			ExpectWithOffset(1, shootClient.Create(ctx, statefulSet)).To(Succeed())
+		}
+
+		It("should succeed when the registry caches are ready", func() {
+			createStatefulSet("registry-docker-io", true)
+			createStatefulSet("registry-europe-docker-pkg-dev", true)
+
+			notReadyUpstreams, err := WaitUntilRegistryCachesReady(ctx, shootClient, shootClientSet, services)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(notReadyUpstreams).To(BeEmpty())
+		})
+
+		It("should return the registry caches with StatefulSets which are not ready within the timeout", func() {
+			createStatefulSet("registry-docker-io", true)
+			createStatefulSet("registry-europe-docker-pkg-dev", false)
+
+			notReadyUpstreams, err := WaitUntilRegistryCachesReady(ctx, shootClient, shootClientSet, services)
+			Expect(err).To(MatchError(And(
+				ContainSubstring("registry cache for upstream europe-docker.pkg.dev is not ready"),
+				ContainSubstring("StatefulSet is not healthy"),
+			)))
+			Expect(err).NotTo(MatchError(ContainSubstring("docker.io is not ready")))
+			Expect(notReadyUpstreams).To(ConsistOf("europe-docker.pkg.dev"))
+		})
+
+		It("should return the registry caches without StatefulSet", func() {
+			createStatefulSet("registry-docker-io", true)
+
+			notReadyUpstreams, err := WaitUntilRegistryCachesReady(ctx, shootClient, shootClientSet, services)
+			Expect(err).To(MatchError(ContainSubstring("failed to get StatefulSet")))
+			Expect(notReadyUpstreams).To(ConsistOf("europe-docker.pkg.dev"))
+		})
+
+		It("should return the registry caches when the health probe fails", func() {
+			createStatefulSet("registry-docker-io", true)
+			createStatefulSet("registry-europe-docker-pkg-dev", true)
+			probeErr = errors.New("connection refused")
+
+			notReadyUpstreams, err := WaitUntilRegistryCachesReady(ctx, shootClient, shootClientSet, services)
+			Expect(err).To(MatchError(ContainSubstring("health probe against the endpoint failed: connection refused")))
+			Expect(notReadyUpstreams).To(ConsistOf("docker.io", "europe-docker.pkg.dev"))
+		})
+	})
+})
+
+type fakeResponseWrapper struct {
+	err error
+}
+
+func (f *fakeResponseWrapper) DoRaw(context.Context) ([]byte, error) {
+	return nil, f.err
+}
+
+func (f *fakeResponseWrapper) Stream(context.Context) (io.ReadCloser, error) {
+	return nil, f.err
+}
@@ -6,35 +6,127 @@ package mirror
 
 import (
 	"context"
+	"fmt"
 
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/extension"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/helper"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/v1alpha1"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/validation"
 )
 
 // NewActuator returns an actuator responsible for registry-mirror Extension resources.
-func NewActuator() extension.Actuator {
-	return &actuator{}
+func NewActuator(client client.Client, decoder runtime.Decoder) extension.Actuator {
+	return &actuator{
+		client:  client,
+		decoder: decoder,
+	}
 }
 
-type actuator struct{}
+type actuator struct {
+	client  client.Client
+	decoder runtime.Decoder
+}
 
-func (a *actuator) Reconcile(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.Extension) error {
-	return nil
+// Reconcile the Extension resource.
+func (a *actuator) Reconcile(ctx context.Context, _ logr.Logger, ex *extensionsv1alpha1.Extension) error {
+	namespace := ex.GetNamespace()
+	cluster, err := extensionscontroller.GetCluster(ctx, a.client, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	if ex.Spec.ProviderConfig == nil {
+		return fmt.Errorf("providerConfig is required for the registry-mirror extension")
+	}
+
+	mirrorConfig := &api.MirrorConfig{}
+	if err := runtime.DecodeInto(a.decoder, ex.Spec.ProviderConfig.Raw, mirrorConfig); err != nil {
+		return fmt.Errorf("failed to decode provider config: %w", err)
+	}
+
+	if err := validation.ValidateMirrorConfig(mirrorConfig, field.NewPath("spec", "providerConfig")).ToAggregate(); err != nil {
+		return fmt.Errorf("provider config is invalid: %w", err)
+	}
+
+	mirrorStatus, err := a.computeProviderStatus(ctx, namespace, mirrorConfig, cluster.Shoot.Spec.Resources)
+	if err != nil {
+		return err
+	}
+
+	return a.updateProviderStatus(ctx, ex, mirrorStatus)
 }
 
+// Delete the Extension resource.
 func (a *actuator) Delete(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.Extension) error {
 	return nil
 }
 
+// ForceDelete force deletes the Extension resource.
 func (a *actuator) ForceDelete(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.Extension) error {
 	return nil
 }
 
-func (a *actuator) Restore(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.Extension) error {
-	return nil
+// Restore the Extension resource.
+func (a *actuator) Restore(ctx context.Context, logger logr.Logger, ex *extensionsv1alpha1.Extension) error {
+	return a.Reconcile(ctx, logger, ex)
 }
 
+// Migrate the Extension resource.
 func (a *actuator) Migrate(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.Extension) error {
 	return nil
 }
+
+func (a *actuator) computeProviderStatus(ctx context.Context, namespace string, mirrorConfig *api.MirrorConfig, resources []gardencorev1beta1.NamedResourceReference) (*v1alpha1.MirrorStatus, error) {
+	mirrorStatus, err := helper.ComputeMirrorStatus(mirrorConfig, func(referenceName string) (string, error) {
+		return a.resolveReferencedSecret(ctx, namespace, referenceName, resources)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &v1alpha1.MirrorStatus{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "MirrorStatus",
+		},
+	}
+	if err := v1alpha1.Convert_mirror_MirrorStatus_To_v1alpha1_MirrorStatus(mirrorStatus, status, nil); err != nil {
+		return nil, fmt.Errorf("failed to convert provider status: %w", err)
+	}
+
+	return status, nil
+}
+
+// resolveReferencedSecret returns the name of the Secret in the Shoot namespace for the given resource reference name.
+func (a *actuator) resolveReferencedSecret(ctx context.Context, namespace, referenceName string, resources []gardencorev1beta1.NamedResourceReference) (string, error) {
+	ref := v1beta1helper.GetResourceByName(resources, referenceName)
+	if ref == nil || ref.ResourceRef.Kind != "Secret" {
+		return "", fmt.Errorf("failed to find referenced resource with name %s and kind Secret", referenceName)
+	}
+
+	secret := &corev1.Secret{}
+	if err := extensionscontroller.GetObjectByReference(ctx, a.client, &ref.ResourceRef, namespace, secret); err != nil {
+		return "", fmt.Errorf("failed to read referenced secret %s%s for reference %s: %w", v1beta1constants.ReferencedResourcesPrefix, ref.ResourceRef.Name, referenceName, err)
+	}
+
+	return secret.Name, nil
+}
+
+func (a *actuator) updateProviderStatus(ctx context.Context, ex *extensionsv1alpha1.Extension, mirrorStatus *v1alpha1.MirrorStatus) error {
+	patch := client.MergeFrom(ex.DeepCopy())
+	ex.Status.ProviderStatus = &runtime.RawExtension{Object: mirrorStatus}
+	return a.client.Status().Patch(ctx, ex, patch)
+}
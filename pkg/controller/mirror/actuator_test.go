@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mirror_test
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gardener/gardener/extensions/pkg/controller/extension"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mirrorinstall "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/install"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/v1alpha1"
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/controller/mirror"
+)
+
+var _ = Describe("Actuator", func() {
+	const namespace = "shoot--foo--bar"
+
+	var (
+		ctx    = context.Background()
+		logger = logr.Discard()
+
+		c         client.Client
+		decoder   runtime.Decoder
+		actuator  extension.Actuator
+		shoot     *gardencorev1beta1.Shoot
+		ex        *extensionsv1alpha1.Extension
+		mirrorCfg *v1alpha1.MirrorConfig
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(kubernetes.AddSeedSchemeToScheme(scheme)).To(Succeed())
+		mirrorinstall.Install(scheme)
+
+		c = fakeclient.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&extensionsv1alpha1.Extension{}).Build()
+		decoder = serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
+		actuator = NewActuator(c, decoder)
+
+		shoot = &gardencorev1beta1.Shoot{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: gardencorev1beta1.SchemeGroupVersion.String(),
+				Kind:       "Shoot",
+			},
+			Spec: gardencorev1beta1.ShootSpec{
+				Resources: []gardencorev1beta1.NamedResourceReference{
+					{
+						Name:        "mirror-auth",
+						ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "mirror-auth-secret", APIVersion: "v1"},
+					},
+				},
+			},
+		}
+
+		mirrorCfg = &v1alpha1.MirrorConfig{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "MirrorConfig",
+			},
+			Mirrors: []v1alpha1.MirrorConfiguration{
+				{
+					Upstream: "docker.io",
+					Hosts: []v1alpha1.MirrorHost{
+						{
+							Host:                    "https://mirror.example.com/v2/docker",
+							Capabilities:            []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull},
+							SkipVerify:              ptr.To(true),
+							AuthSecretReferenceName: ptr.To("mirror-auth"),
+						},
+					},
+				},
+				{
+					Upstream: "*",
+					Hosts: []v1alpha1.MirrorHost{
+						{
+							Host:         "https://mirror.example.com",
+							Capabilities: []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull, v1alpha1.MirrorHostCapabilityResolve},
+						},
+					},
+				},
+			},
+		}
+
+		ex = &extensionsv1alpha1.Extension{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "registry-mirror",
+				Namespace: namespace,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		shootRaw, err := json.Marshal(shoot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Create(ctx, &extensionsv1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+			Spec:       extensionsv1alpha1.ClusterSpec{Shoot: runtime.RawExtension{Raw: shootRaw}},
+		})).To(Succeed())
+
+		if mirrorCfg != nil {
+			mirrorCfgRaw, err := json.Marshal(mirrorCfg)
+			Expect(err).NotTo(HaveOccurred())
+			ex.Spec.ProviderConfig = &runtime.RawExtension{Raw: mirrorCfgRaw}
+		}
+		Expect(c.Create(ctx, ex)).To(Succeed())
+	})
+
+	Describe("#Reconcile", func() {
+		Context("when the provider config is missing", func() {
+			BeforeEach(func() {
+				mirrorCfg = nil
+			})
+
+			It("should return error", func() {
+				Expect(actuator.Reconcile(ctx, logger, ex)).To(MatchError("providerConfig is required for the registry-mirror extension"))
+			})
+		})
+
+		Context("when the provider config is invalid", func() {
+			BeforeEach(func() {
+				mirrorCfg.Mirrors[0].Hosts = nil
+			})
+
+			It("should return error", func() {
+				Expect(actuator.Reconcile(ctx, logger, ex)).To(MatchError(ContainSubstring("provider config is invalid")))
+			})
+		})
+
+		It("should return error when the referenced secret does not exist", func() {
+			Expect(actuator.Reconcile(ctx, logger, ex)).To(MatchError(ContainSubstring("failed to read referenced secret ref-mirror-auth-secret for reference mirror-auth")))
+		})
+
+		Context("when the referenced resource is not found", func() {
+			BeforeEach(func() {
+				shoot.Spec.Resources = nil
+			})
+
+			It("should return error", func() {
+				Expect(actuator.Reconcile(ctx, logger, ex)).To(MatchError("failed to find referenced resource with name mirror-auth and kind Secret"))
+			})
+		})
+
+		It("should publish the effective mirror configuration in the provider status", func() {
+			Expect(c.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ref-mirror-auth-secret", Namespace: namespace}})).To(Succeed())
+
+			Expect(actuator.Reconcile(ctx, logger, ex)).To(Succeed())
+
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(ex), ex)).To(Succeed())
+			Expect(ex.Status.ProviderStatus).NotTo(BeNil())
+
+			mirrorStatus := &v1alpha1.MirrorStatus{}
+			Expect(runtime.DecodeInto(decoder, ex.Status.ProviderStatus.Raw, mirrorStatus)).To(Succeed())
+			Expect(mirrorStatus.Mirrors).To(Equal([]v1alpha1.MirrorConfigurationStatus{
+				{
+					Upstream: "docker.io",
+					Server:   ptr.To("https://registry-1.docker.io"),
+					Hosts: []v1alpha1.MirrorHostStatus{
+						{
+							Host:           "https://mirror.example.com/v2/docker",
+							Capabilities:   []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull},
+							OverridePath:   true,
+							SkipVerify:     true,
+							AuthSecretName: ptr.To("ref-mirror-auth-secret"),
+						},
+					},
+				},
+				{
+					Upstream: "_default",
+					Hosts: []v1alpha1.MirrorHostStatus{
+						{
+							Host:         "https://mirror.example.com",
+							Capabilities: []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull, v1alpha1.MirrorHostCapabilityResolve},
+						},
+					},
+				},
+			}))
+		})
+	})
+
+	Describe("#Delete", func() {
+		It("should do nothing", func() {
+			Expect(actuator.Delete(ctx, logger, ex)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sharedcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/gardener/pkg/controllerutils"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+)
+
+const (
+	// ControllerName is the name of the shared registry cache controller.
+	ControllerName = "registry-cache-shared-controller"
+)
+
+var (
+	// DefaultAddOptions are the default AddOptions for AddToManager.
+	DefaultAddOptions = AddOptions{}
+)
+
+// AddOptions are options to apply when adding the shared registry cache controller to the manager.
+type AddOptions struct {
+	// Config contains configuration for the shared registry cache controller.
+	Config config.Configuration
+	// SyncPeriod is the period with which the shared registry caches are reconciled.
+	// Periodic reconciliation makes sure that the server certificates are renewed in time.
+	SyncPeriod time.Duration
+}
+
+// AddToManager adds a controller with the default Options to the given Controller Manager.
+func AddToManager(ctx context.Context, mgr manager.Manager) error {
+	return AddToManagerWithOptions(ctx, mgr, DefaultAddOptions)
+}
+
+// AddToManagerWithOptions adds a controller with the given Options to the given manager.
+// The controller is also added when the shared registry cache is not configured, so that the shared registry caches
+// of a previous configuration are deleted.
+func AddToManagerWithOptions(_ context.Context, mgr manager.Manager, opts AddOptions) error {
+	if opts.SyncPeriod == 0 {
+		opts.SyncPeriod = time.Hour
+	}
+
+	return builder.
+		ControllerManagedBy(mgr).
+		Named(ControllerName).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1,
+		}).
+		WatchesRawSource(controllerutils.EnqueueOnce).
+		Complete(&reconciler{
+			client:       mgr.GetClient(),
+			config:       opts.Config.SharedCache,
+			certificates: opts.Config.Certificates,
+			syncPeriod:   opts.SyncPeriod,
+		})
+}
@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sharedcache
+
+import (
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+)
+
+// NewReconciler returns a reconciler for the shared registry caches of the given configuration.
+func NewReconciler(c client.Client, sharedCache *config.SharedCache, syncPeriod time.Duration) reconcile.Reconciler {
+	return &reconciler{client: c, config: sharedCache, syncPeriod: syncPeriod}
+}
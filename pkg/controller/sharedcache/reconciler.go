@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sharedcache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gardener/gardener/pkg/component"
+	secretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/gardener/gardener-extension-registry-cache/imagevector"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/component/sharedregistrycaches"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+)
+
+// reconciler reconciles the shared registry caches in the seed. It is triggered once on startup and requeues itself
+// with the sync period, so that the server certificates are renewed in time.
+type reconciler struct {
+	client       client.Client
+	config       *config.SharedCache
+	certificates *config.Certificates
+	syncPeriod   time.Duration
+}
+
+// Reconcile reconciles the shared registry caches.
+func (r *reconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	log := logf.FromContext(ctx)
+
+	if err := r.deleteStaleNamespaces(ctx, log); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if r.config == nil {
+		return reconcile.Result{RequeueAfter: r.syncPeriod}, nil
+	}
+
+	log.Info("Reconciling shared registry caches", "namespace", r.config.Namespace)
+
+	image, err := imagevector.ImageVector().FindImage("registry")
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to find the registry image: %w", err)
+	}
+
+	secretsManager, err := secretsmanager.New(ctx, log.WithName("secretsmanager"), clock.RealClock{}, r.client, r.config.Namespace, sharedregistrycaches.ManagerIdentity, secretsmanager.Config{})
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to create secrets manager: %w", err)
+	}
+
+	sharedRegistryCaches := sharedregistrycaches.New(r.client, r.config.Namespace, secretsManager, sharedregistrycaches.Values{
+		Image:                    image.String(),
+		Caches:                   r.config.Caches,
+		LoadBalancerSourceRanges: r.config.LoadBalancerSourceRanges,
+		Certificates:             r.certificates,
+	})
+
+	if err := component.OpWait(sharedRegistryCaches).Deploy(ctx); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to deploy the shared registry caches component: %w", err)
+	}
+
+	if err := secretsManager.Cleanup(ctx); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to cleanup secrets: %w", err)
+	}
+
+	log.Info("Successfully reconciled shared registry caches")
+	return reconcile.Result{RequeueAfter: r.syncPeriod}, nil
+}
+
+// deleteStaleNamespaces deletes the shared registry caches in the namespaces which are no longer configured, i.e. all
+// namespaces of shared registry caches when the shared registry cache is not configured.
+func (r *reconciler) deleteStaleNamespaces(ctx context.Context, log logr.Logger) error {
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.client.List(ctx, namespaceList, client.MatchingLabels{sharedregistrycaches.ManagedByLabel: constants.Origin}); err != nil {
+		return fmt.Errorf("failed to list namespaces of shared registry caches: %w", err)
+	}
+
+	for _, namespace := range namespaceList.Items {
+		if r.config != nil && namespace.Name == r.config.Namespace {
+			continue
+		}
+
+		log.Info("Deleting shared registry caches", "namespace", namespace.Name)
+
+		// The secrets manager is not used for deletion.
+		sharedRegistryCaches := sharedregistrycaches.New(r.client, namespace.Name, nil, sharedregistrycaches.Values{})
+		if err := component.OpDestroyAndWait(sharedRegistryCaches).Destroy(ctx); err != nil {
+			return fmt.Errorf("failed to delete the shared registry caches in namespace %s: %w", namespace.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sharedcache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils/test"
+	. "github.com/gardener/gardener/pkg/utils/test/matchers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpaautoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/component/sharedregistrycaches"
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/controller/sharedcache"
+)
+
+var _ = Describe("Reconciler", func() {
+	const (
+		namespace      = "registry-cache-shared"
+		staleNamespace = "registry-cache-shared-old"
+		syncPeriod     = time.Hour
+	)
+
+	var (
+		ctx = context.Background()
+
+		c           client.Client
+		sharedCache *config.SharedCache
+		labels      map[string]string
+	)
+
+	BeforeEach(func() {
+		c = fakeclient.NewClientBuilder().WithScheme(kubernetes.SeedScheme).WithStatusSubresource(&corev1.Service{}, &appsv1.StatefulSet{}).Build()
+		sharedCache = &config.SharedCache{
+			Namespace:                namespace,
+			Caches:                   []config.SharedRegistryCache{{Upstream: "docker.io"}},
+			LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+		}
+		labels = map[string]string{"app": "registry-docker-io", "upstream-host": "docker.io"}
+
+		DeferCleanup(test.WithVar(&sharedregistrycaches.TimeoutWaitForStatefulSets, time.Millisecond))
+
+		Expect(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: staleNamespace, Labels: map[string]string{"app.kubernetes.io/managed-by": "registry-cache"}}})).To(Succeed())
+		Expect(c.Create(ctx, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io", Namespace: staleNamespace, Labels: labels}})).To(Succeed())
+		Expect(c.Create(ctx, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io", Namespace: staleNamespace, Labels: labels}})).To(Succeed())
+		Expect(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})).To(Succeed())
+		Expect(c.Create(ctx, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io", Namespace: "foo", Labels: labels}})).To(Succeed())
+	})
+
+	reconcileSharedCaches := func() (reconcile.Result, error) {
+		return NewReconciler(c, sharedCache, syncPeriod).Reconcile(ctx, reconcile.Request{})
+	}
+
+	expectStaleNamespaceCleanedUp := func() {
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: staleNamespace}, &appsv1.StatefulSet{})).To(BeNotFoundError())
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: staleNamespace}, &corev1.Service{})).To(BeNotFoundError())
+
+		ns := &corev1.Namespace{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: staleNamespace}, ns)).To(Succeed())
+		Expect(ns.Labels).NotTo(HaveKey("app.kubernetes.io/managed-by"))
+
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: "foo"}, &corev1.Service{})).To(Succeed())
+	}
+
+	It("should delete the shared registry caches when the shared registry cache is not configured", func() {
+		sharedCache = nil
+
+		Expect(reconcileSharedCaches()).To(Equal(reconcile.Result{RequeueAfter: syncPeriod}))
+
+		expectStaleNamespaceCleanedUp()
+	})
+
+	It("should delete the shared registry caches of a previous namespace and deploy the configured ones", func() {
+		_, err := reconcileSharedCaches()
+		Expect(err).To(MatchError(ContainSubstring("load balancer of Service registry-cache-shared/registry-docker-io is not ready yet")))
+
+		expectStaleNamespaceCleanedUp()
+
+		ns := &corev1.Namespace{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: namespace}, ns)).To(Succeed())
+		Expect(ns.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "registry-cache"))
+
+		service := &corev1.Service{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, service)).To(Succeed())
+		Expect(service.Spec.LoadBalancerSourceRanges).To(ConsistOf("10.0.0.0/8"))
+	})
+
+	It("should deploy and keep the shared registry caches of the configured namespace", func() {
+		_, err := reconcileSharedCaches()
+		Expect(err).To(HaveOccurred())
+
+		service := &corev1.Service{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, service)).To(Succeed())
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+		Expect(c.Status().Update(ctx, service)).To(Succeed())
+
+		_, err = reconcileSharedCaches()
+		Expect(err).To(MatchError(ContainSubstring("not enough ready replicas")))
+
+		statefulSet := &appsv1.StatefulSet{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, statefulSet)).To(Succeed())
+		statefulSet.Status.ReadyReplicas = 1
+		Expect(c.Status().Update(ctx, statefulSet)).To(Succeed())
+
+		Expect(reconcileSharedCaches()).To(Equal(reconcile.Result{RequeueAfter: syncPeriod}))
+		Expect(reconcileSharedCaches()).To(Equal(reconcile.Result{RequeueAfter: syncPeriod}))
+
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, statefulSet)).To(Succeed())
+		Expect(statefulSet.Spec.Template.Spec.PriorityClassName).To(Equal("gardener-system-600"))
+		Expect(statefulSet.Spec.Template.Spec.Containers).To(ConsistOf(HaveField("Name", "registry-cache")))
+		Expect(statefulSet.Spec.Template.Spec.Volumes).To(ContainElements(
+			HaveField("Secret.SecretName", "registry-docker-io-config"),
+			HaveField("Secret.SecretName", HavePrefix("registry-docker-io-tls")),
+		))
+
+		vpa := &vpaautoscalingv1.VerticalPodAutoscaler{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, vpa)).To(Succeed())
+		Expect(vpa.Spec.TargetRef.Name).To(Equal("registry-docker-io"))
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, pdb)).To(Succeed())
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(labels))
+
+		configSecret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io-config", Namespace: namespace}, configSecret)).To(Succeed())
+		Expect(string(configSecret.Data["config.yml"])).To(ContainSubstring("remoteurl: https://registry-1.docker.io"))
+
+		caBundleSecret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: sharedregistrycaches.CABundleSecretName, Namespace: namespace}, caBundleSecret)).To(Succeed())
+		Expect(caBundleSecret.Data).To(HaveKey("bundle.crt"))
+
+		Expect(c.Get(ctx, client.ObjectKey{Name: "registry-docker-io", Namespace: namespace}, service)).To(Succeed())
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+
+		ns := &corev1.Namespace{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: namespace}, ns)).To(Succeed())
+		Expect(ns.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "registry-cache"))
+	})
+})
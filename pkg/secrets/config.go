@@ -16,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
 	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
 )
@@ -25,17 +26,28 @@ const (
 	ManagerIdentity = "extension-registry-cache"
 	// CAName is the name of the CA secret.
 	CAName = "ca-extension-registry-cache"
+
+	// DefaultCAValidity is the default validity of the CA certificate.
+	DefaultCAValidity = 730 * 24 * time.Hour
+	// DefaultServerCertificateValidity is the default validity of the registry cache server certificates.
+	DefaultServerCertificateValidity = 90 * 24 * time.Hour
+	// ServerCertificateRenewAfterValidityPercentage is the percentage of the validity after which the registry cache
+	// server certificates are renewed.
+	ServerCertificateRenewAfterValidityPercentage = 80
 )
 
 // ConfigsFor returns configurations for the secrets manager for the given registry caches services.
-func ConfigsFor(services []corev1.Service) []extensionssecretsmanager.SecretConfigWithOptions {
+// The validities of the certificates are taken from the given certificates configuration, if configured.
+func ConfigsFor(services []corev1.Service, certificates *config.Certificates) []extensionssecretsmanager.SecretConfigWithOptions {
+	caValidity, serverCertificateValidity := Validities(certificates)
+
 	configs := []extensionssecretsmanager.SecretConfigWithOptions{
 		{
 			Config: &secretutils.CertificateSecretConfig{
 				Name:       CAName,
 				CommonName: CAName,
 				CertType:   secretutils.CACert,
-				Validity:   ptr.To(730 * 24 * time.Hour),
+				Validity:   ptr.To(caValidity),
 			},
 			Options: []secretsmanager.GenerateOption{secretsmanager.Persist()},
 		},
@@ -50,23 +62,61 @@ func ConfigsFor(services []corev1.Service) []extensionssecretsmanager.SecretConf
 		upstream := service.Annotations[constants.UpstreamAnnotation]
 		name := TLSSecretNameForUpstream(upstream)
 
+		dnsNames := kubernetesutils.DNSNamesForService(service.Name, metav1.NamespaceSystem)
+		var ipAddresses []net.IP
+		for _, clusterIP := range registryutils.ClusterIPs(&service) {
+			ipAddresses = append(ipAddresses, net.ParseIP(clusterIP))
+		}
+		// The registry cache is exposed outside of the Shoot cluster, hence the external address has to be covered as well.
+		if hostname := service.Annotations[constants.ExternalHostnameAnnotation]; hostname != "" {
+			dnsNames = append(dnsNames, hostname)
+		}
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			if address := registryutils.LoadBalancerAddress(&service); address != "" {
+				if ip := net.ParseIP(address); ip != nil {
+					ipAddresses = append(ipAddresses, ip)
+				} else {
+					dnsNames = append(dnsNames, address)
+				}
+			}
+		}
+
 		configs = append(configs, extensionssecretsmanager.SecretConfigWithOptions{
 			Config: &secretutils.CertificateSecretConfig{
 				Name:                        name,
 				CommonName:                  name,
 				CertType:                    secretutils.ServerCert,
-				DNSNames:                    kubernetesutils.DNSNamesForService(service.Name, metav1.NamespaceSystem),
-				IPAddresses:                 []net.IP{net.ParseIP(service.Spec.ClusterIP)},
-				Validity:                    ptr.To(90 * 24 * time.Hour),
+				DNSNames:                    dnsNames,
+				IPAddresses:                 ipAddresses,
+				Validity:                    ptr.To(serverCertificateValidity),
 				SkipPublishingCACertificate: true,
 			},
-			Options: []secretsmanager.GenerateOption{secretsmanager.SignedByCA(CAName, secretsmanager.UseOldCA)},
+			Options: []secretsmanager.GenerateOption{
+				secretsmanager.SignedByCA(CAName, secretsmanager.UseOldCA),
+				secretsmanager.RenewAfterValidityPercentage(ServerCertificateRenewAfterValidityPercentage),
+			},
 		})
 	}
 
 	return configs
 }
 
+// Validities returns the validities of the CA and of the server certificates from the given certificates
+// configuration. They default to DefaultCAValidity and DefaultServerCertificateValidity.
+func Validities(certificates *config.Certificates) (time.Duration, time.Duration) {
+	caValidity, serverCertificateValidity := DefaultCAValidity, DefaultServerCertificateValidity
+	if certificates != nil {
+		if certificates.CAValidity != nil {
+			caValidity = certificates.CAValidity.Duration
+		}
+		if certificates.ServerCertificateValidity != nil {
+			serverCertificateValidity = certificates.ServerCertificateValidity.Duration
+		}
+	}
+
+	return caValidity, serverCertificateValidity
+}
+
 // TLSSecretNameForUpstream returns a TLS Secret name for a given upstream.
 func TLSSecretNameForUpstream(upstream string) string {
 	name := registryutils.ComputeKubernetesResourceName(upstream)
@@ -16,6 +16,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/config"
 	"github.com/gardener/gardener-extension-registry-cache/pkg/secrets"
 )
 
@@ -30,7 +31,7 @@ var _ = Describe("Secrets", func() {
 		It("should return secret config for CA only when no services are passed", func() {
 			services := []corev1.Service{}
 
-			actual := secrets.ConfigsFor(services)
+			actual := secrets.ConfigsFor(services, nil)
 			Expect(actual).To(HaveLen(1))
 			Expect(actual).To(ConsistOf(
 				MatchFields(IgnoreExtras, Fields{
@@ -84,7 +85,7 @@ var _ = Describe("Secrets", func() {
 				},
 			}
 
-			actual := secrets.ConfigsFor(services)
+			actual := secrets.ConfigsFor(services, nil)
 			Expect(actual).To(HaveLen(3))
 			Expect(actual).To(ConsistOf(
 				MatchFields(IgnoreExtras, Fields{
@@ -124,5 +125,191 @@ var _ = Describe("Secrets", func() {
 				}),
 			))
 		})
+
+		It("should return secret configs with the configured validities", func() {
+			services := []corev1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "registry-docker-io",
+						Annotations: map[string]string{
+							"upstream": "docker.io",
+							"scheme":   "https",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: "10.4.0.10",
+					},
+				},
+			}
+			certificates := &config.Certificates{
+				CAValidity:                &metav1.Duration{Duration: 365 * 24 * time.Hour},
+				ServerCertificateValidity: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+			}
+
+			actual := secrets.ConfigsFor(services, certificates)
+			Expect(actual).To(ConsistOf(
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name":     Equal("ca-extension-registry-cache"),
+						"Validity": PointTo(Equal(365 * 24 * time.Hour)),
+					})),
+				}),
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name":     Equal("registry-docker-io-tls"),
+						"Validity": PointTo(Equal(30 * 24 * time.Hour)),
+					})),
+				}),
+			))
+		})
+
+		It("should include all cluster IPs of IPv6-only and dual-stack Services", func() {
+			services := []corev1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "registry-docker-io",
+						Annotations: map[string]string{
+							"upstream": "docker.io",
+							"scheme":   "https",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP:  "fd00:10:4::a",
+						ClusterIPs: []string{"fd00:10:4::a"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "registry-ghcr-io",
+						Annotations: map[string]string{
+							"upstream": "ghcr.io",
+							"scheme":   "https",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP:  "10.4.0.11",
+						ClusterIPs: []string{"10.4.0.11", "fd00:10:4::b"},
+					},
+				},
+			}
+
+			actual := secrets.ConfigsFor(services, nil)
+			Expect(actual).To(ConsistOf(
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name": Equal("ca-extension-registry-cache"),
+					})),
+				}),
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name":        Equal("registry-docker-io-tls"),
+						"IPAddresses": ConsistOf([]net.IP{net.ParseIP("fd00:10:4::a")}),
+					})),
+				}),
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name":        Equal("registry-ghcr-io-tls"),
+						"IPAddresses": ConsistOf([]net.IP{net.IPv4(10, 4, 0, 11), net.ParseIP("fd00:10:4::b")}),
+					})),
+				}),
+			))
+		})
+
+		It("should include the external addresses of exposed registry caches", func() {
+			services := []corev1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "registry-docker-io",
+						Annotations: map[string]string{
+							"upstream": "docker.io",
+							"scheme":   "https",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: "10.4.0.10",
+						Type:      corev1.ServiceTypeLoadBalancer,
+					},
+					Status: corev1.ServiceStatus{
+						LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "registry-ghcr-io",
+						Annotations: map[string]string{
+							"upstream": "ghcr.io",
+							"scheme":   "https",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: "10.4.0.11",
+						Type:      corev1.ServiceTypeLoadBalancer,
+					},
+					Status: corev1.ServiceStatus{
+						LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "registry-quay-io",
+						Annotations: map[string]string{
+							"upstream":          "quay.io",
+							"scheme":            "https",
+							"external-hostname": "quay-cache.example.com",
+						},
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: "10.4.0.12",
+					},
+				},
+			}
+
+			actual := secrets.ConfigsFor(services, nil)
+			Expect(actual).To(ConsistOf(
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name": Equal("ca-extension-registry-cache"),
+					})),
+				}),
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name":        Equal("registry-docker-io-tls"),
+						"DNSNames":    ConsistOf("registry-docker-io", "registry-docker-io.kube-system", "registry-docker-io.kube-system.svc", "registry-docker-io.kube-system.svc.cluster.local"),
+						"IPAddresses": ConsistOf([]net.IP{net.IPv4(10, 4, 0, 10), net.IPv4(203, 0, 113, 10)}),
+					})),
+				}),
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name":        Equal("registry-ghcr-io-tls"),
+						"DNSNames":    ConsistOf("registry-ghcr-io", "registry-ghcr-io.kube-system", "registry-ghcr-io.kube-system.svc", "registry-ghcr-io.kube-system.svc.cluster.local", "lb.example.com"),
+						"IPAddresses": ConsistOf([]net.IP{net.IPv4(10, 4, 0, 11)}),
+					})),
+				}),
+				MatchFields(IgnoreExtras, Fields{
+					"Config": PointTo(MatchFields(IgnoreExtras, Fields{
+						"Name":        Equal("registry-quay-io-tls"),
+						"DNSNames":    ConsistOf("registry-quay-io", "registry-quay-io.kube-system", "registry-quay-io.kube-system.svc", "registry-quay-io.kube-system.svc.cluster.local", "quay-cache.example.com"),
+						"IPAddresses": ConsistOf([]net.IP{net.IPv4(10, 4, 0, 12)}),
+					})),
+				}),
+			))
+		})
+	})
+
+	Describe("#Validities", func() {
+		It("should return the default validities", func() {
+			caValidity, serverCertificateValidity := secrets.Validities(nil)
+			Expect(caValidity).To(Equal(730 * 24 * time.Hour))
+			Expect(serverCertificateValidity).To(Equal(90 * 24 * time.Hour))
+		})
+
+		It("should return the configured validities", func() {
+			caValidity, serverCertificateValidity := secrets.Validities(&config.Certificates{
+				CAValidity:                &metav1.Duration{Duration: 365 * 24 * time.Hour},
+				ServerCertificateValidity: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+			})
+			Expect(caValidity).To(Equal(365 * 24 * time.Hour))
+			Expect(serverCertificateValidity).To(Equal(30 * 24 * time.Hour))
+		})
 	})
 })
@@ -0,0 +1,266 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamlimiter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// LimitRequestsPerSecond is the name of the limit of the requests per second.
+	LimitRequestsPerSecond = "requests-per-second"
+	// LimitConcurrentRequests is the name of the limit of the concurrent requests.
+	LimitConcurrentRequests = "concurrent-requests"
+	// LimitBandwidth is the name of the limit of the bandwidth.
+	LimitBandwidth = "bandwidth"
+
+	// maxChunkSize is the maximum number of bytes which are copied from the upstream response at once.
+	maxChunkSize = 32 * 1024
+)
+
+// hopByHopHeaders are the headers which are meaningful only for a single connection and hence not forwarded.
+// See https://datatracker.ietf.org/doc/html/rfc9110#section-7.6.1.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Config contains the limits of the requests to the upstream. A zero value disables the respective limit.
+type Config struct {
+	// RequestsPerSecond is the maximum number of requests per second to the upstream.
+	RequestsPerSecond int32
+	// MaxConcurrentRequests is the maximum number of concurrent requests to the upstream.
+	MaxConcurrentRequests int32
+	// MaxBandwidth is the maximum bandwidth in bytes per second of the responses from the upstream.
+	MaxBandwidth int64
+}
+
+// Limiter is an HTTP handler which proxies the requests to the upstream, enforces the configured limits and records the
+// status codes of the upstream responses.
+// Requests which exceed a limit are delayed instead of rejected. Redirects of the upstream, e.g. to the storage
+// backend of the upstream registry, are followed by the Limiter, so that the limits also apply to the redirected requests.
+type Limiter struct {
+	log         logr.Logger
+	upstreamURL *url.URL
+	client      *http.Client
+	metrics     *Metrics
+
+	requests  *rate.Limiter
+	inFlight  chan struct{}
+	bandwidth *rate.Limiter
+}
+
+// New returns a new Limiter which proxies the requests to the given upstream URL with the given client.
+func New(log logr.Logger, upstreamURL *url.URL, client *http.Client, config Config, metrics *Metrics) *Limiter {
+	l := &Limiter{
+		log:         log,
+		upstreamURL: upstreamURL,
+		client:      client,
+		metrics:     metrics,
+	}
+
+	if config.RequestsPerSecond > 0 {
+		l.requests = rate.NewLimiter(rate.Limit(config.RequestsPerSecond), int(config.RequestsPerSecond))
+	}
+	if config.MaxConcurrentRequests > 0 {
+		l.inFlight = make(chan struct{}, config.MaxConcurrentRequests)
+	}
+	if config.MaxBandwidth > 0 {
+		// The burst allows to transfer the bandwidth of one second at once.
+		l.bandwidth = rate.NewLimiter(rate.Limit(config.MaxBandwidth), int(min(config.MaxBandwidth, math.MaxInt32)))
+	}
+
+	return l
+}
+
+// ServeHTTP proxies the given request to the upstream.
+func (l *Limiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	release, err := l.acquire(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+
+	l.metrics.requestsInFlight.Inc()
+	defer l.metrics.requestsInFlight.Dec()
+
+	upstreamRequest, err := http.NewRequestWithContext(ctx, r.Method, l.upstreamRequestURL(r.URL), r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	upstreamRequest.Header = r.Header.Clone()
+	removeHopByHopHeaders(upstreamRequest.Header)
+	upstreamRequest.ContentLength = r.ContentLength
+
+	response, err := l.client.Do(upstreamRequest)
+	if err != nil {
+		l.log.Error(err, "Failed to send request to upstream", "method", r.Method, "path", r.URL.Path)
+		http.Error(w, "failed to send request to upstream", http.StatusBadGateway)
+		return
+	}
+	defer response.Body.Close()
+
+	// The API version check endpoint responds with 401 (Unauthorized) to request the authentication of the client, hence
+	// its responses do not indicate errors of the upstream.
+	if r.URL.Path != "/v2/" {
+		l.metrics.upstreamResponses.WithLabelValues(l.upstreamURL.Host, strconv.Itoa(response.StatusCode)).Inc()
+	}
+
+	header := w.Header()
+	for name, values := range response.Header {
+		header[name] = values
+	}
+	removeHopByHopHeaders(header)
+	w.WriteHeader(response.StatusCode)
+
+	if err := l.copyResponseBody(ctx, w, response.Body); err != nil && !errors.Is(err, context.Canceled) {
+		l.log.Error(err, "Failed to copy response from upstream", "method", r.Method, "path", r.URL.Path)
+	}
+}
+
+// acquire waits until the request is allowed by the limits of the requests per second and the concurrent requests.
+// The returned func releases the acquired slot of the concurrent requests.
+func (l *Limiter) acquire(ctx context.Context) (func(), error) {
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		default:
+			l.metrics.throttledRequests.WithLabelValues(LimitConcurrentRequests).Inc()
+			start := time.Now()
+			select {
+			case l.inFlight <- struct{}{}:
+				l.metrics.throttledSeconds.WithLabelValues(LimitConcurrentRequests).Add(time.Since(start).Seconds())
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	release := func() {
+		if l.inFlight != nil {
+			<-l.inFlight
+		}
+	}
+
+	if l.requests != nil {
+		throttled, err := l.wait(ctx, l.requests.Reserve(), LimitRequestsPerSecond)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if throttled {
+			l.metrics.throttledRequests.WithLabelValues(LimitRequestsPerSecond).Inc()
+		}
+	}
+
+	return release, nil
+}
+
+// copyResponseBody copies the given body of an upstream response to the given writer and enforces the bandwidth limit.
+func (l *Limiter) copyResponseBody(ctx context.Context, w http.ResponseWriter, body io.Reader) error {
+	var (
+		flusher, canFlush = w.(http.Flusher)
+		chunkSize         = maxChunkSize
+		throttled         bool
+	)
+	if l.bandwidth != nil {
+		chunkSize = min(chunkSize, l.bandwidth.Burst())
+	}
+	buf := make([]byte, chunkSize)
+
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			if l.bandwidth != nil {
+				waited, err := l.wait(ctx, l.bandwidth.ReserveN(time.Now(), n), LimitBandwidth)
+				if err != nil {
+					return err
+				}
+				if waited && !throttled {
+					throttled = true
+					l.metrics.throttledRequests.WithLabelValues(LimitBandwidth).Inc()
+				}
+			}
+
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			if canFlush && l.bandwidth != nil {
+				flusher.Flush()
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// wait waits for the given reservation of the given limit and records the waiting time. It returns whether the
+// reservation had to wait.
+func (l *Limiter) wait(ctx context.Context, reservation *rate.Reservation, limit string) (bool, error) {
+	delay := reservation.Delay()
+	if delay == 0 {
+		return false, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		l.metrics.throttledSeconds.WithLabelValues(limit).Add(delay.Seconds())
+		return true, nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return true, ctx.Err()
+	}
+}
+
+// upstreamRequestURL returns the URL of the upstream for the given request URL.
+func (l *Limiter) upstreamRequestURL(requestURL *url.URL) string {
+	u := *l.upstreamURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + requestURL.Path
+	u.RawPath = ""
+	u.RawQuery = requestURL.RawQuery
+
+	return u.String()
+}
+
+func removeHopByHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			header.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
@@ -0,0 +1,252 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamlimiter_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	. "github.com/gardener/gardener-extension-registry-cache/pkg/upstreamlimiter"
+)
+
+var _ = Describe("Limiter", func() {
+	var (
+		upstreamHandler http.HandlerFunc
+		upstream        *httptest.Server
+		registry        *prometheus.Registry
+		config          Config
+		limiter         *httptest.Server
+	)
+
+	BeforeEach(func() {
+		upstreamHandler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Docker-Distribution-Api-Version", "registry/2.0")
+			_, _ = w.Write([]byte(r.Method + " " + r.URL.RequestURI()))
+		}
+		upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upstreamHandler(w, r)
+		}))
+		DeferCleanup(upstream.Close)
+
+		registry = prometheus.NewRegistry()
+		config = Config{}
+	})
+
+	JustBeforeEach(func() {
+		upstreamURL, err := url.Parse(upstream.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		limiter = httptest.NewServer(New(logr.Discard(), upstreamURL, upstream.Client(), config, NewMetrics(registry)))
+		DeferCleanup(limiter.Close)
+	})
+
+	get := func(path string, header http.Header) *http.Response {
+		request, err := http.NewRequest(http.MethodGet, limiter.URL+path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		for name, values := range header {
+			request.Header[name] = values
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(response.Body.Close)
+
+		return response
+	}
+
+	It("should proxy the request to the upstream", func() {
+		upstreamHandler = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer token"))
+			Expect(r.Header.Get("Connection")).To(BeEmpty())
+			Expect(r.Host).To(Equal(strings.TrimPrefix(upstream.URL, "http://")))
+
+			w.Header().Set("Docker-Content-Digest", "sha256:foo")
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(r.URL.RequestURI()))
+		}
+
+		response := get("/v2/library/alpine/manifests/latest?ns=docker.io", http.Header{"Authorization": {"Bearer token"}})
+		Expect(response.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(response.Header.Get("Docker-Content-Digest")).To(Equal("sha256:foo"))
+		Expect(io.ReadAll(response.Body)).To(BeEquivalentTo("/v2/library/alpine/manifests/latest?ns=docker.io"))
+	})
+
+	It("should follow the redirects of the upstream", func() {
+		storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(BeEmpty())
+			_, _ = w.Write([]byte("blob"))
+		}))
+		DeferCleanup(storage.Close)
+
+		upstreamHandler = func(w http.ResponseWriter, r *http.Request) {
+			// The storage is redirected to with a different hostname, so that the Authorization header is not forwarded.
+			http.Redirect(w, r, strings.Replace(storage.URL, "127.0.0.1", "localhost", 1)+"/blobs/foo", http.StatusTemporaryRedirect)
+		}
+
+		response := get("/v2/library/alpine/blobs/sha256:foo", http.Header{"Authorization": {"Bearer token"}})
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(io.ReadAll(response.Body)).To(BeEquivalentTo("blob"))
+	})
+
+	It("should record the status codes of the upstream responses", func() {
+		upstreamHandler = func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/":
+				w.WriteHeader(http.StatusUnauthorized)
+			case "/v2/library/alpine/manifests/latest":
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}
+
+		Expect(get("/v2/", nil).StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(get("/v2/library/alpine/manifests/latest", nil).StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(get("/v2/library/alpine/manifests/latest", nil).StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(get("/v2/library/alpine/blobs/sha256:foo", nil).StatusCode).To(Equal(http.StatusOK))
+
+		upstreamHost := strings.TrimPrefix(upstream.URL, "http://")
+		Expect(metricValue(registry, "registry_cache_upstream_limiter_upstream_responses_total", map[string]string{"upstream": upstreamHost, "code": "429"})).To(Equal(2.0))
+		Expect(metricValue(registry, "registry_cache_upstream_limiter_upstream_responses_total", map[string]string{"upstream": upstreamHost, "code": "200"})).To(Equal(1.0))
+		Expect(metricValue(registry, "registry_cache_upstream_limiter_upstream_responses_total", map[string]string{"upstream": upstreamHost, "code": "401"})).To(BeZero())
+	})
+
+	It("should return bad gateway when the upstream is not reachable", func() {
+		upstream.Close()
+
+		response := get("/v2/", nil)
+		Expect(response.StatusCode).To(Equal(http.StatusBadGateway))
+	})
+
+	Context("with a limit of the concurrent requests", func() {
+		var unblock chan struct{}
+
+		BeforeEach(func() {
+			config.MaxConcurrentRequests = 1
+
+			unblock = make(chan struct{})
+			upstreamHandler = func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/blocked" {
+					<-unblock
+				}
+				_, _ = w.Write([]byte(r.URL.Path))
+			}
+		})
+
+		It("should delay the requests exceeding the limit", func() {
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				Expect(get("/blocked", nil).StatusCode).To(Equal(http.StatusOK))
+			}()
+			Eventually(func() float64 {
+				return metricValue(registry, "registry_cache_upstream_limiter_requests_in_flight", nil)
+			}).Should(Equal(1.0))
+
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+
+				Expect(get("/delayed", nil).StatusCode).To(Equal(http.StatusOK))
+			}()
+			Consistently(done, 200*time.Millisecond).ShouldNot(BeClosed())
+
+			close(unblock)
+			Eventually(done).Should(BeClosed())
+			wg.Wait()
+
+			Expect(metricValue(registry, "registry_cache_upstream_limiter_throttled_requests_total", map[string]string{"limit": LimitConcurrentRequests})).To(Equal(1.0))
+		})
+	})
+
+	Context("with a limit of the requests per second", func() {
+		BeforeEach(func() {
+			config.RequestsPerSecond = 5
+		})
+
+		It("should delay the requests exceeding the limit", func() {
+			start := time.Now()
+			for range 7 {
+				Expect(get("/v2/", nil).StatusCode).To(Equal(http.StatusOK))
+			}
+			Expect(time.Since(start)).To(BeNumerically(">=", 300*time.Millisecond))
+
+			Expect(metricValue(registry, "registry_cache_upstream_limiter_throttled_requests_total", map[string]string{"limit": LimitRequestsPerSecond})).To(Equal(2.0))
+		})
+	})
+
+	Context("with a limit of the bandwidth", func() {
+		BeforeEach(func() {
+			config.MaxBandwidth = 1024
+
+			upstreamHandler = func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(strings.Repeat("a", 1536)))
+			}
+		})
+
+		It("should delay the responses exceeding the limit", func() {
+			start := time.Now()
+			response := get("/v2/library/alpine/blobs/sha256:foo", nil)
+			body, err := io.ReadAll(response.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(HaveLen(1536))
+			Expect(time.Since(start)).To(BeNumerically(">=", 400*time.Millisecond))
+
+			Expect(metricValue(registry, "registry_cache_upstream_limiter_throttled_requests_total", map[string]string{"limit": LimitBandwidth})).To(Equal(1.0))
+			Expect(metricValue(registry, "registry_cache_upstream_limiter_throttled_seconds_total", map[string]string{"limit": LimitBandwidth})).To(BeNumerically(">", 0))
+		})
+	})
+})
+
+// metricValue returns the value of the gauge or counter with the given name and the given labels.
+func metricValue(registry *prometheus.Registry, name string, labels map[string]string) float64 {
+	families, err := registry.Gather()
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if !hasLabels(metric, labels) {
+				continue
+			}
+
+			if metric.GetGauge() != nil {
+				return metric.GetGauge().GetValue()
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	for name, value := range labels {
+		if !slices.ContainsFunc(metric.GetLabel(), func(label *dto.LabelPair) bool {
+			return label.GetName() == name && label.GetValue() == value
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamlimiter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "registry_cache_upstream_limiter"
+
+// Metrics contains the metrics of the Limiter.
+type Metrics struct {
+	requestsInFlight  prometheus.Gauge
+	throttledRequests *prometheus.CounterVec
+	throttledSeconds  *prometheus.CounterVec
+	upstreamResponses *prometheus.CounterVec
+}
+
+// NewMetrics returns new metrics of the Limiter which are registered with the given registerer.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "requests_in_flight",
+			Help:      "Number of requests to the upstream which are currently processed.",
+		}),
+		throttledRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "throttled_requests_total",
+			Help:      "Total number of requests to the upstream which were delayed by a limit.",
+		}, []string{"limit"}),
+		throttledSeconds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "throttled_seconds_total",
+			Help:      "Total time in seconds the requests to the upstream were delayed by a limit.",
+		}, []string{"limit"}),
+		upstreamResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "upstream_responses_total",
+			Help:      "Total number of responses of the upstream per status code.",
+		}, []string{"upstream", "code"}),
+	}
+
+	registerer.MustRegister(m.requestsInFlight, m.throttledRequests, m.throttledSeconds, m.upstreamResponses)
+
+	return m
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package containerd
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"path"
+	"strings"
+	"text/template"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+)
+
+const (
+	// CertsDirectory is the directory containing the containerd registry host configurations.
+	CertsDirectory = "/etc/containerd/certs.d"
+	// DefaultUpstream is the upstream of the containerd host configuration which is used for all upstreams without an
+	// explicit host configuration.
+	DefaultUpstream = "_default"
+)
+
+var (
+	//go:embed templates/hosts.toml.tpl
+	hostsTpl      string
+	hostsTemplate *template.Template
+)
+
+func init() {
+	hostsTemplate = template.Must(template.New("hosts.toml").Funcs(template.FuncMap{
+		"toJson": func(v any) (string, error) {
+			// JSON strings and arrays of strings are valid TOML values.
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(hostsTpl))
+}
+
+// Host is a registry host in the containerd hosts.toml file.
+// Contrary to extensionsv1alpha1.RegistryHost, it supports all host options that are needed by the extension.
+type Host struct {
+	// URL is the endpoint address of the registry host.
+	URL string
+	// Capabilities are the operations the host is capable of performing.
+	Capabilities []extensionsv1alpha1.RegistryCapability
+	// CACerts are paths to CA certificates used to verify the host's TLS certificate.
+	CACerts []string
+	// ClientCertificate is a pair of paths to the client certificate and key used to authenticate against the host.
+	ClientCertificate []string
+	// SkipVerify disables the verification of the host's TLS certificate.
+	SkipVerify bool
+	// OverridePath denotes that the path of the URL is the API root of the host. containerd does not append '/v2' to it.
+	OverridePath bool
+	// Header are the HTTP headers which are sent with each request to the host.
+	Header map[string][]string
+}
+
+// HostsConfig is the content of a containerd hosts.toml file.
+type HostsConfig struct {
+	// Server is the upstream registry server.
+	Server string
+	// DisableServerFallback disables all capabilities of the server, hence containerd does not fall back to the server
+	// when all hosts fail.
+	DisableServerFallback bool
+	// Hosts are the registry hosts which are tried in order before falling back to the server.
+	Hosts []Host
+}
+
+// HostsFilePath returns the path of the containerd hosts.toml file for the given upstream.
+func HostsFilePath(upstream string) string {
+	return path.Join(CertsDirectory, upstream, "hosts.toml")
+}
+
+// HostDirectoryName returns a directory name for the given host URL which can be used to store host specific files.
+func HostDirectoryName(hostURL string) string {
+	if i := strings.Index(hostURL, "://"); i != -1 {
+		hostURL = hostURL[i+len("://"):]
+	}
+
+	return strings.NewReplacer(":", "_", "/", "_").Replace(strings.TrimSuffix(hostURL, "/"))
+}
+
+// RenderHosts renders the containerd hosts.toml file for the given configuration.
+func RenderHosts(config HostsConfig) ([]byte, error) {
+	hosts := make([]Host, 0, len(config.Hosts))
+	for _, host := range config.Hosts {
+		if len(host.Capabilities) == 0 {
+			host.Capabilities = []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability}
+		}
+		hosts = append(hosts, host)
+	}
+	config.Hosts = hosts
+
+	var hostsTOML bytes.Buffer
+	if err := hostsTemplate.Execute(&hostsTOML, config); err != nil {
+		return nil, err
+	}
+
+	return hostsTOML.Bytes(), nil
+}
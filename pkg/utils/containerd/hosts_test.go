@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package containerd_test
+
+import (
+	"testing"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/utils/containerd"
+)
+
+func TestContainerdUtils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Containerd Utils")
+}
+
+var _ = Describe("Containerd utils", func() {
+
+	DescribeTable("#HostDirectoryName",
+		func(hostURL, expected string) {
+			Expect(containerd.HostDirectoryName(hostURL)).To(Equal(expected))
+		},
+		Entry("host", "https://mirror.example.com", "mirror.example.com"),
+		Entry("host with port", "http://mirror.example.com:8080", "mirror.example.com_8080"),
+		Entry("host with path", "https://mirror.example.com/v2/docker-remote/", "mirror.example.com_v2_docker-remote"),
+	)
+
+	Describe("#HostsFilePath", func() {
+		It("should return the path of the hosts.toml file", func() {
+			Expect(containerd.HostsFilePath("docker.io")).To(Equal("/etc/containerd/certs.d/docker.io/hosts.toml"))
+		})
+	})
+
+	Describe("#RenderHosts", func() {
+		It("should render the hosts.toml file", func() {
+			hosts := []containerd.Host{
+				{
+					URL: "https://mirror1.example.com",
+				},
+				{
+					URL:               "https://mirror2.example.com:8443",
+					Capabilities:      []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability},
+					CACerts:           []string{"/etc/containerd/ca.crt"},
+					ClientCertificate: []string{"/etc/containerd/client.crt", "/etc/containerd/client.key"},
+					SkipVerify:        true,
+				},
+				{
+					URL:          "https://artifactory.example.com/v2/docker-remote",
+					OverridePath: true,
+				},
+			}
+
+			data, err := containerd.RenderHosts(containerd.HostsConfig{
+				Server: "https://registry-1.docker.io",
+				Hosts:  hosts,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`# managed by gardener-extension-registry-cache
+server = "https://registry-1.docker.io"
+
+[host."https://mirror1.example.com"]
+  capabilities = ["pull","resolve"]
+
+[host."https://mirror2.example.com:8443"]
+  capabilities = ["pull"]
+  ca = ["/etc/containerd/ca.crt"]
+  client = [["/etc/containerd/client.crt","/etc/containerd/client.key"]]
+  skip_verify = true
+
+[host."https://artifactory.example.com/v2/docker-remote"]
+  capabilities = ["pull","resolve"]
+  override_path = true
+
+`))
+			Expect(hosts[0].Capabilities).To(BeEmpty())
+		})
+
+		It("should render the hosts.toml file without server fallback", func() {
+			data, err := containerd.RenderHosts(containerd.HostsConfig{
+				Server:                "https://registry-1.docker.io",
+				DisableServerFallback: true,
+				Hosts: []containerd.Host{
+					{
+						URL:     "https://10.4.246.205:5000",
+						CACerts: []string{"/etc/containerd/certs.d/ca-bundle.pem"},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`# managed by gardener-extension-registry-cache
+server = "https://registry-1.docker.io"
+capabilities = []
+
+[host."https://10.4.246.205:5000"]
+  capabilities = ["pull","resolve"]
+  ca = ["/etc/containerd/certs.d/ca-bundle.pem"]
+
+`))
+		})
+
+		It("should render the headers of a host", func() {
+			data, err := containerd.RenderHosts(containerd.HostsConfig{
+				Server: "https://registry-1.docker.io",
+				Hosts: []containerd.Host{
+					{
+						URL: "https://mirror.example.com",
+						Header: map[string][]string{
+							"X-Foo":         {"bar"},
+							"Authorization": {"Basic Zm9vOmJhcg=="},
+						},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`# managed by gardener-extension-registry-cache
+server = "https://registry-1.docker.io"
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull","resolve"]
+
+  [host."https://mirror.example.com".header]
+    "Authorization" = ["Basic Zm9vOmJhcg=="]
+    "X-Foo" = ["bar"]
+
+`))
+		})
+	})
+})
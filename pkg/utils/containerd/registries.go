@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package containerd
+
+import (
+	"slices"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+)
+
+// PrependRegistryHosts returns the given hosts followed by the existing hosts with a different URL.
+// It is used for hosts which have to be tried by containerd before the hosts of other registry configurations for
+// the same upstream, e.g. the in-cluster registry cache.
+func PrependRegistryHosts(existing, hosts []extensionsv1alpha1.RegistryHost) []extensionsv1alpha1.RegistryHost {
+	return append(slices.Clone(hosts), withoutRegistryHosts(existing, hosts)...)
+}
+
+// AppendRegistryHosts returns the existing hosts with a different URL followed by the given hosts.
+// It is used for hosts which have to be tried by containerd after the hosts of other registry configurations for
+// the same upstream, e.g. the registry mirrors.
+func AppendRegistryHosts(existing, hosts []extensionsv1alpha1.RegistryHost) []extensionsv1alpha1.RegistryHost {
+	return append(withoutRegistryHosts(existing, hosts), hosts...)
+}
+
+func withoutRegistryHosts(existing, hosts []extensionsv1alpha1.RegistryHost) []extensionsv1alpha1.RegistryHost {
+	var result []extensionsv1alpha1.RegistryHost
+	for _, host := range existing {
+		if !slices.ContainsFunc(hosts, func(h extensionsv1alpha1.RegistryHost) bool { return h.URL == host.URL }) {
+			result = append(result, host)
+		}
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package containerd_test
+
+import (
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/utils/containerd"
+)
+
+var _ = Describe("Registries", func() {
+	var (
+		cacheHost  = extensionsv1alpha1.RegistryHost{URL: "http://10.4.246.205:5000", Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability}}
+		mirrorHost = extensionsv1alpha1.RegistryHost{URL: "https://mirror.gcr.io", Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability}}
+		otherHost  = extensionsv1alpha1.RegistryHost{URL: "https://mirror.example.com", Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability}}
+	)
+
+	Describe("#PrependRegistryHosts", func() {
+		It("should return the hosts if there are no existing hosts", func() {
+			Expect(containerd.PrependRegistryHosts(nil, []extensionsv1alpha1.RegistryHost{cacheHost})).To(Equal([]extensionsv1alpha1.RegistryHost{cacheHost}))
+		})
+
+		It("should prepend the hosts to the existing hosts", func() {
+			Expect(containerd.PrependRegistryHosts([]extensionsv1alpha1.RegistryHost{mirrorHost, otherHost}, []extensionsv1alpha1.RegistryHost{cacheHost})).To(Equal([]extensionsv1alpha1.RegistryHost{cacheHost, mirrorHost, otherHost}))
+		})
+
+		It("should replace existing hosts with the same URL", func() {
+			existingCacheHost := extensionsv1alpha1.RegistryHost{URL: cacheHost.URL}
+			Expect(containerd.PrependRegistryHosts([]extensionsv1alpha1.RegistryHost{existingCacheHost, mirrorHost}, []extensionsv1alpha1.RegistryHost{cacheHost})).To(Equal([]extensionsv1alpha1.RegistryHost{cacheHost, mirrorHost}))
+		})
+	})
+
+	Describe("#AppendRegistryHosts", func() {
+		It("should return the hosts if there are no existing hosts", func() {
+			Expect(containerd.AppendRegistryHosts(nil, []extensionsv1alpha1.RegistryHost{mirrorHost, otherHost})).To(Equal([]extensionsv1alpha1.RegistryHost{mirrorHost, otherHost}))
+		})
+
+		It("should append the hosts to the existing hosts", func() {
+			Expect(containerd.AppendRegistryHosts([]extensionsv1alpha1.RegistryHost{cacheHost}, []extensionsv1alpha1.RegistryHost{mirrorHost, otherHost})).To(Equal([]extensionsv1alpha1.RegistryHost{cacheHost, mirrorHost, otherHost}))
+		})
+
+		It("should replace existing hosts with the same URL", func() {
+			existingMirrorHost := extensionsv1alpha1.RegistryHost{URL: mirrorHost.URL}
+			Expect(containerd.AppendRegistryHosts([]extensionsv1alpha1.RegistryHost{cacheHost, existingMirrorHost}, []extensionsv1alpha1.RegistryHost{mirrorHost, otherHost})).To(Equal([]extensionsv1alpha1.RegistryHost{cacheHost, mirrorHost, otherHost}))
+		})
+	})
+})
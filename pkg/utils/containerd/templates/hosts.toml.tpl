@@ -0,0 +1,30 @@
+# managed by gardener-extension-registry-cache
+{{- if .Server }}
+server = {{ toJson .Server }}
+{{- end }}
+{{- if .DisableServerFallback }}
+capabilities = []
+{{- end }}
+{{ range .Hosts }}
+[host.{{ toJson .URL }}]
+  capabilities = {{ toJson .Capabilities }}
+  {{- if .CACerts }}
+  ca = {{ toJson .CACerts }}
+  {{- end }}
+  {{- if .ClientCertificate }}
+  client = [{{ toJson .ClientCertificate }}]
+  {{- end }}
+  {{- if .SkipVerify }}
+  skip_verify = true
+  {{- end }}
+  {{- if .OverridePath }}
+  override_path = true
+  {{- end }}
+  {{- if .Header }}
+
+  [host.{{ toJson .URL }}.header]
+  {{- range $name, $values := .Header }}
+    {{ toJson $name }} = {{ toJson $values }}
+  {{- end }}
+  {{- end }}
+{{ end }}
@@ -6,9 +6,12 @@ package registry
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 
 	"github.com/gardener/gardener/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
 )
@@ -22,6 +25,37 @@ func GetUpstreamURL(upstream string) string {
 	return "https://" + upstream
 }
 
+// URLPath returns the path of the given registry URL in the format `<scheme><host>[:<port>][<path>]`.
+func URLPath(registryURL string) string {
+	if index := strings.Index(registryURL, "://"); index != -1 {
+		registryURL = registryURL[index+len("://"):]
+	}
+	if index := strings.IndexByte(registryURL, '/'); index != -1 {
+		return registryURL[index:]
+	}
+
+	return ""
+}
+
+// RequiresOverridePath returns true if the path of the given registry URL contains the registry API root, i.e. a 'v2'
+// segment which is not the last one (e.g. `https://artifactory.example.com/v2/docker-remote`).
+// containerd appends '/v2' to paths which do not end with '/v2', unless the 'override_path' host option is set.
+func RequiresOverridePath(registryURL string) bool {
+	path := URLPath(registryURL)
+
+	return strings.Contains(path+"/", "/v2/") && !strings.HasSuffix(path, "/v2")
+}
+
+// DistributionRemoteURL returns the given registry URL in the format expected by the `proxy.remoteurl` field of the
+// distribution configuration. distribution appends '/v2' to the remote URL, hence a trailing '/v2' segment is trimmed.
+func DistributionRemoteURL(registryURL string) string {
+	if strings.HasSuffix(URLPath(registryURL), "/v2") {
+		return strings.TrimSuffix(registryURL, "/v2")
+	}
+
+	return registryURL
+}
+
 // GetLabels returns a map with 'app' and 'upstream-host' labels.
 func GetLabels(name, upstreamLabel string) map[string]string {
 	return map[string]string{
@@ -65,3 +99,57 @@ func ComputeKubernetesResourceName(upstream string) string {
 	upstreamLabel := ComputeUpstreamLabelValue(upstream)
 	return "registry-" + strings.ReplaceAll(upstreamLabel, ".", "-")
 }
+
+// ClusterIPs returns the cluster IPs of the given Service. For dual-stack Services, it returns one cluster IP per IP
+// family with the primary one first.
+func ClusterIPs(service *corev1.Service) []string {
+	if len(service.Spec.ClusterIPs) > 0 {
+		return service.Spec.ClusterIPs
+	}
+	if service.Spec.ClusterIP != "" {
+		return []string{service.Spec.ClusterIP}
+	}
+
+	return nil
+}
+
+// Endpoint returns the registry cache endpoint with the given scheme, host and port. IPv6 addresses are enclosed in
+// square brackets, e.g. `https://[fd00::1]:5000`.
+func Endpoint(scheme, host string, port int32) string {
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// LoadBalancerAddress returns the address (hostname or IP) of the load balancer of the given Service.
+// It returns an empty string when the load balancer is not ready yet.
+func LoadBalancerAddress(service *corev1.Service) string {
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			return ingress.Hostname
+		}
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+	}
+
+	return ""
+}
+
+// InternalLoadBalancerAnnotations returns the Service annotations which instruct the cloud provider of the given type
+// to create an internal load balancer, i.e. a load balancer which is only reachable from the network of the cluster.
+// It returns nil for provider types without a known annotation.
+func InternalLoadBalancerAnnotations(providerType string) map[string]string {
+	switch providerType {
+	case "aws":
+		return map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"}
+	case "gcp":
+		return map[string]string{"networking.gke.io/load-balancer-type": "Internal"}
+	case "azure":
+		return map[string]string{"service.beta.kubernetes.io/azure-load-balancer-internal": "true"}
+	case "openstack":
+		return map[string]string{"service.beta.kubernetes.io/openstack-internal-load-balancer": "true"}
+	case "alicloud":
+		return map[string]string{"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-address-type": "intranet"}
+	}
+
+	return nil
+}
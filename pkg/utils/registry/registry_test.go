@@ -9,6 +9,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 
 	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
 )
@@ -29,6 +30,38 @@ var _ = Describe("Registry utils", func() {
 		Entry("upstream is quay.io", "quay.io", "https://quay.io"),
 	)
 
+	DescribeTable("#URLPath",
+		func(registryURL, expected string) {
+			Expect(registryutils.URLPath(registryURL)).To(Equal(expected))
+		},
+		Entry("url without path", "https://registry.example.com", ""),
+		Entry("url with port and without path", "https://registry.example.com:8443", ""),
+		Entry("url with path", "https://registry.example.com:8443/repository/docker", "/repository/docker"),
+	)
+
+	DescribeTable("#RequiresOverridePath",
+		func(registryURL string, expected bool) {
+			Expect(registryutils.RequiresOverridePath(registryURL)).To(Equal(expected))
+		},
+		Entry("url without path", "https://registry.example.com", false),
+		Entry("url with path prefix", "https://registry.example.com/repository/docker", false),
+		Entry("url with path ending with v2", "https://registry.example.com/repository/docker/v2", false),
+		Entry("url with v2 path", "https://registry.example.com/v2", false),
+		Entry("url with path containing v2", "https://artifactory.example.com/v2/docker-remote", true),
+		Entry("url with path containing v2 prefixed segment", "https://registry.example.com/v2foo/docker", false),
+	)
+
+	DescribeTable("#DistributionRemoteURL",
+		func(registryURL, expected string) {
+			Expect(registryutils.DistributionRemoteURL(registryURL)).To(Equal(expected))
+		},
+		Entry("url without path", "https://registry.example.com", "https://registry.example.com"),
+		Entry("url with path prefix", "https://registry.example.com/repository/docker", "https://registry.example.com/repository/docker"),
+		Entry("url with path ending with v2", "https://registry.example.com/repository/docker/v2", "https://registry.example.com/repository/docker"),
+		Entry("url with v2 path", "https://registry.example.com/v2", "https://registry.example.com"),
+		Entry("url with path ending with v2 prefixed segment", "https://registry.example.com/repository/foov2", "https://registry.example.com/repository/foov2"),
+	)
+
 	DescribeTable("#ComputeUpstreamLabelValue",
 		func(upstream, expected string) {
 			actual := registryutils.ComputeUpstreamLabelValue(upstream)
@@ -55,4 +88,43 @@ var _ = Describe("Registry utils", func() {
 		Entry("long upstream ends with port", "my-very-long-registry.long-subdomain.io:8443", "registry-my-very-long-registry-long-subdomain--8cb9e"),
 		Entry("long upstream ends like a port", "my-very-long-registry.long-subdomain.io-8443", "registry-my-very-long-registry-long-subdomain--e91ed"),
 	)
+
+	DescribeTable("#ClusterIPs",
+		func(spec corev1.ServiceSpec, expected []string) {
+			Expect(registryutils.ClusterIPs(&corev1.Service{Spec: spec})).To(Equal(expected))
+		},
+		Entry("no cluster IP", corev1.ServiceSpec{}, nil),
+		Entry("only cluster IP", corev1.ServiceSpec{ClusterIP: "10.4.0.10"}, []string{"10.4.0.10"}),
+		Entry("IPv6-only", corev1.ServiceSpec{ClusterIP: "fd00:10:4::a", ClusterIPs: []string{"fd00:10:4::a"}}, []string{"fd00:10:4::a"}),
+		Entry("dual-stack", corev1.ServiceSpec{ClusterIP: "10.4.0.10", ClusterIPs: []string{"10.4.0.10", "fd00:10:4::a"}}, []string{"10.4.0.10", "fd00:10:4::a"}),
+	)
+
+	DescribeTable("#Endpoint",
+		func(scheme, host string, port int32, expected string) {
+			Expect(registryutils.Endpoint(scheme, host, port)).To(Equal(expected))
+		},
+		Entry("IPv4 address", "https", "10.4.0.10", int32(5000), "https://10.4.0.10:5000"),
+		Entry("IPv6 address", "http", "fd00:10:4::a", int32(5000), "http://[fd00:10:4::a]:5000"),
+		Entry("hostname with custom port", "https", "lb.example.com", int32(8443), "https://lb.example.com:8443"),
+	)
+
+	DescribeTable("#LoadBalancerAddress",
+		func(ingress []corev1.LoadBalancerIngress, expected string) {
+			service := &corev1.Service{Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress}}}
+
+			Expect(registryutils.LoadBalancerAddress(service)).To(Equal(expected))
+		},
+		Entry("load balancer not ready", nil, ""),
+		Entry("load balancer with IP", []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}, "203.0.113.10"),
+		Entry("load balancer with hostname", []corev1.LoadBalancerIngress{{Hostname: "lb.example.com", IP: "203.0.113.10"}}, "lb.example.com"),
+	)
+
+	DescribeTable("#InternalLoadBalancerAnnotations",
+		func(providerType string, expected map[string]string) {
+			Expect(registryutils.InternalLoadBalancerAnnotations(providerType)).To(Equal(expected))
+		},
+		Entry("aws", "aws", map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"}),
+		Entry("gcp", "gcp", map[string]string{"networking.gke.io/load-balancer-type": "Internal"}),
+		Entry("unknown provider", "local", nil),
+	)
 })
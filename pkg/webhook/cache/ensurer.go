@@ -6,8 +6,12 @@ package cache
 
 import (
 	"context"
+	_ "embed"
 	"encoding/base64"
 	"fmt"
+	"net"
+	"net/url"
+	"path"
 	"slices"
 	"strings"
 
@@ -15,6 +19,7 @@ import (
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	gcontext "github.com/gardener/gardener/extensions/pkg/webhook/context"
 	"github.com/gardener/gardener/extensions/pkg/webhook/controlplane/genericmutator"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/go-logr/logr"
@@ -25,9 +30,29 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/utils/containerd"
 )
 
-const caBundlePath = "/etc/containerd/certs.d/ca-bundle.pem"
+const (
+	caBundlePath = "/etc/containerd/certs.d/ca-bundle.pem"
+	// filesDirectory is the directory on the Node containing the CA bundles of the registry caches with provided certificates.
+	filesDirectory = "/etc/containerd/registry-cache"
+
+	// hostsEntriesPath is the path of the file on the Node containing the hosts entries of the registry cache hostnames.
+	hostsEntriesPath = "/var/lib/registry-cache/hosts"
+	// updateHostsScriptPath is the path of the script on the Node which adds the hosts entries to /etc/hosts.
+	updateHostsScriptPath = "/var/lib/registry-cache/update-hosts.sh"
+	// updateHostsUnitName is the name of the systemd unit which runs the update hosts script.
+	updateHostsUnitName = "registry-cache-hosts.service"
+	// removeHostsEntriesCommand removes the block of the registry cache entries from /etc/hosts when the update hosts
+	// unit is stopped. It does not depend on the update hosts script which is removed together with the unit.
+	removeHostsEntriesCommand = `/bin/sh -c 'sed "/^# BEGIN registry-cache/,/^# END registry-cache/d" /etc/hosts > /etc/hosts.registry-cache && cat /etc/hosts.registry-cache > /etc/hosts; rm -f /etc/hosts.registry-cache'`
+)
+
+var (
+	//go:embed scripts/update-hosts.sh
+	updateHostsScript string
+)
 
 // NewEnsurer creates a new registry cache ensurer.
 func NewEnsurer(client client.Client, decoder runtime.Decoder, logger logr.Logger) genericmutator.Ensurer {
@@ -60,25 +85,34 @@ func (e *ensurer) EnsureCRIConfig(ctx context.Context, gctx gcontext.GardenConte
 	if err != nil {
 		return err
 	}
+	if registryStatus == nil {
+		return nil
+	}
 
 	if newCRIConfig.Containerd == nil {
 		newCRIConfig.Containerd = &extensionsv1alpha1.ContainerdConfig{}
 	}
 
 	for _, cache := range registryStatus.Caches {
+		if cache.Strict {
+			// The hosts.toml file for the upstream of a strict registry cache is added by EnsureAdditionalFiles. Remove the
+			// registry config to prevent gardener-node-agent from overwriting it.
+			newCRIConfig.Containerd.Registries = slices.DeleteFunc(newCRIConfig.Containerd.Registries, func(registryConfig extensionsv1alpha1.RegistryConfig) bool {
+				return registryConfig.Upstream == cache.Upstream
+			})
+			continue
+		}
+
 		cfg := extensionsv1alpha1.RegistryConfig{
 			Upstream: cache.Upstream,
 			Server:   ptr.To(cache.RemoteURL),
 			Hosts: []extensionsv1alpha1.RegistryHost{{
-				URL:          cache.Endpoint,
-				Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability},
+				URL:          hostURL(cache, cluster.Shoot),
+				Capabilities: registryCapabilities(cache.Capabilities),
 			}},
 			ReadinessProbe: ptr.To(true),
 		}
-
-		if strings.HasPrefix(cache.Endpoint, "https://") {
-			cfg.Hosts[0].CACerts = []string{caBundlePath}
-		}
+		cfg.Hosts[0].CACerts = caCerts(cache)
 
 		i := slices.IndexFunc(newCRIConfig.Containerd.Registries, func(registryConfig extensionsv1alpha1.RegistryConfig) bool {
 			return registryConfig.Upstream == cfg.Upstream
@@ -86,6 +120,9 @@ func (e *ensurer) EnsureCRIConfig(ctx context.Context, gctx gcontext.GardenConte
 		if i == -1 {
 			newCRIConfig.Containerd.Registries = append(newCRIConfig.Containerd.Registries, cfg)
 		} else {
+			// The registry config for the upstream can already contain the hosts of the registry-mirror extension.
+			// The registry cache has to be tried first, hence its host is prepended to the existing ones.
+			cfg.Hosts = containerd.PrependRegistryHosts(newCRIConfig.Containerd.Registries[i].Hosts, cfg.Hosts)
 			newCRIConfig.Containerd.Registries[i] = cfg
 		}
 	}
@@ -93,7 +130,8 @@ func (e *ensurer) EnsureCRIConfig(ctx context.Context, gctx gcontext.GardenConte
 	return nil
 }
 
-// EnsureAdditionalFiles ensures that the CA bundle is added to the <new> files.
+// EnsureAdditionalFiles ensures that the CA bundles and the hosts.toml files of the strict registry caches are added to
+// the <new> files.
 func (e *ensurer) EnsureAdditionalFiles(ctx context.Context, gctx gcontext.GardenContext, newFiles, _ *[]extensionsv1alpha1.File) error {
 	cluster, err := gctx.GetCluster(ctx)
 	if err != nil {
@@ -108,9 +146,101 @@ func (e *ensurer) EnsureAdditionalFiles(ctx context.Context, gctx gcontext.Garde
 	if err != nil {
 		return err
 	}
+	if registryStatus == nil {
+		return nil
+	}
+
+	var hostsEntries []string
+	for _, cache := range registryStatus.Caches {
+		if cache.Hostname != nil {
+			entry, err := hostsEntry(cache, cluster.Shoot)
+			if err != nil {
+				return err
+			}
+			hostsEntries = append(hostsEntries, entry)
+		}
+
+		if cache.CABundleSecretName != nil {
+			caBundleSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      *cache.CABundleSecretName,
+					Namespace: cluster.ObjectMeta.Name,
+				},
+			}
+			if err := e.client.Get(ctx, client.ObjectKeyFromObject(caBundleSecret), caBundleSecret); err != nil {
+				return fmt.Errorf("failed to get CA bundle secret '%s' for upstream %s: %w", client.ObjectKeyFromObject(caBundleSecret), cache.Upstream, err)
+			}
+
+			*newFiles = extensionswebhook.EnsureFileWithPath(*newFiles, extensionsv1alpha1.File{
+				Path:        providedCABundlePath(cache.Upstream),
+				Permissions: ptr.To[uint32](0644),
+				Content: extensionsv1alpha1.FileContent{
+					Inline: &extensionsv1alpha1.FileContentInline{
+						Encoding: "b64",
+						Data:     base64.StdEncoding.EncodeToString(caBundleSecret.Data["ca.crt"]),
+					},
+				},
+			})
+		}
+
+		if !cache.Strict {
+			continue
+		}
+
+		host := containerd.Host{
+			URL:          hostURL(cache, cluster.Shoot),
+			Capabilities: registryCapabilities(cache.Capabilities),
+			CACerts:      caCerts(cache),
+		}
+
+		// containerd's registry configuration via the OperatingSystemConfig does not support disabling the fallback to
+		// the upstream server, hence the hosts.toml file is rendered by the extension.
+		hostsTOML, err := containerd.RenderHosts(containerd.HostsConfig{
+			Server:                cache.RemoteURL,
+			DisableServerFallback: true,
+			Hosts:                 []containerd.Host{host},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to render hosts.toml for upstream %s: %w", cache.Upstream, err)
+		}
+
+		*newFiles = extensionswebhook.EnsureFileWithPath(*newFiles, extensionsv1alpha1.File{
+			Path:        containerd.HostsFilePath(cache.Upstream),
+			Permissions: ptr.To[uint32](0644),
+			Content: extensionsv1alpha1.FileContent{
+				Inline: &extensionsv1alpha1.FileContentInline{
+					Encoding: "b64",
+					Data:     base64.StdEncoding.EncodeToString(hostsTOML),
+				},
+			},
+		})
+	}
+
+	if len(hostsEntries) > 0 {
+		*newFiles = extensionswebhook.EnsureFileWithPath(*newFiles, extensionsv1alpha1.File{
+			Path:        hostsEntriesPath,
+			Permissions: ptr.To[uint32](0644),
+			Content: extensionsv1alpha1.FileContent{
+				Inline: &extensionsv1alpha1.FileContentInline{
+					Encoding: "b64",
+					Data:     base64.StdEncoding.EncodeToString([]byte(strings.Join(hostsEntries, "\n") + "\n")),
+				},
+			},
+		})
+		*newFiles = extensionswebhook.EnsureFileWithPath(*newFiles, extensionsv1alpha1.File{
+			Path:        updateHostsScriptPath,
+			Permissions: ptr.To[uint32](0755),
+			Content: extensionsv1alpha1.FileContent{
+				Inline: &extensionsv1alpha1.FileContentInline{
+					Encoding: "b64",
+					Data:     base64.StdEncoding.EncodeToString([]byte(updateHostsScript)),
+				},
+			},
+		})
+	}
 
 	if registryStatus.CASecretName == nil {
-		e.logger.Info("Registry status does not contain caSecretName, skipping the OperatingSystemConfig mutation", "shoot", client.ObjectKeyFromObject(cluster.Shoot))
+		e.logger.Info("Registry status does not contain caSecretName, skipping the CA bundle file", "shoot", client.ObjectKeyFromObject(cluster.Shoot))
 		return nil
 	}
 
@@ -143,23 +273,66 @@ func (e *ensurer) EnsureAdditionalFiles(ctx context.Context, gctx gcontext.Garde
 	return nil
 }
 
+// EnsureAdditionalUnits ensures that the unit adding the hosts entries of the registry cache hostnames to /etc/hosts is
+// added to the <new> units.
+func (e *ensurer) EnsureAdditionalUnits(ctx context.Context, gctx gcontext.GardenContext, newUnits, _ *[]extensionsv1alpha1.Unit) error {
+	cluster, err := gctx.GetCluster(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster resource: %w", err)
+	}
+
+	if !e.shouldMutate(cluster) {
+		return nil
+	}
+
+	registryStatus, err := e.getProviderStatus(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if registryStatus == nil {
+		return nil
+	}
+
+	if !slices.ContainsFunc(registryStatus.Caches, func(cache api.RegistryCacheStatus) bool { return cache.Hostname != nil }) {
+		return nil
+	}
+
+	*newUnits = extensionswebhook.EnsureUnitWithName(*newUnits, extensionsv1alpha1.Unit{
+		Name:    updateHostsUnitName,
+		Command: ptr.To(extensionsv1alpha1.CommandRestart),
+		Enable:  ptr.To(true),
+		Content: ptr.To(`[Unit]
+Description=Adds the hosts entries of the registry caches to /etc/hosts
+Before=containerd.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=` + updateHostsScriptPath + ` ` + hostsEntriesPath + `
+ExecStop=` + removeHostsEntriesCommand + `
+
+[Install]
+WantedBy=multi-user.target
+`),
+		// The unit is restarted by gardener-node-agent when the hosts entries change. It is stopped by gardener-node-agent
+		// when it is removed from the OperatingSystemConfig, e.g. when the registry caches do not use hostnames anymore.
+		FilePaths: []string{hostsEntriesPath, updateHostsScriptPath},
+	})
+
+	return nil
+}
+
 func (e *ensurer) shouldMutate(cluster *extensionscontroller.Cluster) bool {
 	if cluster.Shoot.DeletionTimestamp != nil {
 		e.logger.Info("Shoot has a deletion timestamp set, skipping the OperatingSystemConfig mutation", "shoot", client.ObjectKeyFromObject(cluster.Shoot))
 		return false
 	}
 
-	// If hibernation is enabled for Shoot, then the .status.providerStatus field of the registry-cache Extension can be missing (on Shoot creation)
-	// or outdated (if for hibernated Shoot a new registry is added). Hence, we skip the OperatingSystemConfig mutation when hibernation is enabled.
-	// When Shoot is waking up, then .status.providerStatus will be updated in the Extension and the OperatingSystemConfig will be mutated according to it.
-	if v1beta1helper.HibernationIsEnabled(cluster.Shoot) {
-		e.logger.Info("Hibernation is enabled for Shoot, skipping the OperatingSystemConfig mutation", "shoot", client.ObjectKeyFromObject(cluster.Shoot))
-		return false
-	}
-
 	return true
 }
 
+// getProviderStatus returns the provider status of the registry-cache Extension. It returns nil if the Shoot is
+// hibernated and the Extension has no provider status yet.
 func (e *ensurer) getProviderStatus(ctx context.Context, cluster *extensionscontroller.Cluster) (*api.RegistryStatus, error) {
 	extension := &extensionsv1alpha1.Extension{
 		ObjectMeta: metav1.ObjectMeta{
@@ -172,6 +345,11 @@ func (e *ensurer) getProviderStatus(ctx context.Context, cluster *extensionscont
 	}
 
 	if extension.Status.ProviderStatus == nil {
+		// The provider status is kept during hibernation, it is missing only when the Shoot is created with hibernation enabled.
+		if v1beta1helper.HibernationIsEnabled(cluster.Shoot) {
+			e.logger.Info("Hibernation is enabled for Shoot and the extension does not have a .status.providerStatus specified, skipping the OperatingSystemConfig mutation", "shoot", client.ObjectKeyFromObject(cluster.Shoot))
+			return nil, nil
+		}
 		return nil, fmt.Errorf("extension '%s' does not have a .status.providerStatus specified", client.ObjectKeyFromObject(extension))
 	}
 
@@ -181,3 +359,105 @@ func (e *ensurer) getProviderStatus(ctx context.Context, cluster *extensionscont
 	}
 	return registryStatus, nil
 }
+
+// endpoint returns the endpoint of the given registry cache for the IP family of the Nodes of the given Shoot. The Nodes
+// of a dual-stack Shoot use the first IP family of the Shoot networking as primary IP family.
+// The providerStatus of Extensions reconciled by older versions of the extension does not contain endpoints per IP family,
+// hence the endpoint for the primary IP family of the Service is returned when there is no matching endpoint.
+func endpoint(cache api.RegistryCacheStatus, shoot *gardencorev1beta1.Shoot) string {
+	if shoot.Spec.Networking == nil || len(shoot.Spec.Networking.IPFamilies) == 0 {
+		return cache.Endpoint
+	}
+
+	nodeIPFamily := shoot.Spec.Networking.IPFamilies[0]
+	for _, e := range cache.Endpoints {
+		u, err := url.Parse(e)
+		if err != nil {
+			continue
+		}
+
+		ip := net.ParseIP(u.Hostname())
+		if ip == nil {
+			continue
+		}
+
+		if (ip.To4() != nil) == (nodeIPFamily == gardencorev1beta1.IPFamilyIPv4) {
+			return e
+		}
+	}
+
+	return cache.Endpoint
+}
+
+// hostURL returns the URL of the given registry cache in the containerd configuration. The hostname of the registry
+// cache is used instead of the cluster IP when it is set.
+func hostURL(cache api.RegistryCacheStatus, shoot *gardencorev1beta1.Shoot) string {
+	e := endpoint(cache, shoot)
+	if cache.Hostname == nil {
+		return e
+	}
+
+	u, err := url.Parse(e)
+	if err != nil {
+		return e
+	}
+	u.Host = net.JoinHostPort(*cache.Hostname, u.Port())
+
+	return u.String()
+}
+
+// hostsEntry returns the hosts entry resolving the hostname of the given registry cache to the cluster IP for the IP
+// family of the Nodes. The Nodes do not use the cluster DNS, hence the hostname cannot be resolved without the cluster
+// IP. A changed cluster IP is only propagated with the next reconciliation of the OperatingSystemConfig.
+func hostsEntry(cache api.RegistryCacheStatus, shoot *gardencorev1beta1.Shoot) (string, error) {
+	e := endpoint(cache, shoot)
+	u, err := url.Parse(e)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse endpoint %q of upstream %s: %w", e, cache.Upstream, err)
+	}
+
+	return u.Hostname() + " " + *cache.Hostname, nil
+}
+
+// registryCapabilities maps the given registry cache capabilities to containerd registry capabilities.
+// The providerStatus of Extensions reconciled by older versions of the extension does not contain capabilities, hence
+// pull and resolve are returned when there are no capabilities.
+func registryCapabilities(capabilities []api.RegistryCacheCapability) []extensionsv1alpha1.RegistryCapability {
+	if len(capabilities) == 0 {
+		return []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability}
+	}
+
+	var registryCapabilities []extensionsv1alpha1.RegistryCapability
+	for _, c := range capabilities {
+		switch c {
+		case api.RegistryCacheCapabilityPull:
+			registryCapabilities = append(registryCapabilities, extensionsv1alpha1.PullCapability)
+		case api.RegistryCacheCapabilityResolve:
+			registryCapabilities = append(registryCapabilities, extensionsv1alpha1.ResolveCapability)
+		}
+	}
+	return registryCapabilities
+}
+
+// caCerts returns the CA certificates used by containerd to verify the certificate of the given registry cache.
+// A provided certificate without CA bundle is verified with the system CAs of the Node, hence no CA certificates are
+// returned for it.
+func caCerts(cache api.RegistryCacheStatus) []string {
+	if !strings.HasPrefix(cache.Endpoint, "https://") {
+		return nil
+	}
+
+	if !cache.ProvidedCertificate {
+		return []string{caBundlePath}
+	}
+
+	if cache.CABundleSecretName != nil {
+		return []string{providedCABundlePath(cache.Upstream)}
+	}
+
+	return nil
+}
+
+func providedCABundlePath(upstream string) string {
+	return path.Join(filesDirectory, upstream, "ca.crt")
+}
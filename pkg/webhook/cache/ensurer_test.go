@@ -16,6 +16,7 @@ import (
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -151,11 +152,14 @@ var _ = Describe("Ensurer", func() {
 			Expect(criConfig.Containerd).To(Equal(expectedContainerd))
 		})
 
-		It("should do nothing if hibernation is enabled for Shoot", func() {
+		It("should do nothing if hibernation is enabled for Shoot and extension .status.providerStatus is nil", func() {
 			cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+			extension.Status.DefaultStatus.ProviderStatus = nil
 
 			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
 
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
 			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
 			expectedContainerd := criConfig.Containerd.DeepCopy()
 
@@ -163,6 +167,26 @@ var _ = Describe("Ensurer", func() {
 			Expect(criConfig.Containerd).To(Equal(expectedContainerd))
 		})
 
+		It("should add the registry config of the last known .status.providerStatus if hibernation is enabled for Shoot", func() {
+			cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, []extensionsv1alpha1.RegistryConfig{
+				createRegistryConfig("docker.io", "https://registry-1.docker.io", "https://10.0.0.1:5000", caCerts),
+				createRegistryConfig("europe-docker.pkg.dev", "https://europe-docker.pkg.dev", "http://10.0.0.2:5000", nil),
+				createRegistryConfig("my-registry.io:5000", "http://my-registry.io:5000", "https://10.0.0.3:5000", caCerts),
+			}...)
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
 		It("return err when it fails to get the extension", func() {
 			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
 
@@ -250,14 +274,185 @@ var _ = Describe("Ensurer", func() {
 			}...)
 
 			criConfig.Containerd.Registries = append(criConfig.Containerd.Registries, []extensionsv1alpha1.RegistryConfig{
-				createRegistryConfig("docker.io", "foo", "bar", caCerts),
-				createRegistryConfig("europe-docker.pkg.dev", "foo", "bar", nil),
-				createRegistryConfig("my-registry.io:5000", "foo", "bar", caCerts),
+				createRegistryConfig("docker.io", "foo", "https://10.0.0.1:5000", nil),
+				createRegistryConfig("europe-docker.pkg.dev", "foo", "http://10.0.0.2:5000", caCerts),
+				createRegistryConfig("my-registry.io:5000", "foo", "https://10.0.0.3:5000", nil),
+			}...)
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should remove the registry config of a strict registry cache", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus).Caches[0].Strict = true
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, []extensionsv1alpha1.RegistryConfig{
+				createRegistryConfig("europe-docker.pkg.dev", "https://europe-docker.pkg.dev", "http://10.0.0.2:5000", nil),
+				createRegistryConfig("my-registry.io:5000", "http://my-registry.io:5000", "https://10.0.0.3:5000", caCerts),
+			}...)
+
+			criConfig.Containerd.Registries = append(criConfig.Containerd.Registries, createRegistryConfig("docker.io", "https://registry-1.docker.io", "https://10.0.0.1:5000", caCerts))
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should add the capabilities of the registry caches", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			registryStatus := extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus)
+			registryStatus.Caches[0].Capabilities = []v1alpha3.RegistryCacheCapability{v1alpha3.RegistryCacheCapabilityPull}
+			registryStatus.Caches[1].Capabilities = []v1alpha3.RegistryCacheCapability{v1alpha3.RegistryCacheCapabilityPull, v1alpha3.RegistryCacheCapabilityResolve}
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			dockerRegistryConfig := createRegistryConfig("docker.io", "https://registry-1.docker.io", "https://10.0.0.1:5000", caCerts)
+			dockerRegistryConfig.Hosts[0].Capabilities = []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability}
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, []extensionsv1alpha1.RegistryConfig{
+				dockerRegistryConfig,
+				createRegistryConfig("europe-docker.pkg.dev", "https://europe-docker.pkg.dev", "http://10.0.0.2:5000", nil),
+				createRegistryConfig("my-registry.io:5000", "http://my-registry.io:5000", "https://10.0.0.3:5000", caCerts),
 			}...)
 
 			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
 			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
 		})
+
+		It("should use the CA bundles of registry caches with provided certificates", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			registryStatus := extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus)
+			registryStatus.Caches[0].ProvidedCertificate = true
+			registryStatus.Caches[0].CABundleSecretName = ptr.To("ref-docker-ca")
+			registryStatus.Caches[2].ProvidedCertificate = true
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, []extensionsv1alpha1.RegistryConfig{
+				createRegistryConfig("docker.io", "https://registry-1.docker.io", "https://10.0.0.1:5000", []string{"/etc/containerd/registry-cache/docker.io/ca.crt"}),
+				createRegistryConfig("europe-docker.pkg.dev", "https://europe-docker.pkg.dev", "http://10.0.0.2:5000", nil),
+				createRegistryConfig("my-registry.io:5000", "http://my-registry.io:5000", "https://10.0.0.3:5000", nil),
+			}...)
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should use the IPv6 endpoints for an IPv6-only Shoot", func() {
+			cluster.Shoot.Spec.Networking = &gardencorev1beta1.Networking{IPFamilies: []gardencorev1beta1.IPFamily{gardencorev1beta1.IPFamilyIPv6}}
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			registryStatus := extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus)
+			registryStatus.Caches = []v1alpha3.RegistryCacheStatus{
+				{
+					Upstream:  "docker.io",
+					Endpoint:  "https://[fd00:10:4::a]:5000",
+					Endpoints: []string{"https://[fd00:10:4::a]:5000"},
+					RemoteURL: "https://registry-1.docker.io",
+				},
+			}
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, createRegistryConfig("docker.io", "https://registry-1.docker.io", "https://[fd00:10:4::a]:5000", caCerts))
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		DescribeTable("should use the endpoints for the Node IP family of a dual-stack Shoot",
+			func(ipFamilies []gardencorev1beta1.IPFamily, expectedEndpoint string) {
+				cluster.Shoot.Spec.Networking = &gardencorev1beta1.Networking{IPFamilies: ipFamilies}
+				gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+				registryStatus := extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus)
+				registryStatus.Caches = []v1alpha3.RegistryCacheStatus{
+					{
+						Upstream:  "docker.io",
+						Endpoint:  "https://10.0.0.1:5000",
+						Endpoints: []string{"https://10.0.0.1:5000", "https://[fd00:10:4::a]:5000"},
+						RemoteURL: "https://registry-1.docker.io",
+					},
+				}
+
+				Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+				ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+				expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+				expectedRegistries = append(expectedRegistries, createRegistryConfig("docker.io", "https://registry-1.docker.io", expectedEndpoint, caCerts))
+
+				Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+				Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+			},
+
+			Entry("IPv4 primary", []gardencorev1beta1.IPFamily{gardencorev1beta1.IPFamilyIPv4, gardencorev1beta1.IPFamilyIPv6}, "https://10.0.0.1:5000"),
+			Entry("IPv6 primary", []gardencorev1beta1.IPFamily{gardencorev1beta1.IPFamilyIPv6, gardencorev1beta1.IPFamilyIPv4}, "https://[fd00:10:4::a]:5000"),
+		)
+
+		It("should use the hostnames of the registry caches", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			registryStatus := extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus)
+			registryStatus.Caches[0].Hostname = ptr.To("registry-docker-io.kube-system.svc")
+			registryStatus.Caches[1].Hostname = ptr.To("registry-europe-docker-pkg-dev.kube-system.svc")
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, []extensionsv1alpha1.RegistryConfig{
+				createRegistryConfig("docker.io", "https://registry-1.docker.io", "https://registry-docker-io.kube-system.svc:5000", caCerts),
+				createRegistryConfig("europe-docker.pkg.dev", "https://europe-docker.pkg.dev", "http://registry-europe-docker-pkg-dev.kube-system.svc:5000", nil),
+				createRegistryConfig("my-registry.io:5000", "http://my-registry.io:5000", "https://10.0.0.3:5000", caCerts),
+			}...)
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should prepend the registry cache host to the hosts of an existing registry config", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			mirrorHost := extensionsv1alpha1.RegistryHost{
+				URL:          "https://mirror.gcr.io",
+				Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability},
+			}
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			dockerRegistryConfig := createRegistryConfig("docker.io", "https://registry-1.docker.io", "https://10.0.0.1:5000", caCerts)
+			dockerRegistryConfig.Hosts = append(dockerRegistryConfig.Hosts, mirrorHost)
+			expectedRegistries = append(expectedRegistries, []extensionsv1alpha1.RegistryConfig{
+				dockerRegistryConfig,
+				createRegistryConfig("europe-docker.pkg.dev", "https://europe-docker.pkg.dev", "http://10.0.0.2:5000", nil),
+				createRegistryConfig("my-registry.io:5000", "http://my-registry.io:5000", "https://10.0.0.3:5000", caCerts),
+			}...)
+
+			criConfig.Containerd.Registries = append(criConfig.Containerd.Registries, extensionsv1alpha1.RegistryConfig{
+				Upstream: "docker.io",
+				Server:   ptr.To("https://registry-1.docker.io"),
+				Hosts:    []extensionsv1alpha1.RegistryHost{mirrorHost},
+			})
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
 	})
 
 	Describe("#EnsureAdditionalFiles", func() {
@@ -347,11 +542,14 @@ var _ = Describe("Ensurer", func() {
 			Expect(newFiles).To(Equal(expectedNewFiles))
 		})
 
-		It("should do nothing if hibernation is enabled for Shoot", func() {
+		It("should do nothing if hibernation is enabled for Shoot and extension .status.providerStatus is nil", func() {
 			cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+			extension.Status.DefaultStatus.ProviderStatus = nil
 
 			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
 
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
 			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
 			expectedNewFiles := make([]extensionsv1alpha1.File, len(newFiles))
 			copy(expectedNewFiles, newFiles)
@@ -360,6 +558,22 @@ var _ = Describe("Ensurer", func() {
 			Expect(newFiles).To(Equal(expectedNewFiles))
 		})
 
+		It("should add the CA bundle file of the last known .status.providerStatus if hibernation is enabled for Shoot", func() {
+			cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			Expect(fakeClient.Create(ctx, caSecret)).To(Succeed())
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &newFiles, nil)).To(Succeed())
+			Expect(newFiles).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+				"Path": Equal("/etc/containerd/certs.d/ca-bundle.pem"),
+			})))
+		})
+
 		It("return err when it fails to get the extension", func() {
 			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
 
@@ -472,6 +686,173 @@ var _ = Describe("Ensurer", func() {
 			Expect(newFiles).To(ConsistOf(expectedNewFiles))
 		})
 
+		It("should add the hosts.toml file of a strict registry cache", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus).Caches = []v1alpha3.RegistryCacheStatus{
+				{
+					Upstream:  "docker.io",
+					Endpoint:  "https://10.0.0.1:5000",
+					RemoteURL: "https://registry-1.docker.io",
+					Strict:    true,
+				},
+				{
+					Upstream:  "europe-docker.pkg.dev",
+					Endpoint:  "http://10.0.0.2:5000",
+					RemoteURL: "https://europe-docker.pkg.dev",
+				},
+			}
+
+			Expect(fakeClient.Create(ctx, caSecret)).To(Succeed())
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+			expectedNewFiles := make([]extensionsv1alpha1.File, len(newFiles))
+			copy(expectedNewFiles, newFiles)
+			expectedNewFiles = append(expectedNewFiles,
+				extensionsv1alpha1.File{
+					Path:        "/etc/containerd/certs.d/docker.io/hosts.toml",
+					Permissions: ptr.To[uint32](0644),
+					Content: extensionsv1alpha1.FileContent{
+						Inline: &extensionsv1alpha1.FileContentInline{
+							Encoding: "b64",
+							Data: base64.StdEncoding.EncodeToString([]byte(`# managed by gardener-extension-registry-cache
+server = "https://registry-1.docker.io"
+capabilities = []
+
+[host."https://10.0.0.1:5000"]
+  capabilities = ["pull","resolve"]
+  ca = ["/etc/containerd/certs.d/ca-bundle.pem"]
+
+`)),
+						},
+					},
+				},
+				extensionsv1alpha1.File{
+					Path:        "/etc/containerd/certs.d/ca-bundle.pem",
+					Permissions: ptr.To[uint32](0644),
+					Content: extensionsv1alpha1.FileContent{
+						Inline: &extensionsv1alpha1.FileContentInline{
+							Encoding: "b64",
+							Data:     base64.StdEncoding.EncodeToString([]byte("bar")),
+						},
+					},
+				},
+			)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &newFiles, nil)).To(Succeed())
+			Expect(newFiles).To(ConsistOf(expectedNewFiles))
+		})
+
+		It("should add the hosts entries of the registry cache hostnames", func() {
+			cluster.Shoot.Spec.Networking = &gardencorev1beta1.Networking{IPFamilies: []gardencorev1beta1.IPFamily{gardencorev1beta1.IPFamilyIPv6, gardencorev1beta1.IPFamilyIPv4}}
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus).Caches = []v1alpha3.RegistryCacheStatus{
+				{
+					Upstream:  "docker.io",
+					Endpoint:  "https://10.0.0.1:5000",
+					Endpoints: []string{"https://10.0.0.1:5000", "https://[fd00:10:4::a]:5000"},
+					Hostname:  ptr.To("registry-docker-io.kube-system.svc"),
+					RemoteURL: "https://registry-1.docker.io",
+				},
+				{
+					Upstream:  "europe-docker.pkg.dev",
+					Endpoint:  "http://10.0.0.2:5000",
+					Hostname:  ptr.To("registry-europe-docker-pkg-dev.kube-system.svc"),
+					RemoteURL: "https://europe-docker.pkg.dev",
+				},
+			}
+
+			Expect(fakeClient.Create(ctx, caSecret)).To(Succeed())
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &newFiles, nil)).To(Succeed())
+			Expect(newFiles).To(ContainElements(
+				extensionsv1alpha1.File{
+					Path:        "/var/lib/registry-cache/hosts",
+					Permissions: ptr.To[uint32](0644),
+					Content: extensionsv1alpha1.FileContent{
+						Inline: &extensionsv1alpha1.FileContentInline{
+							Encoding: "b64",
+							Data: base64.StdEncoding.EncodeToString([]byte(`fd00:10:4::a registry-docker-io.kube-system.svc
+10.0.0.2 registry-europe-docker-pkg-dev.kube-system.svc
+`)),
+						},
+					},
+				},
+				MatchFields(IgnoreExtras, Fields{
+					"Path":        Equal("/var/lib/registry-cache/update-hosts.sh"),
+					"Permissions": PointTo(Equal(uint32(0755))),
+				}),
+			))
+		})
+
+		It("should add the CA bundle file of a registry cache with a provided certificate", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			registryStatus := extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus)
+			registryStatus.CASecretName = nil
+			registryStatus.Caches = []v1alpha3.RegistryCacheStatus{
+				{
+					Upstream:            "docker.io",
+					Endpoint:            "https://10.0.0.1:5000",
+					RemoteURL:           "https://registry-1.docker.io",
+					ProvidedCertificate: true,
+					CABundleSecretName:  ptr.To("ref-docker-ca"),
+				},
+			}
+
+			Expect(fakeClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ref-docker-ca",
+					Namespace: namespace,
+				},
+				Data: map[string][]byte{
+					"ca.crt": []byte("foo"),
+				},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+			expectedNewFiles := make([]extensionsv1alpha1.File, len(newFiles))
+			copy(expectedNewFiles, newFiles)
+			expectedNewFiles = append(expectedNewFiles,
+				extensionsv1alpha1.File{
+					Path:        "/etc/containerd/registry-cache/docker.io/ca.crt",
+					Permissions: ptr.To[uint32](0644),
+					Content: extensionsv1alpha1.FileContent{
+						Inline: &extensionsv1alpha1.FileContentInline{
+							Encoding: "b64",
+							Data:     base64.StdEncoding.EncodeToString([]byte("foo")),
+						},
+					},
+				},
+			)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &newFiles, nil)).To(Succeed())
+			Expect(newFiles).To(ConsistOf(expectedNewFiles))
+		})
+
+		It("should return err when the CA bundle secret of a registry cache with a provided certificate does not exist", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus).Caches = []v1alpha3.RegistryCacheStatus{
+				{
+					Upstream:            "docker.io",
+					Endpoint:            "https://10.0.0.1:5000",
+					RemoteURL:           "https://registry-1.docker.io",
+					ProvidedCertificate: true,
+					CABundleSecretName:  ptr.To("ref-docker-ca"),
+				},
+			}
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			err := ensurer.EnsureAdditionalFiles(ctx, gctx, &newFiles, nil)
+			Expect(err).To(MatchError(ContainSubstring("failed to get CA bundle secret '%s/ref-docker-ca' for upstream docker.io", namespace)))
+		})
+
 		It("should update file with the expected content if it already exists", func() {
 			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
 
@@ -509,6 +890,114 @@ var _ = Describe("Ensurer", func() {
 			Expect(newFiles).To(ConsistOf(expectedNewFiles))
 		})
 	})
+
+	Describe("#EnsureAdditionalUnits", func() {
+		var (
+			cluster   *extensions.Cluster
+			extension *extensionsv1alpha1.Extension
+
+			newUnits []extensionsv1alpha1.Unit
+		)
+
+		BeforeEach(func() {
+			cluster = &extensions.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: namespace},
+				Shoot:      &gardencorev1beta1.Shoot{},
+			}
+
+			extension = &extensionsv1alpha1.Extension{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "registry-cache",
+					Namespace: cluster.ObjectMeta.Name,
+				},
+				Status: extensionsv1alpha1.ExtensionStatus{
+					DefaultStatus: extensionsv1alpha1.DefaultStatus{
+						ProviderStatus: &runtime.RawExtension{
+							Object: &v1alpha3.RegistryStatus{
+								TypeMeta: metav1.TypeMeta{
+									APIVersion: v1alpha3.SchemeGroupVersion.String(),
+									Kind:       "RegistryStatus",
+								},
+								Caches: []v1alpha3.RegistryCacheStatus{
+									{
+										Upstream:  "docker.io",
+										Endpoint:  "https://10.0.0.1:5000",
+										RemoteURL: "https://registry-1.docker.io",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			newUnits = []extensionsv1alpha1.Unit{{Name: "foo.service"}}
+		})
+
+		It("should not add the unit when the registry caches do not use hostnames", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalUnits(ctx, gctx, &newUnits, nil)).To(Succeed())
+			Expect(newUnits).To(ConsistOf(extensionsv1alpha1.Unit{Name: "foo.service"}))
+		})
+
+		It("should add the unit updating the hosts entries when the registry caches use hostnames", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus).Caches[0].Hostname = ptr.To("registry-docker-io.kube-system.svc")
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalUnits(ctx, gctx, &newUnits, nil)).To(Succeed())
+			Expect(newUnits).To(ConsistOf(
+				extensionsv1alpha1.Unit{Name: "foo.service"},
+				MatchFields(IgnoreExtras, Fields{
+					"Name":    Equal("registry-cache-hosts.service"),
+					"Command": PointTo(Equal(extensionsv1alpha1.CommandRestart)),
+					"Enable":  PointTo(BeTrue()),
+					"Content": PointTo(And(
+						ContainSubstring("ExecStart=/var/lib/registry-cache/update-hosts.sh /var/lib/registry-cache/hosts"),
+						ContainSubstring(`ExecStop=/bin/sh -c 'sed "/^# BEGIN registry-cache/,/^# END registry-cache/d" /etc/hosts`),
+					)),
+					"FilePaths": ConsistOf("/var/lib/registry-cache/hosts", "/var/lib/registry-cache/update-hosts.sh"),
+				}),
+			))
+		})
+
+		It("should do nothing if hibernation is enabled for Shoot and extension .status.providerStatus is nil", func() {
+			cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+			extension.Status.DefaultStatus.ProviderStatus = nil
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalUnits(ctx, gctx, &newUnits, nil)).To(Succeed())
+			Expect(newUnits).To(ConsistOf(extensionsv1alpha1.Unit{Name: "foo.service"}))
+		})
+
+		It("should add the unit of the last known .status.providerStatus if hibernation is enabled for Shoot", func() {
+			cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+			extension.Status.ProviderStatus.Object.(*v1alpha3.RegistryStatus).Caches[0].Hostname = ptr.To("registry-docker-io.kube-system.svc")
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := cache.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalUnits(ctx, gctx, &newUnits, nil)).To(Succeed())
+			Expect(newUnits).To(ConsistOf(
+				extensionsv1alpha1.Unit{Name: "foo.service"},
+				MatchFields(IgnoreExtras, Fields{"Name": Equal("registry-cache-hosts.service")}),
+			))
+		})
+	})
 })
 
 func createRegistryConfig(upstream, server, host string, caCerts []string) extensionsv1alpha1.RegistryConfig {
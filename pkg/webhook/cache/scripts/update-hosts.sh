@@ -0,0 +1,30 @@
+#!/bin/bash
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+# Replaces the block of the registry cache entries in /etc/hosts with the entries from the given file.
+# The block is removed when the file is empty or does not exist.
+
+hosts_file="/etc/hosts"
+entries_file="$1"
+begin_marker="# BEGIN registry-cache"
+end_marker="# END registry-cache"
+
+tmp_file="$(mktemp)"
+trap 'rm -f "$tmp_file"' EXIT
+
+sed "/^${begin_marker}\$/,/^${end_marker}\$/d" "$hosts_file" > "$tmp_file"
+if [[ -s "$entries_file" ]]; then
+  {
+    echo "$begin_marker"
+    cat "$entries_file"
+    echo "$end_marker"
+  } >> "$tmp_file"
+fi
+
+# Write the content instead of moving the file to keep the inode (and a potential symlink) of /etc/hosts.
+if ! cmp -s "$tmp_file" "$hosts_file"; then
+  cat "$tmp_file" > "$hosts_file"
+fi
@@ -6,22 +6,33 @@ package mirror
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"path"
 	"slices"
 
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	gcontext "github.com/gardener/gardener/extensions/pkg/webhook/context"
 	"github.com/gardener/gardener/extensions/pkg/webhook/controlplane/genericmutator"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	api "github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror"
-	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/apis/mirror/helper"
+	"github.com/gardener/gardener-extension-registry-cache/pkg/utils/containerd"
 )
 
+// filesDirectory is the directory on the Node containing the files (CA bundles, client certificates) of the mirror hosts.
+const filesDirectory = "/etc/containerd/registry-mirror"
+
 // NewEnsurer creates a new mirror configuration ensurer.
 func NewEnsurer(client client.Client, decoder runtime.Decoder, logger logr.Logger) genericmutator.Ensurer {
 	return &ensurer{
@@ -49,57 +60,248 @@ func (e *ensurer) EnsureCRIConfig(ctx context.Context, gctx gcontext.GardenConte
 		e.logger.Info("Shoot has a deletion timestamp set, skipping the OperatingSystemConfig mutation", "shoot", client.ObjectKeyFromObject(cluster.Shoot))
 		return nil
 	}
-	extension := &extensionsv1alpha1.Extension{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "registry-mirror",
-			Namespace: cluster.ObjectMeta.Name,
-		},
-	}
-	if err := e.client.Get(ctx, client.ObjectKeyFromObject(extension), extension); err != nil {
-		return fmt.Errorf("failed to get extension '%s': %w", client.ObjectKeyFromObject(extension), err)
-	}
 
-	if extension.Spec.ProviderConfig == nil {
-		return fmt.Errorf("extension '%s' does not have a .spec.providerConfig specified", client.ObjectKeyFromObject(extension))
-	}
-
-	mirrorConfig := &api.MirrorConfig{}
-	if err := runtime.DecodeInto(e.decoder, extension.Spec.ProviderConfig.Raw, mirrorConfig); err != nil {
-		return fmt.Errorf("failed to decode providerConfig of extension '%s': %w", client.ObjectKeyFromObject(extension), err)
+	mirrorStatus, err := e.getProviderStatus(ctx, cluster)
+	if err != nil {
+		return err
 	}
 
 	if newCRIConfig.Containerd == nil {
 		newCRIConfig.Containerd = &extensionsv1alpha1.ContainerdConfig{}
 	}
 
-	for _, mirror := range mirrorConfig.Mirrors {
+	for _, mirror := range mirrorStatus.Mirrors {
+		i := slices.IndexFunc(newCRIConfig.Containerd.Registries, func(registryConfig extensionsv1alpha1.RegistryConfig) bool {
+			return registryConfig.Upstream == mirror.Upstream
+		})
+
+		if requiresHostsFile(mirror) {
+			// The hosts.toml file for the upstream is added by EnsureAdditionalFiles. Remove the registry config
+			// to prevent gardener-node-agent from overwriting it.
+			if i != -1 {
+				newCRIConfig.Containerd.Registries = slices.Delete(newCRIConfig.Containerd.Registries, i, i+1)
+			}
+			continue
+		}
+
 		cfg := extensionsv1alpha1.RegistryConfig{
 			Upstream: mirror.Upstream,
-			Server:   ptr.To(registryutils.GetUpstreamURL(mirror.Upstream)),
+			Server:   mirror.Server,
 		}
 		for _, host := range mirror.Hosts {
 			registryHost := extensionsv1alpha1.RegistryHost{
-				URL: host.Host,
+				URL:          host.Host,
+				Capabilities: registryCapabilities(host.Capabilities),
 			}
-			for _, c := range host.Capabilities {
-				switch c {
-				case api.MirrorHostCapabilityPull:
-					registryHost.Capabilities = append(registryHost.Capabilities, extensionsv1alpha1.PullCapability)
-				case api.MirrorHostCapabilityResolve:
-					registryHost.Capabilities = append(registryHost.Capabilities, extensionsv1alpha1.ResolveCapability)
-				}
+			if host.CABundleSecretName != nil {
+				registryHost.CACerts = []string{caBundlePath(mirror.Upstream, host.Host)}
 			}
 			cfg.Hosts = append(cfg.Hosts, registryHost)
 		}
-		i := slices.IndexFunc(newCRIConfig.Containerd.Registries, func(registryConfig extensionsv1alpha1.RegistryConfig) bool {
-			return registryConfig.Upstream == cfg.Upstream
-		})
 		if i == -1 {
 			newCRIConfig.Containerd.Registries = append(newCRIConfig.Containerd.Registries, cfg)
 		} else {
+			// The registry config for the upstream can already contain the host of the registry-cache extension.
+			// The mirror hosts have to be tried after the registry cache, hence they are appended to the existing hosts.
+			// The server and the readiness probe of the existing registry config take precedence.
+			existing := newCRIConfig.Containerd.Registries[i]
+			cfg.Hosts = containerd.AppendRegistryHosts(existing.Hosts, cfg.Hosts)
+			if existing.Server != nil {
+				cfg.Server = existing.Server
+			}
+			cfg.ReadinessProbe = existing.ReadinessProbe
 			newCRIConfig.Containerd.Registries[i] = cfg
 		}
 	}
 
 	return nil
 }
+
+// EnsureAdditionalFiles ensures that the CA bundles and client certificates of the mirror hosts and the hosts.toml files
+// of mirrors which cannot be expressed in the CRI config are added to the <new> files.
+func (e *ensurer) EnsureAdditionalFiles(ctx context.Context, gctx gcontext.GardenContext, newFiles, _ *[]extensionsv1alpha1.File) error {
+	cluster, err := gctx.GetCluster(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster resource: %w", err)
+	}
+
+	if cluster.Shoot.DeletionTimestamp != nil {
+		e.logger.Info("Shoot has a deletion timestamp set, skipping the OperatingSystemConfig mutation", "shoot", client.ObjectKeyFromObject(cluster.Shoot))
+		return nil
+	}
+
+	mirrorStatus, err := e.getProviderStatus(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	for _, mirror := range mirrorStatus.Mirrors {
+		hostsConfig := containerd.HostsConfig{
+			Server: ptr.Deref(mirror.Server, ""),
+		}
+
+		for _, host := range mirror.Hosts {
+			hostConfig := containerd.Host{
+				URL:          host.Host,
+				Capabilities: registryCapabilities(host.Capabilities),
+				SkipVerify:   host.SkipVerify,
+				OverridePath: host.OverridePath,
+			}
+
+			if host.CABundleSecretName != nil {
+				secret, err := e.getSecret(ctx, cluster.ObjectMeta.Name, *host.CABundleSecretName)
+				if err != nil {
+					return err
+				}
+
+				filePath := caBundlePath(mirror.Upstream, host.Host)
+				*newFiles = extensionswebhook.EnsureFileWithPath(*newFiles, inlineFile(filePath, 0644, secret.Data["ca.crt"]))
+				hostConfig.CACerts = []string{filePath}
+			}
+
+			if host.ClientCertificateSecretName != nil {
+				secret, err := e.getSecret(ctx, cluster.ObjectMeta.Name, *host.ClientCertificateSecretName)
+				if err != nil {
+					return err
+				}
+
+				var (
+					hostDirectory = hostFilesDirectory(mirror.Upstream, host.Host)
+					certPath      = path.Join(hostDirectory, "client.crt")
+					keyPath       = path.Join(hostDirectory, "client.key")
+				)
+				*newFiles = extensionswebhook.EnsureFileWithPath(*newFiles, inlineFile(certPath, 0644, secret.Data[corev1.TLSCertKey]))
+				*newFiles = extensionswebhook.EnsureFileWithPath(*newFiles, inlineFile(keyPath, 0600, secret.Data[corev1.TLSPrivateKeyKey]))
+				hostConfig.ClientCertificate = []string{certPath, keyPath}
+			}
+
+			if host.AuthSecretName != nil {
+				secret, err := e.getSecret(ctx, cluster.ObjectMeta.Name, *host.AuthSecretName)
+				if err != nil {
+					return err
+				}
+
+				hostConfig.Header = helper.AuthHeaders(secret)
+			}
+
+			hostsConfig.Hosts = append(hostsConfig.Hosts, hostConfig)
+		}
+
+		if !requiresHostsFile(mirror) {
+			continue
+		}
+
+		hostsTOML, err := containerd.RenderHosts(hostsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to render hosts.toml for upstream %s: %w", mirror.Upstream, err)
+		}
+		// The hosts.toml file contains the credentials of the mirror hosts if auth headers are configured.
+		var permissions uint32 = 0644
+		if slices.ContainsFunc(mirror.Hosts, func(host api.MirrorHostStatus) bool { return host.AuthSecretName != nil }) {
+			permissions = 0600
+		}
+		*newFiles = extensionswebhook.EnsureFileWithPath(*newFiles, inlineFile(containerd.HostsFilePath(mirror.Upstream), permissions, hostsTOML))
+	}
+
+	return nil
+}
+
+func (e *ensurer) getProviderStatus(ctx context.Context, cluster *extensionscontroller.Cluster) (*api.MirrorStatus, error) {
+	extension := &extensionsv1alpha1.Extension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "registry-mirror",
+			Namespace: cluster.ObjectMeta.Name,
+		},
+	}
+	if err := e.client.Get(ctx, client.ObjectKeyFromObject(extension), extension); err != nil {
+		return nil, fmt.Errorf("failed to get extension '%s': %w", client.ObjectKeyFromObject(extension), err)
+	}
+
+	// The provider status is missing until the Extension is reconciled by a version of the extension publishing it.
+	// The effective configuration is computed from the provider config in this case.
+	if extension.Status.ProviderStatus == nil {
+		return e.computeProviderStatus(extension, cluster)
+	}
+
+	mirrorStatus := &api.MirrorStatus{}
+	if err := runtime.DecodeInto(e.decoder, extension.Status.ProviderStatus.Raw, mirrorStatus); err != nil {
+		return nil, fmt.Errorf("failed to decode providerStatus of extension '%s': %w", client.ObjectKeyFromObject(extension), err)
+	}
+
+	return mirrorStatus, nil
+}
+
+func (e *ensurer) computeProviderStatus(extension *extensionsv1alpha1.Extension, cluster *extensionscontroller.Cluster) (*api.MirrorStatus, error) {
+	if extension.Spec.ProviderConfig == nil {
+		return nil, fmt.Errorf("extension '%s' does not have a .status.providerStatus or .spec.providerConfig specified", client.ObjectKeyFromObject(extension))
+	}
+
+	mirrorConfig := &api.MirrorConfig{}
+	if err := runtime.DecodeInto(e.decoder, extension.Spec.ProviderConfig.Raw, mirrorConfig); err != nil {
+		return nil, fmt.Errorf("failed to decode providerConfig of extension '%s': %w", client.ObjectKeyFromObject(extension), err)
+	}
+
+	return helper.ComputeMirrorStatus(mirrorConfig, func(referenceName string) (string, error) {
+		ref := v1beta1helper.GetResourceByName(cluster.Shoot.Spec.Resources, referenceName)
+		if ref == nil || ref.ResourceRef.Kind != "Secret" {
+			return "", fmt.Errorf("failed to find referenced resource with name %s and kind Secret", referenceName)
+		}
+		return v1beta1constants.ReferencedResourcesPrefix + ref.ResourceRef.Name, nil
+	})
+}
+
+func (e *ensurer) getSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if err := e.client.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret '%s': %w", client.ObjectKeyFromObject(secret), err)
+	}
+
+	return secret, nil
+}
+
+// requiresHostsFile returns true if the mirror uses host options which are not supported by the CRI config.
+// For such mirrors the hosts.toml file is rendered by the extension.
+func requiresHostsFile(mirror api.MirrorConfigurationStatus) bool {
+	return slices.ContainsFunc(mirror.Hosts, func(host api.MirrorHostStatus) bool {
+		return host.ClientCertificateSecretName != nil || host.AuthSecretName != nil || host.SkipVerify || host.OverridePath
+	})
+}
+
+func registryCapabilities(capabilities []api.MirrorHostCapability) []extensionsv1alpha1.RegistryCapability {
+	var registryCapabilities []extensionsv1alpha1.RegistryCapability
+	for _, c := range capabilities {
+		switch c {
+		case api.MirrorHostCapabilityPull:
+			registryCapabilities = append(registryCapabilities, extensionsv1alpha1.PullCapability)
+		case api.MirrorHostCapabilityResolve:
+			registryCapabilities = append(registryCapabilities, extensionsv1alpha1.ResolveCapability)
+		}
+	}
+	return registryCapabilities
+}
+
+func hostFilesDirectory(upstream, host string) string {
+	return path.Join(filesDirectory, upstream, containerd.HostDirectoryName(host))
+}
+
+func caBundlePath(upstream, host string) string {
+	return path.Join(hostFilesDirectory(upstream, host), "ca.crt")
+}
+
+func inlineFile(filePath string, permissions uint32, data []byte) extensionsv1alpha1.File {
+	return extensionsv1alpha1.File{
+		Path:        filePath,
+		Permissions: ptr.To(permissions),
+		Content: extensionsv1alpha1.FileContent{
+			Inline: &extensionsv1alpha1.FileContentInline{
+				Encoding: "b64",
+				Data:     base64.StdEncoding.EncodeToString(data),
+			},
+		},
+	}
+}
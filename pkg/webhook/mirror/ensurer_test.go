@@ -6,6 +6,7 @@ package mirror_test
 
 import (
 	"context"
+	"encoding/base64"
 	"testing"
 
 	extensionscontextwebhook "github.com/gardener/gardener/extensions/pkg/webhook/context"
@@ -45,6 +46,7 @@ var _ = Describe("Ensurer", func() {
 	BeforeEach(func() {
 		scheme := runtime.NewScheme()
 		Expect(extensionsv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
 		mirrorinstall.Install(scheme)
 
 		decoder = serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
@@ -69,18 +71,19 @@ var _ = Describe("Ensurer", func() {
 					Name:      "registry-mirror",
 					Namespace: cluster.ObjectMeta.Name,
 				},
-				Spec: extensionsv1alpha1.ExtensionSpec{
-					DefaultSpec: extensionsv1alpha1.DefaultSpec{
-						ProviderConfig: &runtime.RawExtension{
-							Object: &v1alpha1.MirrorConfig{
+				Status: extensionsv1alpha1.ExtensionStatus{
+					DefaultStatus: extensionsv1alpha1.DefaultStatus{
+						ProviderStatus: &runtime.RawExtension{
+							Object: &v1alpha1.MirrorStatus{
 								TypeMeta: metav1.TypeMeta{
 									APIVersion: v1alpha1.SchemeGroupVersion.String(),
-									Kind:       "MirrorConfig",
+									Kind:       "MirrorStatus",
 								},
-								Mirrors: []v1alpha1.MirrorConfiguration{
+								Mirrors: []v1alpha1.MirrorConfigurationStatus{
 									{
 										Upstream: "docker.io",
-										Hosts: []v1alpha1.MirrorHost{
+										Server:   ptr.To("https://registry-1.docker.io"),
+										Hosts: []v1alpha1.MirrorHostStatus{
 											{
 												Host:         "https://mirror.gcr.io",
 												Capabilities: []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull, v1alpha1.MirrorHostCapabilityResolve},
@@ -150,9 +153,9 @@ var _ = Describe("Ensurer", func() {
 			Expect(err).To(MatchError(ContainSubstring("failed to get extension 'shoot--foo--bar/registry-mirror'")))
 		})
 
-		It("should return err when extension .spec.providerConfig is nil", func() {
+		It("should return err when extension .status.providerStatus and .spec.providerConfig are nil", func() {
 			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
-			extension.Spec.DefaultSpec.ProviderConfig = nil
+			extension.Status.DefaultStatus.ProviderStatus = nil
 
 			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
 
@@ -160,12 +163,55 @@ var _ = Describe("Ensurer", func() {
 
 			err := ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)
 			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError(ContainSubstring("extension 'shoot--foo--bar/registry-mirror' does not have a .spec.providerConfig specified")))
+			Expect(err).To(MatchError(ContainSubstring("extension 'shoot--foo--bar/registry-mirror' does not have a .status.providerStatus or .spec.providerConfig specified")))
 		})
 
-		It("should return err when extension .spec.providerConfig cannot be decoded", func() {
+		It("should add the registry config of extension .spec.providerConfig when .status.providerStatus is nil", func() {
 			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
-			extension.Spec.DefaultSpec.ProviderConfig = &runtime.RawExtension{Object: &corev1.Pod{}}
+			extension.Status.DefaultStatus.ProviderStatus = nil
+			extension.Spec.ProviderConfig = &runtime.RawExtension{
+				Object: &v1alpha1.MirrorConfig{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: v1alpha1.SchemeGroupVersion.String(),
+						Kind:       "MirrorConfig",
+					},
+					Mirrors: []v1alpha1.MirrorConfiguration{
+						{
+							Upstream: "docker.io",
+							Hosts: []v1alpha1.MirrorHost{
+								{
+									Host:         "https://mirror.gcr.io",
+									Capabilities: []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull, v1alpha1.MirrorHostCapabilityResolve},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, extensionsv1alpha1.RegistryConfig{
+				Upstream: "docker.io",
+				Server:   ptr.To("https://registry-1.docker.io"),
+				Hosts: []extensionsv1alpha1.RegistryHost{
+					{
+						URL:          "https://mirror.gcr.io",
+						Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability},
+					},
+				},
+			})
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should return err when extension .status.providerStatus cannot be decoded", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.DefaultStatus.ProviderStatus = &runtime.RawExtension{Object: &corev1.Pod{}}
 
 			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
 
@@ -173,7 +219,7 @@ var _ = Describe("Ensurer", func() {
 
 			err := ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)
 			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError(ContainSubstring("failed to decode providerConfig of extension 'shoot--foo--bar/registry-mirror'")))
+			Expect(err).To(MatchError(ContainSubstring("failed to decode providerStatus of extension 'shoot--foo--bar/registry-mirror'")))
 		})
 
 		It("should add additional registry config to a nil containerd registry configs", func() {
@@ -221,38 +267,416 @@ var _ = Describe("Ensurer", func() {
 			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
 		})
 
-		It("should update existing registry config from containerd registry configs", func() {
+		It("should append the mirror hosts to the hosts of an existing registry config", func() {
 			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
 
 			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
 
 			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
 
+			cacheHost := extensionsv1alpha1.RegistryHost{
+				URL:          "http://10.4.246.205:5000",
+				Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability},
+			}
+
 			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
 			expectedRegistries = append(expectedRegistries, extensionsv1alpha1.RegistryConfig{
 				Upstream: "docker.io",
-				Server:   ptr.To("https://registry-1.docker.io"),
+				Server:   ptr.To("https://index.docker.io"),
 				Hosts: []extensionsv1alpha1.RegistryHost{
+					cacheHost,
 					{
 						URL:          "https://mirror.gcr.io",
 						Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability},
 					},
 				},
+				ReadinessProbe: ptr.To(true),
 			})
 
 			criConfig.Containerd.Registries = append(criConfig.Containerd.Registries, extensionsv1alpha1.RegistryConfig{
 				Upstream: "docker.io",
-				Server:   ptr.To("foo"),
+				Server:   ptr.To("https://index.docker.io"),
 				Hosts: []extensionsv1alpha1.RegistryHost{
+					cacheHost,
 					{
-						URL:          "bar",
+						URL:          "https://mirror.gcr.io",
 						Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PushCapability},
 					},
 				},
+				ReadinessProbe: ptr.To(true),
+			})
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should configure the CA bundle of a mirror host", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.ProviderStatus.Object.(*v1alpha1.MirrorStatus).Mirrors[0].Hosts[0].CABundleSecretName = ptr.To("ref-ca")
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, extensionsv1alpha1.RegistryConfig{
+				Upstream: "docker.io",
+				Server:   ptr.To("https://registry-1.docker.io"),
+				Hosts: []extensionsv1alpha1.RegistryHost{
+					{
+						URL:          "https://mirror.gcr.io",
+						Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability},
+						CACerts:      []string{"/etc/containerd/registry-mirror/docker.io/mirror.gcr.io/ca.crt"},
+					},
+				},
+			})
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should add the default registry config for a mirror with wildcard upstream", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			mirrorStatus := extension.Status.ProviderStatus.Object.(*v1alpha1.MirrorStatus)
+			mirrorStatus.Mirrors = append(mirrorStatus.Mirrors, v1alpha1.MirrorConfigurationStatus{
+				Upstream: "_default",
+				Hosts: []v1alpha1.MirrorHostStatus{{
+					Host:               "https://mirror.example.com",
+					Capabilities:       []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull},
+					CABundleSecretName: ptr.To("ref-ca"),
+				}},
+			})
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries,
+				extensionsv1alpha1.RegistryConfig{
+					Upstream: "docker.io",
+					Server:   ptr.To("https://registry-1.docker.io"),
+					Hosts: []extensionsv1alpha1.RegistryHost{
+						{
+							URL:          "https://mirror.gcr.io",
+							Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability},
+						},
+					},
+				},
+				extensionsv1alpha1.RegistryConfig{
+					Upstream: "_default",
+					Hosts: []extensionsv1alpha1.RegistryHost{
+						{
+							URL:          "https://mirror.example.com",
+							Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability},
+							CACerts:      []string{"/etc/containerd/registry-mirror/_default/mirror.example.com/ca.crt"},
+						},
+					},
+				},
+			)
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should add registry config for a mirror host with path prefix", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.ProviderStatus.Object.(*v1alpha1.MirrorStatus).Mirrors[0].Hosts[0].Host = "https://nexus.example.com/repository/docker-proxy"
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			expectedRegistries = append(expectedRegistries, extensionsv1alpha1.RegistryConfig{
+				Upstream: "docker.io",
+				Server:   ptr.To("https://registry-1.docker.io"),
+				Hosts: []extensionsv1alpha1.RegistryHost{
+					{
+						URL:          "https://nexus.example.com/repository/docker-proxy",
+						Capabilities: []extensionsv1alpha1.RegistryCapability{extensionsv1alpha1.PullCapability, extensionsv1alpha1.ResolveCapability},
+					},
+				},
+			})
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
+		})
+
+		It("should remove the registry config of a mirror which requires a hosts.toml file", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			extension.Status.ProviderStatus.Object.(*v1alpha1.MirrorStatus).Mirrors[0].Hosts[0].SkipVerify = true
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			expectedRegistries := criConfig.Containerd.DeepCopy().Registries
+			criConfig.Containerd.Registries = append(criConfig.Containerd.Registries, extensionsv1alpha1.RegistryConfig{
+				Upstream: "docker.io",
+				Server:   ptr.To("https://registry-1.docker.io"),
 			})
 
 			Expect(ensurer.EnsureCRIConfig(ctx, gctx, &criConfig, nil)).To(Succeed())
 			Expect(criConfig.Containerd.Registries).To(ConsistOf(expectedRegistries))
 		})
 	})
+
+	Describe("#EnsureAdditionalFiles", func() {
+		var (
+			cluster      *extensions.Cluster
+			extension    *extensionsv1alpha1.Extension
+			mirrorStatus *v1alpha1.MirrorStatus
+			files        []extensionsv1alpha1.File
+		)
+
+		BeforeEach(func() {
+			cluster = &extensions.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "shoot--foo--bar"},
+				Shoot:      &gardencorev1beta1.Shoot{},
+			}
+
+			mirrorStatus = &v1alpha1.MirrorStatus{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: v1alpha1.SchemeGroupVersion.String(),
+					Kind:       "MirrorStatus",
+				},
+				Mirrors: []v1alpha1.MirrorConfigurationStatus{
+					{
+						Upstream: "docker.io",
+						Server:   ptr.To("https://registry-1.docker.io"),
+						Hosts: []v1alpha1.MirrorHostStatus{
+							{
+								Host:               "https://mirror.example.com:8443",
+								Capabilities:       []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull},
+								CABundleSecretName: ptr.To("ref-ca"),
+							},
+						},
+					},
+				},
+			}
+
+			extension = &extensionsv1alpha1.Extension{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "registry-mirror",
+					Namespace: cluster.ObjectMeta.Name,
+				},
+				Status: extensionsv1alpha1.ExtensionStatus{
+					DefaultStatus: extensionsv1alpha1.DefaultStatus{
+						ProviderStatus: &runtime.RawExtension{Object: mirrorStatus},
+					},
+				},
+			}
+
+			Expect(fakeClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ref-ca", Namespace: cluster.ObjectMeta.Name},
+				Data:       map[string][]byte{"ca.crt": []byte("ca")},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ref-client", Namespace: cluster.ObjectMeta.Name},
+				Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ref-auth", Namespace: cluster.ObjectMeta.Name},
+				Data:       map[string][]byte{"token": []byte("s3cr3t"), "header.X-Foo": []byte("bar")},
+			})).To(Succeed())
+
+			files = nil
+		})
+
+		inlineFile := func(path string, permissions uint32, data string) extensionsv1alpha1.File {
+			return extensionsv1alpha1.File{
+				Path:        path,
+				Permissions: ptr.To(permissions),
+				Content: extensionsv1alpha1.FileContent{
+					Inline: &extensionsv1alpha1.FileContentInline{
+						Encoding: "b64",
+						Data:     base64.StdEncoding.EncodeToString([]byte(data)),
+					},
+				},
+			}
+		}
+
+		It("should do nothing if the shoot has a deletion timestamp set", func() {
+			deletionTimestamp := metav1.Now()
+			cluster.Shoot.ObjectMeta.DeletionTimestamp = &deletionTimestamp
+
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(files).To(BeEmpty())
+		})
+
+		It("should return err when the secret does not exist", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			mirrorStatus.Mirrors[0].Hosts[0].CABundleSecretName = ptr.To("ref-missing")
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			err := ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)
+			Expect(err).To(MatchError(ContainSubstring("failed to get secret 'shoot--foo--bar/ref-missing'")))
+		})
+
+		It("should add the CA bundle file", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(files).To(ConsistOf(
+				inlineFile("/etc/containerd/registry-mirror/docker.io/mirror.example.com_8443/ca.crt", 0644, "ca"),
+			))
+		})
+
+		It("should add the client certificate files and the hosts.toml file", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			mirrorStatus.Mirrors[0].Hosts[0].ClientCertificateSecretName = ptr.To("ref-client")
+			mirrorStatus.Mirrors[0].Hosts = append(mirrorStatus.Mirrors[0].Hosts, v1alpha1.MirrorHostStatus{
+				Host:         "https://mirror.gcr.io",
+				Capabilities: []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull},
+				SkipVerify:   true,
+			})
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(files).To(ConsistOf(
+				inlineFile("/etc/containerd/registry-mirror/docker.io/mirror.example.com_8443/ca.crt", 0644, "ca"),
+				inlineFile("/etc/containerd/registry-mirror/docker.io/mirror.example.com_8443/client.crt", 0644, "cert"),
+				inlineFile("/etc/containerd/registry-mirror/docker.io/mirror.example.com_8443/client.key", 0600, "key"),
+				inlineFile("/etc/containerd/certs.d/docker.io/hosts.toml", 0644, `# managed by gardener-extension-registry-cache
+server = "https://registry-1.docker.io"
+
+[host."https://mirror.example.com:8443"]
+  capabilities = ["pull"]
+  ca = ["/etc/containerd/registry-mirror/docker.io/mirror.example.com_8443/ca.crt"]
+  client = [["/etc/containerd/registry-mirror/docker.io/mirror.example.com_8443/client.crt","/etc/containerd/registry-mirror/docker.io/mirror.example.com_8443/client.key"]]
+
+[host."https://mirror.gcr.io"]
+  capabilities = ["pull"]
+  skip_verify = true
+
+`),
+			))
+		})
+
+		It("should never configure an upstream via both the registry config and the hosts.toml file when the mirror changes its mode", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+			hostsFilePath := "/etc/containerd/certs.d/docker.io/hosts.toml"
+
+			By("Ensure the mirror without host options requiring a hosts.toml file")
+			criConfig := &extensionsv1alpha1.CRIConfig{}
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, criConfig, nil)).To(Succeed())
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(HaveField("Upstream", "docker.io")))
+			Expect(files).NotTo(ContainElement(HaveField("Path", hostsFilePath)))
+
+			By("Ensure the mirror with a client certificate")
+			mirrorStatus.Mirrors[0].Hosts[0].ClientCertificateSecretName = ptr.To("ref-client")
+			extension.Status.ProviderStatus = &runtime.RawExtension{Object: mirrorStatus}
+			Expect(fakeClient.Update(ctx, extension)).To(Succeed())
+
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, criConfig, criConfig.DeepCopy())).To(Succeed())
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(BeEmpty())
+			Expect(files).To(ContainElement(HaveField("Path", hostsFilePath)))
+
+			By("Ensure the mirror without the client certificate again")
+			mirrorStatus.Mirrors[0].Hosts[0].ClientCertificateSecretName = nil
+			extension.Status.ProviderStatus = &runtime.RawExtension{Object: mirrorStatus}
+			Expect(fakeClient.Update(ctx, extension)).To(Succeed())
+
+			files = nil
+			Expect(ensurer.EnsureCRIConfig(ctx, gctx, criConfig, criConfig.DeepCopy())).To(Succeed())
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(criConfig.Containerd.Registries).To(ConsistOf(HaveField("Upstream", "docker.io")))
+			Expect(files).NotTo(ContainElement(HaveField("Path", hostsFilePath)))
+		})
+
+		It("should add the hosts.toml file with the auth headers", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			mirrorStatus.Mirrors[0].Hosts[0].CABundleSecretName = nil
+			mirrorStatus.Mirrors[0].Hosts[0].AuthSecretName = ptr.To("ref-auth")
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(files).To(ConsistOf(
+				inlineFile("/etc/containerd/certs.d/docker.io/hosts.toml", 0600, `# managed by gardener-extension-registry-cache
+server = "https://registry-1.docker.io"
+
+[host."https://mirror.example.com:8443"]
+  capabilities = ["pull"]
+
+  [host."https://mirror.example.com:8443".header]
+    "Authorization" = ["Bearer s3cr3t"]
+    "X-Foo" = ["bar"]
+
+`),
+			))
+		})
+
+		It("should add the hosts.toml file with override_path for a mirror host with API root path", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			mirrorStatus.Mirrors[0].Hosts[0] = v1alpha1.MirrorHostStatus{
+				Host:         "https://artifactory.example.com/v2/docker-remote",
+				Capabilities: []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull},
+				OverridePath: true,
+			}
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(files).To(ConsistOf(
+				inlineFile("/etc/containerd/certs.d/docker.io/hosts.toml", 0644, `# managed by gardener-extension-registry-cache
+server = "https://registry-1.docker.io"
+
+[host."https://artifactory.example.com/v2/docker-remote"]
+  capabilities = ["pull"]
+  override_path = true
+
+`),
+			))
+		})
+
+		It("should add the default hosts.toml file for a mirror with wildcard upstream", func() {
+			gctx := extensionscontextwebhook.NewInternalGardenContext(cluster)
+			mirrorStatus.Mirrors[0] = v1alpha1.MirrorConfigurationStatus{
+				Upstream: "_default",
+				Hosts: []v1alpha1.MirrorHostStatus{{
+					Host:         "https://mirror.example.com",
+					Capabilities: []v1alpha1.MirrorHostCapability{v1alpha1.MirrorHostCapabilityPull},
+					SkipVerify:   true,
+				}},
+			}
+
+			Expect(fakeClient.Create(ctx, extension)).To(Succeed())
+
+			ensurer := mirror.NewEnsurer(fakeClient, decoder, logger)
+
+			Expect(ensurer.EnsureAdditionalFiles(ctx, gctx, &files, nil)).To(Succeed())
+			Expect(files).To(ConsistOf(
+				inlineFile("/etc/containerd/certs.d/_default/hosts.toml", 0644, `# managed by gardener-extension-registry-cache
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull"]
+  skip_verify = true
+
+`),
+			))
+		})
+	})
 })